@@ -0,0 +1,271 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/codec/encrypted"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/backup"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/core"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/generic"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/migration"
+	"github.com/spf13/cobra"
+	"os"
+	"time"
+)
+
+// NewDBCommand returns instance of cobra command that allows to maintain Aptomi DB while server isn't running
+func NewDBCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "db",
+		Short: "Maintain Aptomi DB",
+	}
+
+	cmd.AddCommand(
+		newDBCompactCommand(),
+		newDBBackupCommand(),
+		newDBRestoreCommand(),
+		newDBKeygenCommand(),
+		newDBReencryptCommand(),
+		newDBMigrateCommand(),
+	)
+
+	return cmd
+}
+
+func newDBCompactCommand() *cobra.Command {
+	var policyGenerations, revisions int
+	var maxAge time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Remove old generations of policy and revisions from DB according to the retention config",
+		Run: func(cmd *cobra.Command, args []string) {
+			retention := cfg.DB.Retention
+			if cmd.Flags().Changed("policy-generations") {
+				retention.PolicyGenerations = policyGenerations
+			}
+			if cmd.Flags().Changed("revisions") {
+				retention.Revisions = revisions
+			}
+			if cmd.Flags().Changed("max-age") {
+				retention.MaxAge = maxAge
+			}
+			if !retention.IsEnabled() {
+				panic(fmt.Sprintf("Retention isn't configured, nothing to compact"))
+			}
+
+			b := openStore()
+			defer b.Close() // nolint: errcheck
+
+			result, err := core.NewStore(b).Compact(retention, time.Now())
+			if err != nil {
+				panic(fmt.Sprintf("Error while compacting DB: %s", err))
+			}
+
+			fmt.Printf("Removed %d revisions, %d policy generations and %d object generations\n", result.Revisions, result.PolicyGenerations, result.ObjectGenerations)
+		},
+	}
+
+	cmd.Flags().IntVar(&policyGenerations, "policy-generations", 0, "Number of last policy generations to keep (overrides db.retention config)")
+	cmd.Flags().IntVar(&revisions, "revisions", 0, "Number of last revisions to keep (overrides db.retention config)")
+	cmd.Flags().DurationVar(&maxAge, "max-age", 0, "Keep all policy generations and revisions younger than this (overrides db.retention config)")
+
+	return cmd
+}
+
+func newDBBackupCommand() *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Write backup of all objects from DB into archive (encrypted if DB encryption is configured), use 'aptomictl backup' while server is running",
+		Run: func(cmd *cobra.Command, args []string) {
+			b := openStore()
+			defer b.Close() // nolint: errcheck
+
+			out, err := os.OpenFile(file, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+			if err != nil {
+				panic(fmt.Sprintf("Error while creating file '%s': %s", file, err))
+			}
+			defer out.Close() // nolint: errcheck
+
+			manifest, err := backup.Write(out, b)
+			if err != nil {
+				panic(fmt.Sprintf("Error while making backup: %s", err))
+			}
+
+			if manifest.Encrypted {
+				fmt.Printf("Encrypted backup with %d objects saved to %s, it could be restored only with the current encryption key\n", manifest.Objects, file)
+			} else {
+				fmt.Printf("Backup with %d objects saved to %s\n", manifest.Objects, file)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "aptomi-backup.tar.gz", "File to save backup to")
+
+	return cmd
+}
+
+func newDBRestoreCommand() *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "restore",
+		Short: "Verify backup archive and restore all objects from it into empty DB of any type",
+		Run: func(cmd *cobra.Command, args []string) {
+			in, err := os.Open(file)
+			if err != nil {
+				panic(fmt.Sprintf("Error while opening file '%s': %s", file, err))
+			}
+			defer in.Close() // nolint: errcheck
+
+			b := openStore()
+			defer b.Close() // nolint: errcheck
+
+			manifest, err := backup.Restore(in, b)
+			if err != nil {
+				panic(fmt.Sprintf("Error while restoring backup: %s", err))
+			}
+
+			fmt.Printf("Restored %d objects from backup created at %s\n", manifest.Objects, manifest.CreatedAt.Format(time.RFC3339))
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "aptomi-backup.tar.gz", "File to restore backup from")
+
+	return cmd
+}
+
+func newDBKeygenCommand() *cobra.Command {
+	var id string
+
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "Generate new DB encryption key and make it current, run 'aptomi db reencrypt' after it to rotate keys",
+		Run: func(cmd *cobra.Command, args []string) {
+			keyFile := cfg.DB.Encryption.KeyFile
+			if len(keyFile) == 0 {
+				panic(fmt.Sprintf("Encryption key file isn't configured (db.encryption.keyfile)"))
+			}
+			if len(id) == 0 {
+				id = fmt.Sprintf("key-%d", time.Now().Unix())
+			}
+
+			err := encrypted.AddKey(keyFile, id)
+			if err != nil {
+				panic(fmt.Sprintf("Error while generating encryption key: %s", err))
+			}
+
+			fmt.Printf("Key '%s' added to %s and will be used to encrypt objects\n", id, keyFile)
+		},
+	}
+
+	cmd.Flags().StringVar(&id, "id", "", "ID of the new key (generated from the current time if not specified)")
+
+	return cmd
+}
+
+func newDBReencryptCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reencrypt",
+		Short: "Re-encrypt all objects in DB with the current encryption key, unencrypted objects get encrypted as well",
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(cfg.DB.Encryption.KeyFile) == 0 {
+				panic(fmt.Sprintf("Encryption key file isn't configured (db.encryption.keyfile)"))
+			}
+
+			b := openStore()
+			defer b.Close() // nolint: errcheck
+
+			// objects are decrypted with any key from the key file and written back encrypted with the current one.
+			// It's done in a single transaction, so DB never ends up partially re-encrypted
+			objs, err := b.List("")
+			if err != nil {
+				panic(fmt.Sprintf("Error while reading objects: %s", err))
+			}
+			tx, err := b.Begin()
+			if err != nil {
+				panic(fmt.Sprintf("Error while starting transaction: %s", err))
+			}
+			defer tx.Rollback() // nolint: errcheck
+
+			for _, obj := range objs {
+				err = tx.Put(obj)
+				if err != nil {
+					panic(fmt.Sprintf("Error while re-encrypting %s: %s", runtime.KeyForStorable(obj), err))
+				}
+			}
+			err = tx.Commit()
+			if err != nil {
+				panic(fmt.Sprintf("Error while saving re-encrypted objects: %s", err))
+			}
+
+			fmt.Printf("Re-encrypted %d objects, keys other than the current one could be removed from the key file now\n", len(objs))
+		},
+	}
+
+	return cmd
+}
+
+func newDBMigrateCommand() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:   "migrate",
+		Short: "Migrate DB to the current schema version, it's done automatically on server start as well",
+		Run: func(cmd *cobra.Command, args []string) {
+			b := openStore()
+			defer b.Close() // nolint: errcheck
+
+			var result *migration.Result
+			var err error
+			if dryRun {
+				result, err = migration.Migrate(b, migration.Migrations, true)
+			} else {
+				var backupFile string
+				result, backupFile, err = migration.MigrateWithBackup(b, migration.Migrations, migration.BackupDir(cfg.DB))
+				if len(backupFile) > 0 {
+					fmt.Printf("Backup saved to %s\n", backupFile)
+				}
+			}
+			if err != nil {
+				panic(fmt.Sprintf("Error while migrating DB: %s", err))
+			}
+
+			if !result.IsNeeded() {
+				fmt.Printf("DB schema version is %d, no migrations needed\n", result.From)
+				return
+			}
+			action := "Migrated"
+			if dryRun {
+				action = "Would migrate"
+			}
+			if len(result.Applied) == 0 {
+				fmt.Printf("%s DB without schema marker to schema version %d, no objects need to be changed\n", action, result.To)
+				return
+			}
+			fmt.Printf("%s DB from schema version %d to %d\n", action, result.From, result.To)
+			for _, applied := range result.Applied {
+				fmt.Printf("  [%d] %s: %d objects changed\n", applied.Version, applied.Description, result.Changed[applied.Version])
+			}
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Show migrations and number of objects they would change without applying them")
+
+	return cmd
+}
+
+func openStore() store.Generic {
+	b, err := generic.NewStore(runtime.NewRegistry().Append(store.Objects...), cfg.DB)
+	if err != nil {
+		panic(fmt.Sprintf("Can't create object store: %s", err))
+	}
+	err = b.Open(cfg.DB)
+	if err != nil {
+		panic(fmt.Sprintf("Can't open object store: %s", err))
+	}
+	return b
+}
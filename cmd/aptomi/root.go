@@ -38,11 +38,16 @@ func init() {
 
 	// add server-specific flags
 	common.AddStringFlag(aptomiCmd, "db.connection", "db", "", "/var/lib/aptomi/db.bolt", envPrefix+"_DB_CONN", "DB connection string")
+	common.AddStringFlag(aptomiCmd, "db.type", "db-type", "", "", envPrefix+"_DB_TYPE", "DB type (bolt, sqlite3 or memory), bolt is used by default and memory is selected by memory:// connection")
+	common.AddStringFlag(aptomiCmd, "db.encryption.keyfile", "db-key-file", "", "", envPrefix+"_DB_KEY_FILE", "File with keys for encryption of objects stored in DB, objects aren't encrypted if it isn't set")
+	common.AddStringFlag(aptomiCmd, "db.backupdir", "db-backup-dir", "", "", envPrefix+"_DB_BACKUP_DIR", "Directory for DB backups made before schema migrations, directory of the DB file is used by default")
 	common.AddStringFlag(aptomiCmd, "ui.schema", "ui-schema", "", "http", envPrefix+"_SCHEMA", "Server UI schema")
 	common.AddBoolFlag(aptomiCmd, "ui.enable", "ui", "", true, envPrefix+"_UI", "Enable server to serve UI")
 	common.AddDurationFlag(aptomiCmd, "enforcer.interval", "enforcer-interval", "", 5*time.Second, envPrefix+"_ENFORCER_INTERVAL", "Enforcer interval")
 
 	aptomiCmd.AddCommand(NewVersionCommand())
+	aptomiCmd.AddCommand(NewSecretsCommand())
+	aptomiCmd.AddCommand(NewDBCommand())
 }
 
 func preRun(command *cobra.Command, args []string) {
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/external/secrets"
+	"github.com/spf13/cobra"
+	"io/ioutil"
+)
+
+// NewSecretsCommand returns instance of cobra command that allows to generate keys and to encrypt/decrypt secret files
+func NewSecretsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "secrets",
+		Short: "Manage encrypted secret files",
+
+		// secret files can be managed without server config
+		PersistentPreRun: func(cmd *cobra.Command, args []string) {},
+	}
+
+	cmd.AddCommand(
+		newSecretsGenKeyCommand(),
+		newSecretsCryptCommand("encrypt", "Encrypt plaintext secrets file", secrets.EncryptSecrets),
+		newSecretsCryptCommand("decrypt", "Decrypt encrypted secrets file", secrets.DecryptSecrets),
+	)
+
+	return cmd
+}
+
+func newSecretsGenKeyCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "genkey",
+		Short: "Generate a new key for encrypting secrets",
+		Run: func(cmd *cobra.Command, args []string) {
+			key, err := secrets.GenerateSecretsKey()
+			if err != nil {
+				panic(fmt.Sprintf("Error while generating key: %s", err))
+			}
+			fmt.Println(key)
+		},
+	}
+}
+
+func newSecretsCryptCommand(use string, short string, crypt func([]byte, string) ([]byte, error)) *cobra.Command {
+	var key, in, out string
+
+	cmd := &cobra.Command{
+		Use:   use,
+		Short: short,
+		Run: func(cmd *cobra.Command, args []string) {
+			data, err := ioutil.ReadFile(in)
+			if err != nil {
+				panic(fmt.Sprintf("Error while reading file '%s': %s", in, err))
+			}
+
+			result, err := crypt(data, key)
+			if err != nil {
+				panic(fmt.Sprintf("Error while processing file '%s': %s", in, err))
+			}
+
+			err = ioutil.WriteFile(out, result, 0600)
+			if err != nil {
+				panic(fmt.Sprintf("Error while writing file '%s': %s", out, err))
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&key, "key", "k", "", "Base64-encoded key (see 'aptomi secrets genkey')")
+	cmd.Flags().StringVarP(&in, "in", "i", "", "Input file")
+	cmd.Flags().StringVarP(&out, "out", "", "", "Output file")
+
+	return cmd
+}
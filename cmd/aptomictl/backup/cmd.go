@@ -0,0 +1,52 @@
+package backup
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/backup"
+	"github.com/spf13/cobra"
+	"io/ioutil"
+)
+
+// NewCommand returns cobra command for backup subcommand
+func NewCommand(cfg *config.Client) *cobra.Command {
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "backup",
+		Short: "Download backup of all data stored by Aptomi server",
+		Long:  "Download consistent backup of all data stored by running Aptomi server, it could be restored using 'aptomi db restore'",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			data, err := rest.New(cfg, http.NewClient(cfg)).Backup().Download()
+			if err != nil {
+				panic(fmt.Sprintf("Error while downloading backup: %s", err))
+			}
+
+			// make sure that downloaded backup isn't corrupted before saving it, objects aren't decoded as backup is
+			// encrypted with the server key if DB encryption is configured
+			manifest, err := backup.Verify(bytes.NewReader(data))
+			if err != nil {
+				panic(fmt.Sprintf("Error while verifying backup: %s", err))
+			}
+
+			err = ioutil.WriteFile(file, data, 0600)
+			if err != nil {
+				panic(fmt.Sprintf("Error while writing backup to '%s': %s", file, err))
+			}
+
+			if manifest.Encrypted {
+				fmt.Printf("Encrypted backup with %d objects saved to %s, it could be restored only with the server encryption key file\n", manifest.Objects, file)
+			} else {
+				fmt.Printf("Backup with %d objects saved to %s\n", manifest.Objects, file)
+			}
+		},
+	}
+
+	cmd.Flags().StringVarP(&file, "file", "f", "aptomi-backup.tar.gz", "File to save backup to")
+
+	return cmd
+}
@@ -2,6 +2,7 @@ package endpoints
 
 import (
 	"fmt"
+	"github.com/Aptomi/aptomi/pkg/api"
 	"github.com/Aptomi/aptomi/pkg/client/rest"
 	"github.com/Aptomi/aptomi/pkg/client/rest/http"
 	"github.com/Aptomi/aptomi/pkg/config"
@@ -9,13 +10,15 @@ import (
 )
 
 func newShowCommand(cfg *config.Client) *cobra.Command {
-	return &cobra.Command{
+	filter := &api.EndpointsFilter{}
+
+	cmd := &cobra.Command{
 		Use:   "show",
 		Short: "endpoints show",
 		Long:  "endpoints show long",
 
 		Run: func(cmd *cobra.Command, args []string) {
-			endpoints, err := rest.New(cfg, http.NewClient(cfg)).Endpoints().Show()
+			endpoints, err := rest.New(cfg, http.NewClient(cfg)).Endpoints().Show(filter)
 			if err != nil {
 				panic(fmt.Sprintf("Error while requesting endpoints: %s", err))
 			}
@@ -24,4 +27,11 @@ func newShowCommand(cfg *config.Client) *cobra.Command {
 			fmt.Println(endpoints)
 		},
 	}
+
+	cmd.Flags().StringVarP(&filter.Namespace, "namespace", "n", "", "Show endpoints only for dependencies in a given namespace")
+	cmd.Flags().StringVar(&filter.Dependency, "dependency", "", "Show endpoints only for a given dependency")
+	cmd.Flags().StringVarP(&filter.Contract, "contract", "c", "", "Show endpoints only for dependencies on a given contract")
+	cmd.Flags().StringVar(&filter.Cluster, "cluster", "", "Show endpoints only for instances running in a given cluster")
+
+	return cmd
 }
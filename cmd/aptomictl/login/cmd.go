@@ -32,6 +32,7 @@ func NewCommand(cfg *config.Client, cfgFile *string) *cobra.Command {
 			}
 
 			cfg.Auth.Token = authSuccess.Token
+			cfg.Auth.RefreshToken = authSuccess.RefreshToken
 
 			writeConfig(cfg, cfgFile)
 
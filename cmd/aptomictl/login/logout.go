@@ -0,0 +1,37 @@
+package login
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	log "github.com/Sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// NewLogoutCommand returns instance of cobra command that allows to logout from aptomi, revoking current tokens
+func NewLogoutCommand(cfg *config.Client, cfgFile *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "logout",
+		Short: "logout from the Aptomi",
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(cfg.Auth.Token) == 0 {
+				panic(fmt.Sprintf("No token found in config, already logged out"))
+			}
+
+			err := rest.New(cfg, http.NewClient(cfg)).User().Logout()
+			if err != nil {
+				panic(fmt.Sprintf("Error while user logout: %s", err))
+			}
+
+			cfg.Auth.Token = ""
+			cfg.Auth.RefreshToken = ""
+
+			writeConfig(cfg, cfgFile)
+
+			log.Infof("Tokens revoked and removed from config")
+		},
+	}
+
+	return cmd
+}
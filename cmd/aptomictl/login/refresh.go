@@ -0,0 +1,37 @@
+package login
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	log "github.com/Sirupsen/logrus"
+	"github.com/spf13/cobra"
+)
+
+// NewRefreshCommand returns instance of cobra command that allows to get a new access token using refresh token
+func NewRefreshCommand(cfg *config.Client, cfgFile *string) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refresh",
+		Short: "get a new access token without logging in again",
+		Run: func(cmd *cobra.Command, args []string) {
+			if len(cfg.Auth.RefreshToken) == 0 {
+				panic(fmt.Sprintf("No refresh token found in config, please login first"))
+			}
+
+			authSuccess, err := rest.New(cfg, http.NewClient(cfg)).User().Refresh(cfg.Auth.RefreshToken)
+			if err != nil {
+				panic(fmt.Sprintf("Error while refreshing token: %s", err))
+			}
+
+			cfg.Auth.Token = authSuccess.Token
+			cfg.Auth.RefreshToken = authSuccess.RefreshToken
+
+			writeConfig(cfg, cfgFile)
+
+			log.Infof("Config successfully updated with new token")
+		},
+	}
+
+	return cmd
+}
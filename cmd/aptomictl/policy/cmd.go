@@ -17,6 +17,10 @@ func NewCommand(cfg *config.Client) *cobra.Command {
 		newShowCommand(cfg),
 		newApplyCommand(cfg),
 		newDeleteCommand(cfg),
+		newDependenciesCommand(cfg),
+		newLintCommand(cfg),
+		newTestCommand(cfg),
+		newWhatIfCommand(cfg),
 	)
 
 	return cmd
@@ -0,0 +1,44 @@
+package policy
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/cmd/common"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+func newDependenciesCommand(cfg *config.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dependencies",
+		Short: "policy dependencies",
+		Long:  "policy dependencies shows dependencies along with their status and remaining lifetime",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := rest.New(cfg, http.NewClient(cfg)).Policy().Dependencies()
+			if err != nil {
+				panic(fmt.Sprintf("Error while requesting dependencies: %s", err))
+			}
+
+			if len(result.List) <= 0 {
+				fmt.Println("No dependencies found")
+				return
+			}
+
+			objs := make([]runtime.Displayable, len(result.List))
+			for idx, info := range result.List {
+				objs[idx] = info
+			}
+
+			data, err := common.Format(cfg.Output, true, objs...)
+			if err != nil {
+				panic(fmt.Sprintf("Error while formating dependencies: %s", err))
+			}
+			fmt.Println(string(data))
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,92 @@
+package policy
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/cmd/common"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/external/users"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+func newLintCommand(cfg *config.Client) *cobra.Command {
+	paths := make([]string, 0)
+	usersPath := ""
+	var gen uint64 // == runtime.Generation
+
+	cmd := &cobra.Command{
+		Use:   "lint",
+		Short: "lint policy files",
+		Long:  "policy lint reports issues in the policy, such as contracts without consumers, unreachable contexts and unused clusters. Policy files are linted locally if specified, otherwise the policy stored on the server is linted",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			var findings []*lang.LintFinding
+			if len(paths) > 0 {
+				findings = lintLocal(paths, usersPath)
+			} else {
+				result, err := rest.New(cfg, http.NewClient(cfg)).Policy().Lint(runtime.Generation(gen))
+				if err != nil {
+					panic(fmt.Sprintf("Error while linting policy: %s", err))
+				}
+				findings = result.List
+			}
+
+			if len(findings) <= 0 {
+				fmt.Println("No issues found")
+				return
+			}
+
+			objs := make([]runtime.Displayable, len(findings))
+			for idx, finding := range findings {
+				objs[idx] = finding
+			}
+
+			data, err := common.Format(cfg.Output, true, objs...)
+			if err != nil {
+				panic(fmt.Sprintf("Error while formating lint findings: %s", err))
+			}
+			fmt.Println(string(data))
+
+			if lang.HasLintErrors(findings) {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&paths, "policyPaths", "f", make([]string, 0), "Paths to files, dirs with policy to lint locally")
+	cmd.Flags().StringVarP(&usersPath, "users", "u", "", "Path to file with users to take their labels into account when linting locally, labels which are never set aren't reported without it")
+	cmd.Flags().Uint64VarP(&gen, "generation", "g", 0, "Policy generation to lint on the server (if no policy paths specified)")
+
+	return cmd
+}
+
+func lintLocal(paths []string, usersPath string) []*lang.LintFinding {
+	allObjects, err := readLangObjects(paths)
+	if err != nil {
+		panic(fmt.Sprintf("Error while reading policy files for linting: %s", err))
+	}
+
+	policy := lang.NewPolicy()
+	for _, obj := range allObjects {
+		err = policy.AddObject(obj.(lang.Base))
+		if err != nil {
+			panic(fmt.Sprintf("Error while adding object to policy: %s", err))
+		}
+	}
+
+	err = policy.Validate()
+	if err != nil {
+		panic(fmt.Sprintf("Policy is not valid: %s", err))
+	}
+
+	var globalUsers *lang.GlobalUsers
+	if len(usersPath) > 0 {
+		globalUsers = users.NewUserLoaderFromFile(usersPath, nil).LoadUsersAll()
+	}
+
+	return lang.NewPolicyLinter(policy, globalUsers).Lint()
+}
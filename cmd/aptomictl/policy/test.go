@@ -0,0 +1,77 @@
+package policy
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/engine/policytest"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/spf13/cobra"
+	"os"
+)
+
+func newTestCommand(cfg *config.Client) *cobra.Command {
+	paths := make([]string, 0)
+	testPaths := make([]string, 0)
+	var verbose bool
+
+	cmd := &cobra.Command{
+		Use:   "test",
+		Short: "run policy tests",
+		Long:  "policy test resolves policy locally against test cases (users, dependencies and expected outcomes) and reports which of them pass or fail",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			allObjects, err := readLangObjects(paths)
+			if err != nil {
+				panic(fmt.Sprintf("Error while reading policy files for testing: %s", err))
+			}
+
+			objects := make([]lang.Base, len(allObjects))
+			for idx, obj := range allObjects {
+				objects[idx] = obj.(lang.Base)
+			}
+
+			suites, err := policytest.LoadSuites(testPaths)
+			if err != nil {
+				panic(fmt.Sprintf("Error while reading policy test files: %s", err))
+			}
+
+			failed := 0
+			results := policytest.Run(objects, suites)
+			for _, result := range results {
+				if result.Passed() {
+					fmt.Printf("PASS: %s / %s\n", result.Suite, result.Name)
+					continue
+				}
+
+				failed++
+				fmt.Printf("FAIL: %s / %s\n", result.Suite, result.Name)
+				for _, failure := range result.Failures {
+					fmt.Printf("    %s\n", failure)
+				}
+				if verbose && len(result.Messages) > 0 {
+					fmt.Println("    policy resolution log:")
+					for _, message := range result.Messages {
+						fmt.Printf("      %s\n", message)
+					}
+				}
+			}
+
+			fmt.Printf("\n%d passed, %d failed\n", len(results)-failed, failed)
+			if failed > 0 {
+				os.Exit(1)
+			}
+		},
+	}
+
+	cmd.Flags().StringSliceVarP(&paths, "policyPaths", "f", make([]string, 0), "Paths to files, dirs with policy to test")
+	if err := cmd.MarkFlagRequired("policyPaths"); err != nil {
+		panic(err)
+	}
+	cmd.Flags().StringSliceVarP(&testPaths, "testPaths", "t", make([]string, 0), "Paths to files, dirs with policy tests")
+	if err := cmd.MarkFlagRequired("testPaths"); err != nil {
+		panic(err)
+	}
+	cmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Print policy resolution log for failed tests")
+
+	return cmd
+}
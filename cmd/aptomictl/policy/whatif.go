@@ -0,0 +1,65 @@
+package policy
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/cmd/common"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/spf13/cobra"
+	"strings"
+)
+
+func newWhatIfCommand(cfg *config.Client) *cobra.Command {
+	var contract string
+	var user string
+	var namespace string
+	labels := make([]string, 0)
+
+	cmd := &cobra.Command{
+		Use:   "whatif",
+		Short: "simulate dependency on a contract",
+		Long:  "policy whatif shows what user will get if they declare a dependency on a contract, without changing the policy",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			dependency := &lang.Dependency{
+				TypeKind: lang.DependencyObject.GetTypeKind(),
+				Metadata: lang.Metadata{
+					Namespace: namespace,
+				},
+				User:     user,
+				Contract: contract,
+				Labels:   make(map[string]string),
+			}
+			for _, label := range labels {
+				parts := strings.SplitN(label, "=", 2)
+				if len(parts) != 2 {
+					panic(fmt.Sprintf("Label should be specified as key=value, got: %s", label))
+				}
+				dependency.Labels[parts[0]] = parts[1]
+			}
+
+			result, err := rest.New(cfg, http.NewClient(cfg)).Policy().WhatIf(dependency)
+			if err != nil {
+				panic(fmt.Sprintf("Error while simulating dependency: %s", err))
+			}
+
+			data, err := common.Format(cfg.Output, false, result)
+			if err != nil {
+				panic(fmt.Sprintf("Error while formating what-if result: %s", err))
+			}
+			fmt.Println(string(data))
+		},
+	}
+
+	cmd.Flags().StringVar(&contract, "contract", "", "Contract to depend on")
+	if err := cmd.MarkFlagRequired("contract"); err != nil {
+		panic(err)
+	}
+	cmd.Flags().StringVar(&user, "user", "", "User on behalf of which dependency is simulated (current user, if not specified)")
+	cmd.Flags().StringVarP(&namespace, "namespace", "n", "main", "Namespace of the dependency")
+	cmd.Flags().StringSliceVarP(&labels, "label", "l", make([]string, 0), "Dependency labels in form of key=value")
+
+	return cmd
+}
@@ -2,12 +2,15 @@ package root
 
 import (
 	"fmt"
+	"github.com/Aptomi/aptomi/cmd/aptomictl/backup"
 	"github.com/Aptomi/aptomi/cmd/aptomictl/endpoints"
 	"github.com/Aptomi/aptomi/cmd/aptomictl/gen"
 	"github.com/Aptomi/aptomi/cmd/aptomictl/login"
 	"github.com/Aptomi/aptomi/cmd/aptomictl/policy"
 	"github.com/Aptomi/aptomi/cmd/aptomictl/revision"
 	"github.com/Aptomi/aptomi/cmd/aptomictl/state"
+	"github.com/Aptomi/aptomi/cmd/aptomictl/token"
+	"github.com/Aptomi/aptomi/cmd/aptomictl/users"
 	"github.com/Aptomi/aptomi/cmd/aptomictl/version"
 	"github.com/Aptomi/aptomi/cmd/common"
 	"github.com/Aptomi/aptomi/pkg/config"
@@ -53,17 +56,26 @@ func init() {
 
 	common.AddStringFlag(Command, "output", "output", "o", "text", EnvPrefix+"_OUTPUT", "Output format. One of: text (default), json, yaml")
 
+	common.AddStringFlag(Command, "auth.token", "token", "", "", EnvPrefix+"_TOKEN", "Token to authenticate with (e.g. API token for service accounts), overrides token saved by login")
+
+	common.AddStringFlag(Command, "http.contenttype", "content-type", "", "yaml", EnvPrefix+"_CONTENT_TYPE", "Format used for communicating with the server. One of: yaml (default), json")
+
 	common.AddDurationFlag(Command, "http.timeout", "timeout", "", 15*time.Second, EnvPrefix+"_TIMEOUT", "HTTP Timeout")
 
 	// Add sub commands
 	Command.AddCommand(
 		login.NewCommand(Config, ConfigFile),
+		login.NewRefreshCommand(Config, ConfigFile),
+		login.NewLogoutCommand(Config, ConfigFile),
 		endpoints.NewCommand(Config),
 		policy.NewCommand(Config),
 		revision.NewCommand(Config),
 		state.NewCommand(Config),
+		token.NewCommand(Config),
+		users.NewCommand(Config),
 		gen.NewCommand(Config),
 		version.NewCommand(Config),
+		backup.NewCommand(Config),
 	)
 }
 
@@ -5,17 +5,29 @@ import (
 	"github.com/Aptomi/aptomi/pkg/client/rest"
 	"github.com/Aptomi/aptomi/pkg/client/rest/http"
 	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/engine"
 	"github.com/spf13/cobra"
 )
 
 func newEnforceCommand(cfg *config.Client) *cobra.Command {
+	var reset bool
+
 	cmd := &cobra.Command{
 		Use:   "enforce",
 		Short: "state enforce",
 		Long:  "state enforce long",
 
 		Run: func(cmd *cobra.Command, args []string) {
-			rev, err := rest.New(cfg, http.NewClient(cfg)).State().Reset()
+			state := rest.New(cfg, http.NewClient(cfg)).State()
+
+			// actual state reset forces Aptomi to re-enforce the whole policy, otherwise enforcement is just triggered
+			var rev *engine.Revision
+			var err error
+			if reset {
+				rev, err = state.Reset()
+			} else {
+				rev, err = state.Enforce()
+			}
 
 			if err != nil {
 				panic(fmt.Sprintf("Error while state enforcement: %s", err))
@@ -26,5 +38,7 @@ func newEnforceCommand(cfg *config.Client) *cobra.Command {
 		},
 	}
 
+	cmd.Flags().BoolVar(&reset, "reset", true, "Reset actual state before enforcement, so the whole policy gets re-enforced")
+
 	return cmd
 }
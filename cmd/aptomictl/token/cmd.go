@@ -0,0 +1,23 @@
+package token
+
+import (
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand returns cobra command for token subcommand
+func NewCommand(cfg *config.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "token subcommand",
+		Long:  "token subcommand allows to manage long-lived API tokens for service accounts",
+	}
+
+	cmd.AddCommand(
+		newCreateCommand(cfg),
+		newListCommand(cfg),
+		newRevokeCommand(cfg),
+	)
+
+	return cmd
+}
@@ -0,0 +1,40 @@
+package token
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/api"
+	"github.com/Aptomi/aptomi/pkg/auth"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func newCreateCommand(cfg *config.Client) *cobra.Command {
+	request := &api.APITokenRequest{}
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "token create",
+		Long:  "token create creates a new API token, which acts on behalf of the current user",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := rest.New(cfg, http.NewClient(cfg)).Token().Create(request)
+			if err != nil {
+				panic(fmt.Sprintf("Error while creating token: %s", err))
+			}
+
+			fmt.Printf("Token '%s' created. Save it now, it can't be retrieved later:\n%s\n", result.Info.Name, result.Token)
+		},
+	}
+
+	cmd.Flags().StringVar(&request.Name, "name", "", "Token name")
+	if err := cmd.MarkFlagRequired("name"); err != nil {
+		panic(err)
+	}
+	cmd.Flags().StringSliceVar(&request.Scopes, "scope", []string{auth.ScopeReadOnly}, fmt.Sprintf("Token scopes, one or more of %s", auth.Scopes))
+	cmd.Flags().StringSliceVarP(&request.Namespaces, "namespace", "n", make([]string, 0), "Namespaces to which policy changes and reads made with the token are limited (all namespaces, if not specified)")
+	cmd.Flags().StringVar(&request.TTL, "ttl", "", "Token lifetime (e.g. '720h'). Token never expires, if not specified")
+
+	return cmd
+}
@@ -0,0 +1,44 @@
+package token
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/cmd/common"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/spf13/cobra"
+)
+
+func newListCommand(cfg *config.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "token list",
+		Long:  "token list shows API tokens created by the current user",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := rest.New(cfg, http.NewClient(cfg)).Token().List()
+			if err != nil {
+				panic(fmt.Sprintf("Error while requesting tokens: %s", err))
+			}
+
+			if len(result.List) <= 0 {
+				fmt.Println("No tokens found")
+				return
+			}
+
+			objs := make([]runtime.Displayable, len(result.List))
+			for idx, token := range result.List {
+				objs[idx] = token
+			}
+
+			data, err := common.Format(cfg.Output, true, objs...)
+			if err != nil {
+				panic(fmt.Sprintf("Error while formating tokens: %s", err))
+			}
+			fmt.Println(string(data))
+		},
+	}
+
+	return cmd
+}
@@ -0,0 +1,35 @@
+package token
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func newRevokeCommand(cfg *config.Client) *cobra.Command {
+	var name string
+
+	cmd := &cobra.Command{
+		Use:   "revoke",
+		Short: "token revoke",
+		Long:  "token revoke revokes API token, so it can't be used anymore",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			_, err := rest.New(cfg, http.NewClient(cfg)).Token().Revoke(name)
+			if err != nil {
+				panic(fmt.Sprintf("Error while revoking token: %s", err))
+			}
+
+			fmt.Printf("Token '%s' revoked\n", name)
+		},
+	}
+
+	cmd.Flags().StringVar(&name, "name", "", "Token name")
+	if err := cmd.MarkFlagRequired("name"); err != nil {
+		panic(err)
+	}
+
+	return cmd
+}
@@ -0,0 +1,22 @@
+package users
+
+import (
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+// NewCommand returns cobra command for users subcommand
+func NewCommand(cfg *config.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "users",
+		Short: "users subcommand",
+		Long:  "users subcommand allows to check status of the user directory cached by Aptomi and to refresh it",
+	}
+
+	cmd.AddCommand(
+		newStatusCommand(cfg),
+		newRefreshCommand(cfg),
+	)
+
+	return cmd
+}
@@ -0,0 +1,33 @@
+package users
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/cmd/common"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func newRefreshCommand(cfg *config.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "refresh",
+		Short: "users refresh",
+		Long:  "users refresh forces Aptomi to reload users from the user directory",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := rest.New(cfg, http.NewClient(cfg)).User().RefreshDirectory()
+			if err != nil {
+				panic(fmt.Sprintf("Error while refreshing users: %s", err))
+			}
+
+			data, err := common.Format(cfg.Output, false, result)
+			if err != nil {
+				panic(fmt.Sprintf("Error while formating user directory status: %s", err))
+			}
+			fmt.Println(string(data))
+		},
+	}
+
+	return cmd
+}
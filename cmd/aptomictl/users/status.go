@@ -0,0 +1,33 @@
+package users
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/cmd/common"
+	"github.com/Aptomi/aptomi/pkg/client/rest"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/spf13/cobra"
+)
+
+func newStatusCommand(cfg *config.Client) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "users status",
+		Long:  "users status shows how many users are cached and statistics of loading them from the user directory",
+
+		Run: func(cmd *cobra.Command, args []string) {
+			result, err := rest.New(cfg, http.NewClient(cfg)).User().Directory()
+			if err != nil {
+				panic(fmt.Sprintf("Error while requesting user directory status: %s", err))
+			}
+
+			data, err := common.Format(cfg.Output, false, result)
+			if err != nil {
+				panic(fmt.Sprintf("Error while formating user directory status: %s", err))
+			}
+			fmt.Println(string(data))
+		},
+	}
+
+	return cmd
+}
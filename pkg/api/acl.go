@@ -25,9 +25,9 @@ func (api *coreAPI) handleUserRoles(writer http.ResponseWriter, request *http.Re
 	systemNamespace := policy.Namespace[runtime.SystemNS]
 	var aclResolver *lang.ACLResolver
 	if systemNamespace != nil {
-		aclResolver = lang.NewACLResolver(systemNamespace.ACLRules)
+		aclResolver = lang.NewACLResolver(systemNamespace.ACLRules, systemNamespace.ACLRoles)
 	} else {
-		aclResolver = lang.NewACLResolver(lang.NewGlobalRules())
+		aclResolver = lang.NewACLResolver(lang.NewGlobalRules(), nil)
 	}
 
 	data := make(map[string]map[string]map[string]bool)
@@ -41,3 +41,19 @@ func (api *coreAPI) handleUserRoles(writer http.ResponseWriter, request *http.Re
 	}
 	api.contentType.WriteOne(writer, request, &userRolesWrapper{Data: data})
 }
+
+// checkOperation verifies that the user, who is making the request, is allowed to perform a given operation
+// according to the current policy. Otherwise it will panic with ACL error
+func (api *coreAPI) checkOperation(request *http.Request, operation string) {
+	user := api.getUserRequired(request)
+
+	policy, _, err := api.store.GetPolicy(runtime.LastGen)
+	if err != nil {
+		panic(fmt.Sprintf("error while getting policy: %s", err))
+	}
+
+	_, err = policy.View(user).CanPerform(operation)
+	if err != nil {
+		panic(fmt.Sprintf("error while checking privileges: %s", err))
+	}
+}
@@ -2,11 +2,14 @@ package api
 
 import (
 	"fmt"
+	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/julienschmidt/httprouter"
 	"net/http"
 )
 
 func (api *coreAPI) handleActualStateReset(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	api.checkOperation(request, lang.OperationResetActualState)
+
 	err := api.store.ResetActualState()
 	if err != nil {
 		panic(fmt.Sprintf("error while resetting actual state: %s", err))
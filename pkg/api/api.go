@@ -1,12 +1,16 @@
 package api
 
 import (
+	"fmt"
 	"github.com/Aptomi/aptomi/pkg/api/codec"
+	"github.com/Aptomi/aptomi/pkg/auth"
+	"github.com/Aptomi/aptomi/pkg/config"
 	"github.com/Aptomi/aptomi/pkg/external"
 	"github.com/Aptomi/aptomi/pkg/plugin"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/runtime/store"
 	"github.com/julienschmidt/httprouter"
+	"strings"
 )
 
 type coreAPI struct {
@@ -14,63 +18,116 @@ type coreAPI struct {
 	store                 store.Core
 	externalData          *external.Data
 	pluginRegistryFactory plugin.RegistryFactory
-	secret                string
+	authCfg               config.ServerAuth
+	externalVerifiers     map[string]*auth.ExternalVerifier
+	enforcementTrigger    chan<- struct{}
 }
 
-// Serve initializes everything needed by REST API and registers all API endpoints in the provided http router
-func Serve(router *httprouter.Router, store store.Core, externalData *external.Data, pluginRegistryFactory plugin.RegistryFactory, secret string) {
+// Serve initializes everything needed by REST API and registers all API endpoints in the provided http router.
+// Enforcement trigger channel is used to ask enforcer to run policy enforcement right away
+func Serve(router *httprouter.Router, store store.Core, externalData *external.Data, pluginRegistryFactory plugin.RegistryFactory, authCfg config.ServerAuth, enforcementTrigger chan<- struct{}) {
 	contentTypeHandler := codec.NewContentTypeHandler(runtime.NewRegistry().Append(Objects...))
 	api := &coreAPI{
 		contentType:           contentTypeHandler,
 		store:                 store,
 		externalData:          externalData,
 		pluginRegistryFactory: pluginRegistryFactory,
-		secret:                secret,
+		authCfg:               authCfg,
+		externalVerifiers:     make(map[string]*auth.ExternalVerifier),
+		enforcementTrigger:    enforcementTrigger,
+	}
+	aliases := make(map[string]bool)
+	for _, externalCfg := range authCfg.External {
+		verifier, err := auth.NewExternalVerifier(externalCfg)
+		if err != nil {
+			panic(fmt.Sprintf("error while configuring external auth: %s", err))
+		}
+		alias := strings.ToLower(verifier.Alias())
+		if aliases[alias] {
+			panic(fmt.Sprintf("error while configuring external auth: alias '%s' is used by more than one issuer", verifier.Alias()))
+		}
+		aliases[alias] = true
+		api.externalVerifiers[verifier.Issuer()] = verifier
 	}
 	api.serve(router)
 }
 
 func (api *coreAPI) serve(router *httprouter.Router) {
-	auth := api.auth
+	// every route declares API token scope required to call it, API tokens can't be used for routes without scope
+	noTokens := func(handle httprouter.Handle) httprouter.Handle { return api.auth("", handle) }
+	readOnly := func(handle httprouter.Handle) httprouter.Handle { return api.auth(auth.ScopeReadOnly, handle) }
+	policyApply := func(handle httprouter.Handle) httprouter.Handle { return api.auth(auth.ScopePolicyApply, handle) }
 
 	// authenticate user
 	router.POST("/api/v1/user/login", api.handleLogin)
 
+	// get new access token using refresh token, and revoke current user tokens
+	router.POST("/api/v1/user/refresh", api.handleRefresh)
+	router.POST("/api/v1/user/logout", noTokens(api.handleLogout))
+
+	// manage long-lived API tokens
+	router.POST("/api/v1/token", noTokens(api.handleTokenCreate))
+	router.GET("/api/v1/tokens", noTokens(api.handleTokensGet))
+	router.DELETE("/api/v1/token/:name", noTokens(api.handleTokenRevoke))
+
 	// get all users and their roles
-	router.GET("/api/v1/user/roles", auth(api.handleUserRoles))
+	router.GET("/api/v1/user/roles", readOnly(api.handleUserRoles))
+
+	// get status of the cached user directory and force its refresh
+	router.GET("/api/v1/user/directory", readOnly(api.handleUserDirectoryGet))
+	router.POST("/api/v1/user/directory/refresh", noTokens(api.handleUserDirectoryRefresh))
+
+	// download backup of all objects stored in DB
+	router.GET("/api/v1/backup", noTokens(api.handleBackup))
 
 	// retrieve policy (latest + by a given generation)
-	router.GET("/api/v1/policy", auth(api.handlePolicyGet))
-	router.GET("/api/v1/policy/gen/:gen", auth(api.handlePolicyGet))
+	router.GET("/api/v1/policy", readOnly(api.handlePolicyGet))
+	router.GET("/api/v1/policy/gen/:gen", readOnly(api.handlePolicyGet))
 
 	// retrieve specific object from the policy
-	router.GET("/api/v1/policy/gen/:gen/object/:ns/:kind/:name", auth(api.handlePolicyObjectGet))
+	router.GET("/api/v1/policy/gen/:gen/object/:ns/:kind/:name", readOnly(api.handlePolicyObjectGet))
 
 	// update policy
-	router.POST("/api/v1/policy", auth(api.handlePolicyUpdate))
-	router.DELETE("/api/v1/policy", auth(api.handlePolicyDelete))
+	router.POST("/api/v1/policy", policyApply(api.handlePolicyUpdate))
+	router.DELETE("/api/v1/policy", policyApply(api.handlePolicyDelete))
 
 	// policy diagrams
-	router.GET("/api/v1/policy/diagram/mode/:mode", auth(api.handlePolicyDiagram))
-	router.GET("/api/v1/policy/diagram/mode/:mode/gen/:gen", auth(api.handlePolicyDiagram))
-	router.GET("/api/v1/policy/diagram/compare/mode/:mode/gen/:gen/genBase/:genBase", auth(api.handlePolicyDiagramCompare))
+	router.GET("/api/v1/policy/diagram/mode/:mode", readOnly(api.handlePolicyDiagram))
+	router.GET("/api/v1/policy/diagram/mode/:mode/gen/:gen", readOnly(api.handlePolicyDiagram))
+	router.GET("/api/v1/policy/diagram/compare/mode/:mode/gen/:gen/genBase/:genBase", readOnly(api.handlePolicyDiagramCompare))
+
+	// lint policy (latest + by a given generation)
+	router.GET("/api/v1/policy/lint", readOnly(api.handlePolicyLint))
+	router.GET("/api/v1/policy/lint/gen/:gen", readOnly(api.handlePolicyLint))
+
+	// simulate dependency against the current policy without saving it
+	router.POST("/api/v1/policy/whatif", readOnly(api.handlePolicyWhatIf))
+
+	// retrieve dependencies along with their status and lifetime
+	router.GET("/api/v1/policy/dependencies", readOnly(api.handleDependenciesGet))
 
 	// retrieve dependency along with its status
-	router.GET("/api/v1/policy/dependency/:ns/:name/status", auth(api.handleDependencyStatusGet))
+	router.GET("/api/v1/policy/dependency/:ns/:name/status", readOnly(api.handleDependencyStatusGet))
 
 	// retrieve endpoints (all + by dependency)
-	router.GET("/api/v1/endpoints", api.handleEndpointsGet)
-	router.GET("/api/v1/endpoints/dependency/:ns/:name", auth(api.handleEndpointsGet))
+	router.GET("/api/v1/endpoints", readOnly(api.handleEndpointsGet))
+	router.GET("/api/v1/endpoints/dependency/:ns/:name", readOnly(api.handleEndpointsGet))
 
 	// retrieve revision (latest + by a given generation)
-	router.GET("/api/v1/revision", auth(api.handleRevisionGet))
-	router.GET("/api/v1/revision/gen/:gen", auth(api.handleRevisionGet))
+	router.GET("/api/v1/revision", readOnly(api.handleRevisionGet))
+	router.GET("/api/v1/revision/gen/:gen", readOnly(api.handleRevisionGet))
+
+	// stream revision progress until it's finished
+	router.GET("/api/v1/revision/gen/:gen"+WatchPathSuffix, readOnly(api.handleRevisionWatch))
 
 	// retrieve revision(s) (for a given policy)
-	router.GET("/api/v1/revision/policy/:policy", auth(api.handleRevisionGetByPolicy))
-	router.GET("/api/v1/revisions/policy/:policy", auth(api.handleRevisionsGetByPolicy))
+	router.GET("/api/v1/revision/policy/:policy", readOnly(api.handleRevisionGetByPolicy))
+	router.GET("/api/v1/revisions/policy/:policy", readOnly(api.handleRevisionsGetByPolicy))
+
+	router.DELETE("/api/v1/actualstate", noTokens(api.handleActualStateReset))
 
-	router.DELETE("/api/v1/actualstate", auth(api.handleActualStateReset))
+	// trigger policy enforcement without waiting for the next enforcement cycle
+	router.POST("/api/v1/enforcement", noTokens(api.handleEnforcementTrigger))
 
 	// return aptomi version
 	router.GET("/version", api.handleVersion)
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/api/codec"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/external"
+	"github.com/Aptomi/aptomi/pkg/external/secrets"
+	"github.com/Aptomi/aptomi/pkg/external/users"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/core"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/generic/memory"
+	"github.com/julienschmidt/httprouter"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const (
+	testDomainAdmin     = "domain-admin"
+	testNamespaceAdmin  = "namespace-admin"
+	testServiceConsumer = "service-consumer"
+	testNobody          = "nobody"
+)
+
+// testAPI is the API served from the in-memory store with a policy, which gives each test user the built-in role
+// with the same name
+type testAPI struct {
+	*coreAPI
+	router             *httprouter.Router
+	userLoader         *users.UserLoaderMock
+	enforcementTrigger chan struct{}
+}
+
+func newTestAPI(t *testing.T) *testAPI {
+	t.Helper()
+
+	generic := memory.NewGenericStore(runtime.NewRegistry().Append(store.Objects...))
+	if err := generic.Open(config.DB{Connection: "memory://"}); err != nil {
+		t.Fatalf("can't open in-memory store: %s", err)
+	}
+	s := core.NewStore(generic)
+	if err := s.InitPolicy(); err != nil {
+		t.Fatalf("can't init policy: %s", err)
+	}
+
+	userLoader := users.NewUserLoaderMock()
+	userLoader.AddUser(&lang.User{Name: testDomainAdmin, DomainAdmin: true})
+	userLoader.AddUser(&lang.User{Name: testNobody, Labels: map[string]string{}})
+
+	var objects []lang.Base
+	for _, role := range []string{testNamespaceAdmin, testServiceConsumer} {
+		userLoader.AddUser(&lang.User{Name: role, Labels: map[string]string{"role": role}})
+		objects = append(objects, &lang.ACLRule{
+			TypeKind: lang.ACLRuleObject.GetTypeKind(),
+			Metadata: lang.Metadata{Namespace: runtime.SystemNS, Name: role},
+			Weight:   len(objects),
+			Criteria: &lang.Criteria{RequireAll: []string{fmt.Sprintf("role == '%s'", role)}},
+			Actions:  &lang.RuleActions{AddRole: map[string]string{role: "main"}},
+		})
+	}
+	if _, _, err := s.UpdatePolicy(objects, "test"); err != nil {
+		t.Fatalf("can't update policy: %s", err)
+	}
+
+	enforcementTrigger := make(chan struct{}, 1)
+	api := &coreAPI{
+		contentType:  codec.NewContentTypeHandler(runtime.NewRegistry().Append(Objects...)),
+		store:        s,
+		externalData: external.NewData(userLoader, secrets.NewSecretLoaderMock()),
+		authCfg: config.ServerAuth{
+			Secret:          "secret",
+			AccessTokenTTL:  time.Hour,
+			RefreshTokenTTL: time.Hour,
+		},
+		enforcementTrigger: enforcementTrigger,
+	}
+	router := httprouter.New()
+	api.serve(router)
+
+	return &testAPI{
+		coreAPI:            api,
+		router:             router,
+		userLoader:         userLoader,
+		enforcementTrigger: enforcementTrigger,
+	}
+}
+
+// do performs request on behalf of a given user and returns recorded response. Panics are converted into internal
+// server errors same as by the panic handler middleware
+func (api *testAPI) do(userName string, method string, path string, body io.Reader) (response *httptest.ResponseRecorder) {
+	request := httptest.NewRequest(method, path, body)
+	if len(userName) > 0 {
+		user := api.userLoader.LoadUserByName(userName)
+		request.Header.Set("Authorization", "Bearer "+api.newAuthSuccess(user).Token)
+	}
+
+	response = httptest.NewRecorder()
+	defer func() {
+		if err := recover(); err != nil {
+			response.Code = http.StatusInternalServerError
+			response.Body.WriteString(fmt.Sprintf("%s", err))
+		}
+	}()
+	api.router.ServeHTTP(response, request)
+
+	return response
+}
+
+// isACLError returns true if the request was rejected because user doesn't have required privileges
+func isACLError(response *httptest.ResponseRecorder) bool {
+	return response.Code == http.StatusInternalServerError && strings.Contains(response.Body.String(), "doesn't have ACL permissions")
+}
+
+func TestOperationPrivileges(t *testing.T) {
+	testCases := []struct {
+		method  string
+		path    string
+		allowed []string
+	}{
+		{http.MethodDelete, "/api/v1/actualstate", []string{testDomainAdmin}},
+		{http.MethodPost, "/api/v1/enforcement", []string{testDomainAdmin}},
+		{http.MethodGet, "/api/v1/revision", []string{testDomainAdmin, testNamespaceAdmin, testServiceConsumer}},
+		{http.MethodGet, "/api/v1/revisions/policy/0", []string{testDomainAdmin, testNamespaceAdmin, testServiceConsumer}},
+		{http.MethodGet, "/api/v1/policy/diagram/mode/policy", []string{testDomainAdmin, testNamespaceAdmin}},
+		{http.MethodPost, "/api/v1/user/directory/refresh", []string{testDomainAdmin}},
+		{http.MethodGet, "/api/v1/backup", []string{testDomainAdmin}},
+	}
+
+	api := newTestAPI(t)
+	for _, tc := range testCases {
+		for _, user := range []string{testDomainAdmin, testNamespaceAdmin, testServiceConsumer, testNobody} {
+			response := api.do(user, tc.method, tc.path, nil)
+			allowed := false
+			for _, allowedUser := range tc.allowed {
+				allowed = allowed || allowedUser == user
+			}
+			assert.Equal(t, !allowed, isACLError(response), "User '%s' should be allowed (%t) to call %s %s, got: %s", user, allowed, tc.method, tc.path, response.Body.String())
+		}
+	}
+}
+
+func TestEnforcementTrigger(t *testing.T) {
+	api := newTestAPI(t)
+
+	api.do(testNamespaceAdmin, http.MethodPost, "/api/v1/enforcement", nil)
+	assert.Len(t, api.enforcementTrigger, 0, "Enforcement shouldn't be triggered by user without privileges")
+
+	// enforcement is triggered once, even if requested multiple times before enforcer picks it up
+	for i := 0; i < 2; i++ {
+		response := api.do(testDomainAdmin, http.MethodPost, "/api/v1/enforcement", nil)
+		assert.False(t, isACLError(response), "Domain admin should be able to trigger enforcement")
+	}
+	assert.Len(t, api.enforcementTrigger, 1, "Enforcement should be triggered")
+}
+
+func TestUnauthenticatedRequest(t *testing.T) {
+	api := newTestAPI(t)
+
+	response := api.do("", http.MethodGet, "/api/v1/revision", nil)
+	assert.Equal(t, http.StatusUnauthorized, response.Code, "Request without token should be rejected")
+}
+
+func TestRecordExternalUser(t *testing.T) {
+	api := newTestAPI(t)
+	user := &lang.User{Name: "idp:alice", Labels: map[string]string{"team": "red"}}
+
+	if !assert.NoError(t, api.recordExternalUser(user, "https://idp.example.com"), "External user should be recorded") {
+		return
+	}
+	record, err := api.store.GetExternalUser("idp:alice")
+	if assert.NoError(t, err, "External user should be loaded") && assert.NotNil(t, record, "External user should be recorded") {
+		assert.Equal(t, user.Labels, record.Labels, "External user labels should be recorded")
+	}
+
+	// record is updated only when labels change
+	updatedAt := record.UpdatedAt
+	assert.NoError(t, api.recordExternalUser(user, "https://idp.example.com"), "External user should be recorded")
+	record, _ = api.store.GetExternalUser("idp:alice")
+	assert.True(t, updatedAt.Equal(record.UpdatedAt), "Unchanged external user shouldn't be saved again")
+
+	user.Labels = map[string]string{"team": "blue"}
+	assert.NoError(t, api.recordExternalUser(user, "https://idp.example.com"), "External user should be recorded")
+	record, _ = api.store.GetExternalUser("idp:alice")
+	assert.Equal(t, "blue", record.Labels["team"], "Changed external user labels should be saved")
+
+	// user with the same name from a different issuer is rejected
+	assert.Error(t, api.recordExternalUser(user, "https://other.example.com"), "User from a different issuer should be rejected")
+	record, _ = api.store.GetExternalUser("idp:alice")
+	assert.Equal(t, "https://idp.example.com", record.Issuer, "User from a different issuer shouldn't overwrite the record")
+}
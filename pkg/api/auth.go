@@ -3,12 +3,14 @@ package api
 import (
 	"context"
 	"fmt"
+	"github.com/Aptomi/aptomi/pkg/auth"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/dgrijalva/jwt-go"
 	jwtreq "github.com/dgrijalva/jwt-go/request"
 	"github.com/julienschmidt/httprouter"
 	"net/http"
+	"reflect"
 	"time"
 )
 
@@ -22,6 +24,9 @@ var AuthSuccessObject = &runtime.Info{
 type AuthSuccess struct {
 	runtime.TypeKind `yaml:",inline"`
 	Token            string
+
+	// RefreshToken allows to get a new access token once the current one expires, without logging in again
+	RefreshToken string `yaml:",omitempty"`
 }
 
 // AuthRequestObject contains Info for the AuthRequest type
@@ -37,6 +42,18 @@ type AuthRequest struct {
 	Password         string
 }
 
+// AuthRefreshRequestObject contains Info for the AuthRefreshRequest type
+var AuthRefreshRequestObject = &runtime.Info{
+	Kind:        "auth-refresh-request",
+	Constructor: func() runtime.Object { return &AuthRefreshRequest{} },
+}
+
+// AuthRefreshRequest represents request to get a new access token using refresh token
+type AuthRefreshRequest struct {
+	runtime.TypeKind `yaml:",inline"`
+	RefreshToken     string
+}
+
 func (api *coreAPI) handleLogin(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	authReq, ok := api.contentType.ReadOne(request).(*AuthRequest)
 	if !ok {
@@ -48,16 +65,76 @@ func (api *coreAPI) handleLogin(writer http.ResponseWriter, request *http.Reques
 		serverErr := NewServerError(fmt.Sprintf("Authentication error: %s", err))
 		api.contentType.WriteOne(writer, request, serverErr)
 	} else {
-		api.contentType.WriteOne(writer, request, &AuthSuccess{
-			AuthSuccessObject.GetTypeKind(),
-			api.newToken(user),
-		})
+		api.contentType.WriteOne(writer, request, api.newAuthSuccess(user))
+	}
+}
+
+func (api *coreAPI) handleRefresh(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	refreshReq, ok := api.contentType.ReadOne(request).(*AuthRefreshRequest)
+	if !ok {
+		panic(fmt.Sprintf("Unexpected object received: %v", refreshReq))
+	}
+
+	claims, err := api.parseToken(refreshReq.RefreshToken, TokenTypeRefresh)
+	if err != nil {
+		serverErr := NewServerError(fmt.Sprintf("Authentication error: %s", err))
+		api.contentType.WriteOneWithStatus(writer, request, serverErr, http.StatusUnauthorized)
+		return
+	}
+
+	user := api.externalData.UserLoader.LoadUserByName(claims.Name)
+	if user == nil {
+		serverErr := NewServerError(fmt.Sprintf("Authentication error: token refers to non-existing user: %s", claims.Name))
+		api.contentType.WriteOneWithStatus(writer, request, serverErr, http.StatusUnauthorized)
+		return
 	}
+
+	// refresh tokens are rotated, so the old one (and corresponding access token) can't be used anymore. Revocation
+	// is atomic, so only one of concurrent requests with the same refresh token gets new tokens
+	revoked, err := api.store.RevokeUserToken(claims.Id, time.Unix(claims.ExpiresAt, 0))
+	if err != nil {
+		panic(fmt.Sprintf("Error while revoking refresh token: %s", err))
+	}
+	if !revoked {
+		serverErr := NewServerError("Authentication error: token is revoked")
+		api.contentType.WriteOneWithStatus(writer, request, serverErr, http.StatusUnauthorized)
+		return
+	}
+
+	api.contentType.WriteOne(writer, request, api.newAuthSuccess(user))
 }
 
-// Claims represent Aptomi JWT Claims
+func (api *coreAPI) handleLogout(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	claims, ok := request.Context().Value(ctxClaimsKey).(*Claims)
+	if !ok {
+		serverErr := NewServerError("Logout is only supported for tokens issued by Aptomi, API tokens should be revoked instead")
+		api.contentType.WriteOneWithStatus(writer, request, serverErr, http.StatusBadRequest)
+		return
+	}
+
+	// revoke both access and refresh tokens, which share the same ID. refresh token lives longer, so keep the
+	// revocation entry until it expires
+	_, err := api.store.RevokeUserToken(claims.Id, time.Unix(claims.IssuedAt, 0).Add(api.authCfg.RefreshTokenTTL))
+	if err != nil {
+		panic(fmt.Sprintf("Error while revoking token: %s", err))
+	}
+
+	api.contentType.WriteOne(writer, request, &AuthSuccess{TypeKind: AuthSuccessObject.GetTypeKind()})
+}
+
+const (
+	// TokenTypeAccess is a type of short-lived token, which is used to call the API
+	TokenTypeAccess = "access"
+
+	// TokenTypeRefresh is a type of long-lived token, which is only used to get new access tokens
+	TokenTypeRefresh = "refresh"
+)
+
+// Claims represent Aptomi JWT Claims. Access and refresh tokens issued together share the same ID ('jti'), so they
+// can be revoked together
 type Claims struct {
 	Name string `json:"name"`
+	Type string `json:"type"`
 	jwt.StandardClaims
 }
 
@@ -66,21 +143,44 @@ func (claims Claims) Valid() error {
 	if len(claims.Name) == 0 {
 		return fmt.Errorf("token should contain non-empty username")
 	}
+	if len(claims.Id) == 0 {
+		return fmt.Errorf("token should contain non-empty id")
+	}
 
 	return claims.StandardClaims.Valid()
 }
 
-func (api *coreAPI) newToken(user *lang.User) string {
+// newAuthSuccess issues new pair of access and refresh tokens for a given user
+func (api *coreAPI) newAuthSuccess(user *lang.User) *AuthSuccess {
+	id, err := auth.GenerateSecret()
+	if err != nil {
+		panic(fmt.Sprintf("error while generating token id: %s", err))
+	}
+
+	now := time.Now()
+	return &AuthSuccess{
+		TypeKind:     AuthSuccessObject.GetTypeKind(),
+		Token:        api.newToken(user, id, TokenTypeAccess, now, api.authCfg.AccessTokenTTL),
+		RefreshToken: api.newToken(user, id, TokenTypeRefresh, now, api.authCfg.RefreshTokenTTL),
+	}
+}
+
+func (api *coreAPI) newToken(user *lang.User, id string, tokenType string, now time.Time, ttl time.Duration) string {
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
 		Name: user.Name,
+		Type: tokenType,
 		StandardClaims: jwt.StandardClaims{
-			IssuedAt:  time.Now().Unix(),
-			ExpiresAt: time.Now().Add(30 * 24 * time.Hour).Unix(),
+			Id:        id,
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(ttl).Unix(),
 		},
 	})
+	if len(api.authCfg.KeyID) > 0 {
+		token.Header["kid"] = api.authCfg.KeyID
+	}
 
 	// Sign and get the complete encoded token as a string using the secret
-	tokenString, err := token.SignedString([]byte(api.secret))
+	tokenString, err := token.SignedString([]byte(api.authCfg.Secret))
 	if err != nil {
 		panic(fmt.Errorf("error while signing token: %s", err))
 	}
@@ -88,9 +188,53 @@ func (api *coreAPI) newToken(user *lang.User) string {
 	return tokenString
 }
 
-func (api *coreAPI) auth(handle httprouter.Handle) httprouter.Handle {
+// getSigningSecret returns secret for verifying token signed with a given key id
+func (api *coreAPI) getSigningSecret(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, fmt.Errorf("unexpected token signing method: %s", token.Header["alg"])
+	}
+
+	keyID, _ := token.Header["kid"].(string)
+	if keyID == api.authCfg.KeyID {
+		return []byte(api.authCfg.Secret), nil
+	}
+	// tokens issued before key id was configured don't have it, so they are verified with the legacy secret
+	// stored under an empty key id
+	if secret, ok := api.authCfg.PreviousSecrets[keyID]; ok {
+		return []byte(secret), nil
+	}
+
+	return nil, fmt.Errorf("token is signed with unknown key: %s", keyID)
+}
+
+// parseToken verifies user token of a given type and returns its claims
+func (api *coreAPI) parseToken(tokenString string, tokenType string) (*Claims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, api.getSigningSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := token.Claims.(*Claims)
+	if claims.Type != tokenType {
+		return nil, fmt.Errorf("%s token expected, but got: '%s'", tokenType, claims.Type)
+	}
+
+	revoked, err := api.store.IsUserTokenRevoked(claims.Id)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, fmt.Errorf("token is revoked")
+	}
+
+	return claims, nil
+}
+
+// auth returns handler, which authenticates the request before calling a given handler. Scope is an API token scope
+// required to call the handler, API tokens can't be used at all if scope is empty
+func (api *coreAPI) auth(scope string, handle httprouter.Handle) httprouter.Handle {
 	return func(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-		err := api.checkToken(request)
+		err := api.checkToken(request, scope)
 		if err != nil {
 			authErr := NewServerError(fmt.Sprintf("Authentication error: %s", err))
 			api.contentType.WriteOneWithStatus(writer, request, authErr, http.StatusUnauthorized)
@@ -107,33 +251,92 @@ type key int
 const (
 	// ctxUserKey is the context key for user
 	ctxUserKey key = iota
+
+	// ctxTokenKey is the context key for API token (if request is authenticated with API token)
+	ctxTokenKey
+
+	// ctxClaimsKey is the context key for user token claims (if request is authenticated with user token)
+	ctxClaimsKey
 )
 
-func (api *coreAPI) checkToken(request *http.Request) error {
-	token, err := jwtreq.ParseFromRequestWithClaims(request, jwtreq.AuthorizationHeaderExtractor, &Claims{},
-		func(token *jwt.Token) (interface{}, error) {
-			return []byte(api.secret), nil
-		})
+func (api *coreAPI) checkToken(request *http.Request, scope string) error {
+	tokenString, err := jwtreq.AuthorizationHeaderExtractor.ExtractToken(request)
 	if err != nil {
 		return err
 	}
-	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-		return fmt.Errorf("unexpected token signing method: %s", token.Header["alg"])
+
+	// long-lived API tokens are verified against the store, all other tokens are treated as user tokens
+	if name, secret, ok := auth.ParseTokenString(tokenString); ok {
+		return api.checkAPIToken(request, name, secret, scope)
+	}
+
+	// tokens issued by configured external identity providers are verified with their keys
+	if verifier, ok := api.externalVerifiers[auth.GetTokenIssuer(tokenString)]; ok {
+		user, errVerify := verifier.Verify(tokenString)
+		if errVerify != nil {
+			return errVerify
+		}
+		errRecord := api.recordExternalUser(user, verifier.Issuer())
+		if errRecord != nil {
+			return errRecord
+		}
+		api.storeUser(request, user, nil)
+		return nil
+	}
+
+	claims, err := api.parseToken(tokenString, TokenTypeAccess)
+	if err != nil {
+		return err
 	}
 
-	claims := token.Claims.(*Claims)
 	user := api.externalData.UserLoader.LoadUserByName(claims.Name)
 	if user == nil {
 		return fmt.Errorf("token refers to non-existing user: %s", claims.Name)
 	}
 
-	// store user into the request
-	newRequest := request.WithContext(context.WithValue(request.Context(), ctxUserKey, user))
-	*request = *newRequest
+	api.storeUser(request, user, nil)
+	*request = *request.WithContext(context.WithValue(request.Context(), ctxClaimsKey, claims))
 
 	return nil
 }
 
+// recordExternalUser saves user authenticated by external identity provider into the store, so policy resolution could
+// find it. Record is only updated when user labels change. User recorded from a different issuer is never overwritten
+func (api *coreAPI) recordExternalUser(user *lang.User, issuer string) error {
+	record, err := api.store.GetExternalUser(user.Name)
+	if err != nil {
+		return err
+	}
+	if record != nil && record.Issuer != issuer {
+		return fmt.Errorf("user '%s' is already authenticated by a different issuer", user.Name)
+	}
+	if record != nil && record.Name == user.Name && record.Issuer == issuer && reflect.DeepEqual(record.Labels, user.Labels) {
+		return nil
+	}
+
+	err = api.store.SaveExternalUser(&auth.ExternalUser{
+		TypeKind:  auth.ExternalUserObject.GetTypeKind(),
+		Name:      user.Name,
+		Issuer:    issuer,
+		Labels:    user.Labels,
+		UpdatedAt: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("error while recording external user: %s", err)
+	}
+
+	return nil
+}
+
+// storeUser stores user (and API token, if request was authenticated with it) into the request
+func (api *coreAPI) storeUser(request *http.Request, user *lang.User, token *auth.APIToken) {
+	ctx := context.WithValue(request.Context(), ctxUserKey, user)
+	if token != nil {
+		ctx = context.WithValue(ctx, ctxTokenKey, token)
+	}
+	*request = *request.WithContext(ctx)
+}
+
 func (api *coreAPI) getUserOptional(request *http.Request) *lang.User {
 	val := request.Context().Value(ctxUserKey)
 	if val == nil {
@@ -0,0 +1,173 @@
+package api
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/api/codec"
+	"github.com/Aptomi/aptomi/pkg/auth"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRefreshTokenConcurrent(t *testing.T) {
+	api := newTestAPI(t)
+	tokens := api.newAuthSuccess(api.userLoader.LoadUserByName(testServiceConsumer))
+
+	body, err := api.contentType.GetCodecByContentType(codec.Default).EncodeOne(&AuthRefreshRequest{
+		TypeKind:     AuthRefreshRequestObject.GetTypeKind(),
+		RefreshToken: tokens.RefreshToken,
+	})
+	if !assert.NoError(t, err, "Refresh request should be encoded") {
+		return
+	}
+
+	// the same refresh token used concurrently should only be exchanged for new tokens once
+	const requests = 10
+	codes := make(chan int, requests)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < requests; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			response := api.do("", http.MethodPost, "/api/v1/user/refresh", bytes.NewReader(body))
+			codes <- response.Code
+		}()
+	}
+	wg.Wait()
+	close(codes)
+
+	refreshed := 0
+	for code := range codes {
+		if code == http.StatusOK {
+			refreshed++
+		} else {
+			assert.Equal(t, http.StatusUnauthorized, code, "Refresh with revoked token should be rejected")
+		}
+	}
+	assert.Equal(t, 1, refreshed, "Refresh token should only be used once")
+}
+
+func TestLegacyTokenWithoutKeyID(t *testing.T) {
+	api := newTestAPI(t)
+
+	// token issued before key id was configured
+	legacyToken := api.newAuthSuccess(api.userLoader.LoadUserByName(testServiceConsumer)).Token
+	response := api.doWithToken(legacyToken, http.MethodGet, "/api/v1/policy")
+	assert.Equal(t, http.StatusOK, response.Code, "Token should be accepted: %s", response.Body.String())
+
+	// after secret is rotated, legacy token is verified with the secret stored under an empty key id
+	api.authCfg.PreviousSecrets = map[string]string{"": api.authCfg.Secret}
+	api.authCfg.Secret = "new-secret"
+	api.authCfg.KeyID = "new"
+	response = api.doWithToken(legacyToken, http.MethodGet, "/api/v1/policy")
+	assert.Equal(t, http.StatusOK, response.Code, "Legacy token should be accepted: %s", response.Body.String())
+
+	// but not when legacy secret isn't configured
+	api.authCfg.PreviousSecrets = nil
+	response = api.doWithToken(legacyToken, http.MethodGet, "/api/v1/policy")
+	assert.Equal(t, http.StatusUnauthorized, response.Code, "Legacy token shouldn't be accepted without legacy secret")
+
+	// new tokens are signed with the current key
+	newToken := api.newAuthSuccess(api.userLoader.LoadUserByName(testServiceConsumer)).Token
+	parsed, _ := jwt.Parse(newToken, api.getSigningSecret)
+	if assert.NotNil(t, parsed, "New token should be parsed") {
+		assert.Equal(t, "new", parsed.Header["kid"], "New token should be signed with the current key")
+	}
+}
+
+func TestLogoutWithoutUserToken(t *testing.T) {
+	api := newTestAPI(t)
+
+	// request authenticated with a token not issued by Aptomi (e.g. by external identity provider)
+	request := httptest.NewRequest(http.MethodPost, "/api/v1/user/logout", nil)
+	api.storeUser(request, api.userLoader.LoadUserByName(testServiceConsumer), nil)
+	response := httptest.NewRecorder()
+	api.handleLogout(response, request, nil)
+
+	assert.Equal(t, http.StatusBadRequest, response.Code, "Logout should be rejected as a bad request")
+	assert.Contains(t, response.Body.String(), "only supported for tokens issued by Aptomi", "Error should be returned")
+}
+
+func TestLogout(t *testing.T) {
+	api := newTestAPI(t)
+	tokens := api.newAuthSuccess(api.userLoader.LoadUserByName(testServiceConsumer))
+
+	response := api.doWithToken(tokens.Token, http.MethodPost, "/api/v1/user/logout")
+	assert.Equal(t, http.StatusOK, response.Code, "Logout should succeed: %s", response.Body.String())
+
+	response = api.doWithToken(tokens.Token, http.MethodGet, "/api/v1/policy")
+	assert.Equal(t, http.StatusUnauthorized, response.Code, "Access token shouldn't be accepted after logout")
+}
+
+func TestExternalTokenNameCollision(t *testing.T) {
+	api := newTestAPI(t)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if !assert.NoError(t, err, "RSA key should be generated") {
+		return
+	}
+	dir, err := ioutil.TempDir("", "aptomi-jwks")
+	if !assert.NoError(t, err, "Temp dir should be created") {
+		return
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	jwksFile := filepath.Join(dir, "jwks.json")
+	err = ioutil.WriteFile(jwksFile, []byte(fmt.Sprintf(
+		`{"keys": [{"kty": "RSA", "kid": "key1", "use": "sig", "n": "%s", "e": "%s"}]}`,
+		base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	)), 0644)
+	if !assert.NoError(t, err, "JWKS file should be written") {
+		return
+	}
+	verifier, err := auth.NewExternalVerifier(config.ExternalAuth{
+		Issuer:   "https://idp.example.com",
+		Alias:    "idp",
+		Audience: "aptomi",
+		JWKSFile: jwksFile,
+	})
+	if !assert.NoError(t, err, "Verifier should be created") {
+		return
+	}
+	api.externalVerifiers = map[string]*auth.ExternalVerifier{verifier.Issuer(): verifier}
+
+	// token issued by external identity provider for the user with the same name as local domain admin
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss": "https://idp.example.com",
+		"aud": "aptomi",
+		"sub": testDomainAdmin,
+		"exp": time.Now().Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = "key1"
+	tokenString, err := token.SignedString(key)
+	if !assert.NoError(t, err, "Token should be signed") {
+		return
+	}
+
+	request := httptest.NewRequest(http.MethodGet, "/api/v1/backup", nil)
+	request.Header.Set("Authorization", "Bearer "+tokenString)
+	if !assert.NoError(t, api.checkToken(request, ""), "External token should be accepted") {
+		return
+	}
+	user := api.getUserRequired(request)
+	assert.Equal(t, "idp:"+testDomainAdmin, user.Name, "External user should be namespaced by issuer alias")
+	assert.False(t, user.DomainAdmin, "External user shouldn't impersonate local user with the same name")
+
+	record, err := api.store.GetExternalUser(testDomainAdmin)
+	assert.NoError(t, err, "External user should be loaded")
+	assert.Nil(t, record, "External user shouldn't be recorded under the name of local user")
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"time"
+)
+
+func (api *coreAPI) handleBackup(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	api.checkOperation(request, lang.OperationBackup)
+
+	// archive is prepared in memory first, so the error could still be returned as a regular API error
+	data := &bytes.Buffer{}
+	err := api.store.Backup(data)
+	if err != nil {
+		panic(fmt.Sprintf("Error while making backup: %s", err))
+	}
+
+	writer.Header().Set("Content-Type", "application/gzip")
+	writer.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=aptomi-backup-%s.tar.gz", time.Now().UTC().Format("20060102-150405")))
+	writer.WriteHeader(http.StatusOK)
+
+	_, err = writer.Write(data.Bytes())
+	if err != nil {
+		panic(fmt.Sprintf("Error while writing backup: %s", err))
+	}
+}
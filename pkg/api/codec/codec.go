@@ -3,9 +3,12 @@ package codec
 import (
 	"fmt"
 	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/codec/json"
 	"github.com/Aptomi/aptomi/pkg/runtime/codec/yaml"
 	"io/ioutil"
+	"mime"
 	"net/http"
+	"strings"
 )
 
 const (
@@ -21,16 +24,17 @@ const (
 
 // ContentTypeHandler is a helper for working with Content-Type header and doing read/write for http requests/response
 type ContentTypeHandler struct {
-	codecs map[string]runtime.Codec
+	registry *runtime.Registry
+	codecs   map[string]runtime.Codec
 }
 
 // NewContentTypeHandler returns instance of ContentTypeHandler for provided runtime registry
 func NewContentTypeHandler(reg *runtime.Registry) *ContentTypeHandler {
 	codecs := make(map[string]runtime.Codec)
 	codecs[YAML] = yaml.NewCodec(reg)
-	codecs[JSON] = yaml.NewJSONCodec(reg)
+	codecs[JSON] = json.NewCodec(reg)
 
-	return &ContentTypeHandler{codecs}
+	return &ContentTypeHandler{reg, codecs}
 }
 
 // GetCodecByContentType returns runtime codec for provided content type that should be used
@@ -43,27 +47,72 @@ func (handler *ContentTypeHandler) GetCodecByContentType(contentType string) run
 	return codec
 }
 
-// GetCodec returns runtime codec for specified http headers based on the content type
+// GetCodec returns runtime codec for specified http headers based on the content type, it's used for reading body
 func (handler *ContentTypeHandler) GetCodec(header http.Header) runtime.Codec {
-	contentType := header.Get("Content-Type")
-	if len(contentType) == 0 {
+	return handler.GetCodecByContentType(handler.GetContentType(header))
+}
+
+// GetContentType returns content type for provided http headers, parameters (e.g. charset) are ignored and default
+// content type is returned if it isn't set or not supported
+func (handler *ContentTypeHandler) GetContentType(header http.Header) string {
+	contentType, _ := mediaType(header.Get("Content-Type"))
+	if _, exist := handler.codecs[contentType]; !exist {
 		contentType = Default
 	}
 
-	return handler.GetCodecByContentType(contentType)
+	return contentType
 }
 
-// GetContentType returns content type for provided http headers
-func (handler *ContentTypeHandler) GetContentType(header http.Header) string {
-	contentType := header.Get("Content-Type")
-	if len(contentType) == 0 {
-		contentType = Default
+// GetAcceptedContentType returns content type that should be used for response to the request with provided http
+// headers. It's the first supported content type listed in Accept header (quality values aren't taken into account)
+// or the request content type if none of them is supported, e.g. if there is no Accept header or it's "*/*"
+func (handler *ContentTypeHandler) GetAcceptedContentType(header http.Header) string {
+	contentType, _ := handler.accepted(header)
+	return contentType
+}
+
+// GetAcceptedAPIVersion returns API version objects should be converted to in response to the request with provided
+// http headers. It's taken from the version parameter of the accepted content type (e.g. "application/yaml; version=v2")
+// and it's empty if specific version isn't requested, in which case objects are written in hub versions of their kinds
+func (handler *ContentTypeHandler) GetAcceptedAPIVersion(header http.Header) string {
+	_, apiVersion := handler.accepted(header)
+	return apiVersion
+}
+
+// GetResponseCodec returns runtime codec that should be used for writing response to the request with provided http
+// headers, it uses accepted content type and converts objects to the accepted API version
+func (handler *ContentTypeHandler) GetResponseCodec(header http.Header) runtime.Codec {
+	contentType, apiVersion := handler.accepted(header)
+	if len(apiVersion) == 0 {
+		return handler.GetCodecByContentType(contentType)
 	}
+	if contentType == JSON {
+		return json.NewVersionedCodec(handler.registry, apiVersion)
+	}
+	return yaml.NewVersionedCodec(handler.registry, apiVersion)
+}
+
+func (handler *ContentTypeHandler) accepted(header http.Header) (string, string) {
+	for _, accepted := range strings.Split(header.Get("Accept"), ",") {
+		contentType, params := mediaType(accepted)
+		if _, exist := handler.codecs[contentType]; exist {
+			return contentType, params["version"]
+		}
+	}
+
+	contentType, params := mediaType(header.Get("Content-Type"))
 	if _, exist := handler.codecs[contentType]; !exist {
-		contentType = Default
+		return Default, ""
 	}
+	return contentType, params["version"]
+}
 
-	return contentType
+func mediaType(value string) (string, map[string]string) {
+	result, params, err := mime.ParseMediaType(strings.TrimSpace(value))
+	if err != nil {
+		return "", nil
+	}
+	return result, params
 }
 
 // ReadOne runtime object from the provided request using correct content type (taken from request)
@@ -101,11 +150,11 @@ func (handler *ContentTypeHandler) WriteOne(writer http.ResponseWriter, request
 // WriteOneWithStatus runtime object into the provided response writer using correct content type (taken from provided request)
 // with specified http status
 func (handler *ContentTypeHandler) WriteOneWithStatus(writer http.ResponseWriter, request *http.Request, body runtime.Object, status int) {
-	writer.Header().Set("Content-Type", handler.GetContentType(request.Header))
+	writer.Header().Set("Content-Type", handler.GetAcceptedContentType(request.Header))
 	writer.WriteHeader(status)
 
 	if body != nil {
-		data, err := handler.GetCodec(request.Header).EncodeOne(body)
+		data, err := handler.GetResponseCodec(request.Header).EncodeOne(body)
 		if err != nil {
 			panic(fmt.Sprintf("Error while encoding body of kind %s: %s", body.GetKind(), err))
 		}
@@ -126,11 +175,11 @@ func (handler *ContentTypeHandler) WriteMany(writer http.ResponseWriter, request
 // WriteManyWithStatus runtime objects into the provided response writer using correct content type (taken from provided request)
 // with specified http status
 func (handler *ContentTypeHandler) WriteManyWithStatus(writer http.ResponseWriter, request *http.Request, body []runtime.Object, status int) {
-	writer.Header().Set("Content-Type", handler.GetContentType(request.Header))
+	writer.Header().Set("Content-Type", handler.GetAcceptedContentType(request.Header))
 	writer.WriteHeader(status)
 
 	if body != nil {
-		data, err := handler.GetCodec(request.Header).EncodeMany(body)
+		data, err := handler.GetResponseCodec(request.Header).EncodeMany(body)
 		if err != nil {
 			if len(body) > 0 {
 				panic(fmt.Sprintf("Error while encoding body of kind %s: %s", body[0].GetKind(), err))
@@ -0,0 +1,66 @@
+package codec
+
+import (
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestContentNegotiation(t *testing.T) {
+	handler := NewContentTypeHandler(runtime.NewRegistry().Append(lang.ServiceObject))
+
+	testCases := []struct {
+		contentType string
+		accept      string
+		expected    string
+	}{
+		{"", "", YAML},
+		{JSON, "", JSON},
+		{"application/json; charset=utf-8", "", JSON},
+		{"", JSON, JSON},
+		{YAML, "text/html, application/json;q=0.9", JSON},
+		{JSON, "*/*", JSON},
+		{"text/plain", "text/html", YAML},
+	}
+	for _, tc := range testCases {
+		header := http.Header{}
+		header.Set("Content-Type", tc.contentType)
+		header.Set("Accept", tc.accept)
+		assert.Equal(t, tc.expected, handler.GetAcceptedContentType(header), "Response content type for Content-Type '%s' and Accept '%s'", tc.contentType, tc.accept)
+	}
+}
+
+func TestAcceptedAPIVersion(t *testing.T) {
+	handler := NewContentTypeHandler(runtime.NewRegistry().Append(lang.ServiceObject))
+
+	header := http.Header{}
+	assert.Empty(t, handler.GetAcceptedAPIVersion(header), "API version shouldn't be requested by default")
+
+	header.Set("Accept", "application/json; version=v2")
+	assert.Equal(t, "v2", handler.GetAcceptedAPIVersion(header), "API version should be taken from Accept header")
+	assert.Equal(t, JSON, handler.GetAcceptedContentType(header), "Content type should be taken from Accept header")
+
+	header.Set("Accept", "*/*")
+	header.Set("Content-Type", "application/yaml; version=v3")
+	assert.Equal(t, "v3", handler.GetAcceptedAPIVersion(header), "API version should be taken from Content-Type header")
+}
+
+func TestReadAndWriteJSON(t *testing.T) {
+	handler := NewContentTypeHandler(runtime.NewRegistry().Append(lang.ServiceObject))
+
+	request := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"kind": "service", "metadata": {"namespace": "main", "name": "svc"}}`))
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	obj := handler.ReadOne(request)
+	if !assert.IsType(t, &lang.Service{}, obj, "Service should be read from JSON request") {
+		return
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.WriteOne(recorder, request, obj)
+	assert.Equal(t, JSON, recorder.Header().Get("Content-Type"), "Response should be written in JSON")
+	assert.Contains(t, recorder.Body.String(), `"kind":"service"`, "Response should be written in JSON")
+}
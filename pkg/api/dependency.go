@@ -2,20 +2,71 @@ package api
 
 import (
 	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/util"
 	"github.com/julienschmidt/httprouter"
 	"net/http"
+	"sort"
+	"time"
 )
 
 type dependencyStatusWrapper struct {
-	Data interface{}
+	Data      interface{}
+	ExpiresAt time.Time `yaml:",omitempty"`
+	ExpiresIn string    `yaml:",omitempty"`
 }
 
 func (g *dependencyStatusWrapper) GetKind() string {
 	return "dependencyStatus"
 }
 
+// DependenciesObject is an informational data structure with Kind and Constructor for Dependencies
+var DependenciesObject = &runtime.Info{
+	Kind:        "dependencies",
+	Constructor: func() runtime.Object { return &Dependencies{} },
+}
+
+// Dependencies represents the list of dependencies visible to the user, along with their status and lifetime
+type Dependencies struct {
+	runtime.TypeKind `yaml:",inline"`
+	List             []*DependencyInfo
+}
+
+// DependencyInfo represents a single dependency along with its status and remaining lifetime
+type DependencyInfo struct {
+	Namespace string
+	Name      string
+	User      string
+	Contract  string
+	Status    string
+	TTL       string    `yaml:",omitempty"`
+	ExpiresAt time.Time `yaml:",omitempty"`
+	ExpiresIn string    `yaml:",omitempty"`
+}
+
+// GetDefaultColumns returns default set of columns to be displayed
+func (info *DependencyInfo) GetDefaultColumns() []string {
+	return []string{"Namespace", "Name", "User", "Contract", "Status", "Expires In"}
+}
+
+// AsColumns returns DependencyInfo representation as columns
+func (info *DependencyInfo) AsColumns() map[string]string {
+	expiresIn := info.ExpiresIn
+	if len(expiresIn) <= 0 {
+		expiresIn = "never"
+	}
+	return map[string]string{
+		"Namespace":  info.Namespace,
+		"Name":       info.Name,
+		"User":       info.User,
+		"Contract":   info.Contract,
+		"Status":     info.Status,
+		"Expires In": expiresIn,
+	}
+}
+
 func (api *coreAPI) handleDependencyStatusGet(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	gen := runtime.LastGen
 	policy, _, err := api.store.GetPolicy(gen)
@@ -26,6 +77,7 @@ func (api *coreAPI) handleDependencyStatusGet(writer http.ResponseWriter, reques
 	ns := params.ByName("ns")
 	kind := lang.DependencyObject.Kind
 	name := params.ByName("name")
+	api.checkTokenNamespace(request, ns)
 
 	obj, err := policy.GetObject(kind, name, ns)
 	if err != nil {
@@ -42,21 +94,81 @@ func (api *coreAPI) handleDependencyStatusGet(writer http.ResponseWriter, reques
 		panic(fmt.Sprintf("Can't load actual state to get endpoints: %s", err))
 	}
 
-	var status string
-	key := runtime.KeyForStorable(dependency)
+	expiresIn, _ := getDependencyExpiresIn(dependency, time.Now())
+	api.contentType.WriteOne(writer, request, &dependencyStatusWrapper{
+		Data:      getDependencyStatus(dependency, actualState),
+		ExpiresAt: dependency.ExpiresAt,
+		ExpiresIn: expiresIn,
+	})
+}
+
+func (api *coreAPI) handleDependenciesGet(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	user := api.getUserRequired(request)
+
+	policy, _, err := api.store.GetPolicy(runtime.LastGen)
+	if err != nil {
+		panic(fmt.Sprintf("error while getting requested policy: %s", err))
+	}
+
+	actualState, err := api.store.GetActualState()
+	if err != nil {
+		panic(fmt.Sprintf("Can't load actual state to get dependency status: %s", err))
+	}
+
+	now := time.Now()
+	view := policy.View(user)
+	result := []*DependencyInfo{}
+	for _, obj := range policy.GetObjectsByKind(lang.DependencyObject.Kind) {
+		if view.ViewObject(obj) != nil || !api.tokenAllowsNamespace(request, obj.GetNamespace()) {
+			continue
+		}
+
+		dependency := obj.(*lang.Dependency)
+		expiresIn, _ := getDependencyExpiresIn(dependency, now)
+		result = append(result, &DependencyInfo{
+			Namespace: dependency.Namespace,
+			Name:      dependency.Name,
+			User:      dependency.User,
+			Contract:  dependency.Contract,
+			Status:    getDependencyStatus(dependency, actualState),
+			TTL:       dependency.TTL,
+			ExpiresAt: dependency.ExpiresAt,
+			ExpiresIn: expiresIn,
+		})
+	}
 
-	foundRefs := false
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Namespace != result[j].Namespace {
+			return result[i].Namespace < result[j].Namespace
+		}
+		return result[i].Name < result[j].Name
+	})
+
+	api.contentType.WriteOne(writer, request, &Dependencies{
+		TypeKind: DependenciesObject.GetTypeKind(),
+		List:     result,
+	})
+}
+
+// getDependencyStatus returns whether dependency is deployed, based on the actual state
+func getDependencyStatus(dependency *lang.Dependency, actualState *resolve.PolicyResolution) string {
+	key := runtime.KeyForStorable(dependency)
 	for _, instance := range actualState.ComponentInstanceMap {
 		if _, ok := instance.DependencyKeys[key]; ok {
-			foundRefs = true
-			break
+			return "Deployed"
 		}
 	}
-	if foundRefs {
-		status = "Deployed"
-	} else {
-		status = "Not Deployed"
-	}
+	return "Not Deployed"
+}
 
-	api.contentType.WriteOne(writer, request, &dependencyStatusWrapper{Data: status})
+// getDependencyExpiresIn returns remaining lifetime of the dependency as a human-readable string
+func getDependencyExpiresIn(dependency *lang.Dependency, now time.Time) (string, bool) {
+	expiresIn, ok := dependency.GetExpiresIn(now)
+	if !ok {
+		return "", false
+	}
+	if expiresIn <= 0 {
+		return "expired", true
+	}
+	return util.NewTimeDiff(expiresIn).Humanize(), true
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/Aptomi/aptomi/pkg/engine/resolve"
 	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/visualization"
 	"github.com/julienschmidt/httprouter"
@@ -21,6 +22,9 @@ func (g *graphWrapper) GetKind() string {
 }
 
 func (api *coreAPI) handlePolicyDiagram(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	api.checkOperation(request, lang.OperationViewDiagrams)
+	api.checkTokenAllNamespaces(request)
+
 	mode := params.ByName("mode")
 	gen := params.ByName("gen")
 
@@ -66,6 +70,9 @@ func (api *coreAPI) handlePolicyDiagram(writer http.ResponseWriter, request *htt
 }
 
 func (api *coreAPI) handlePolicyDiagramCompare(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	api.checkOperation(request, lang.OperationViewDiagrams)
+	api.checkTokenAllNamespaces(request)
+
 	mode := params.ByName("mode")
 	gen := params.ByName("gen")
 	if len(gen) == 0 {
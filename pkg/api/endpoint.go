@@ -2,10 +2,11 @@ package api
 
 import (
 	"fmt"
+	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/julienschmidt/httprouter"
 	"net/http"
-	"strings"
+	"net/url"
 )
 
 // EndpointsObject is an informational data structure with Kind and Constructor for Endpoints
@@ -20,10 +21,76 @@ type Endpoints struct {
 	List             map[string]map[string]string
 }
 
+// EndpointsFilter allows to narrow down the list of returned endpoints. Empty fields match everything
+type EndpointsFilter struct {
+	// Namespace of the dependency
+	Namespace string
+
+	// Dependency is a name of the dependency
+	Dependency string
+
+	// Contract which dependency refers to
+	Contract string
+
+	// Cluster where component instances are running
+	Cluster string
+}
+
+// AsQuery returns filter representation as URL query parameters
+func (filter *EndpointsFilter) AsQuery() url.Values {
+	query := url.Values{}
+	for name, value := range map[string]string{
+		"namespace":  filter.Namespace,
+		"dependency": filter.Dependency,
+		"contract":   filter.Contract,
+		"cluster":    filter.Cluster,
+	} {
+		if len(value) > 0 {
+			query.Set(name, value)
+		}
+	}
+	return query
+}
+
 func (api *coreAPI) handleEndpointsGet(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
-	dependencyNamespace := params.ByName("ns")
-	dependencyName := params.ByName("name")
-	filterEnabled := len(dependencyNamespace) > 0 && len(dependencyName) > 0
+	query := request.URL.Query()
+	filter := &EndpointsFilter{
+		Namespace:  query.Get("namespace"),
+		Dependency: query.Get("dependency"),
+		Contract:   query.Get("contract"),
+		Cluster:    query.Get("cluster"),
+	}
+	if ns := params.ByName("ns"); len(ns) > 0 {
+		filter.Namespace = ns
+	}
+	if name := params.ByName("name"); len(name) > 0 {
+		filter.Dependency = name
+	}
+
+	user := api.getUserRequired(request)
+	policy, _, err := api.store.GetPolicy(runtime.LastGen)
+	if err != nil {
+		panic(fmt.Sprintf("error while getting policy: %s", err))
+	}
+
+	// collect dependencies which user can view and which match the filter. unless user is allowed to see endpoints
+	// of all users, only dependencies declared by the user will be considered
+	view := policy.View(user)
+	viewAll, _ := view.CanPerform(lang.OperationViewAllEndpoints)
+	dependencyKeys := make(map[string]bool)
+	for _, obj := range policy.GetObjectsByKind(lang.DependencyObject.Kind) {
+		dependency := obj.(*lang.Dependency)
+		if !viewAll && dependency.User != user.Name {
+			continue
+		}
+		if view.ViewObject(dependency) != nil || !api.tokenAllowsNamespace(request, dependency.Namespace) {
+			continue
+		}
+		if !filter.matches(dependency) {
+			continue
+		}
+		dependencyKeys[runtime.KeyForStorable(dependency)] = true
+	}
 
 	endpoints := make(map[string]map[string]string)
 	actualState, err := api.store.GetActualState()
@@ -31,24 +98,16 @@ func (api *coreAPI) handleEndpointsGet(writer http.ResponseWriter, request *http
 		panic(fmt.Sprintf("Can't load actual state to get endpoints: %s", err))
 	}
 	for _, instance := range actualState.ComponentInstanceMap {
-		if len(instance.Endpoints) > 0 {
-			// filter by dependency, if/as needed
-			add := true
-			if filterEnabled {
-				add = false
-				// ideally we want to retrieve the corresponding dependency from policy, but for now let's just do
-				// string-based checks (because key for storable objects is namespace/kind/name)
-				for key := range instance.DependencyKeys {
-					if strings.HasPrefix(key, dependencyNamespace) && strings.HasSuffix(key, dependencyName) {
-						add = true
-						break
-					}
-				}
-			}
-
-			// collect endpoints
-			if add {
+		if len(instance.Endpoints) <= 0 {
+			continue
+		}
+		if len(filter.Cluster) > 0 && filter.Cluster != instance.Metadata.Key.ClusterName {
+			continue
+		}
+		for key := range instance.DependencyKeys {
+			if dependencyKeys[key] {
 				endpoints[instance.GetName()] = instance.Endpoints
+				break
 			}
 		}
 	}
@@ -58,3 +117,17 @@ func (api *coreAPI) handleEndpointsGet(writer http.ResponseWriter, request *http
 		List:     endpoints,
 	})
 }
+
+// matches returns true if dependency matches the filter
+func (filter *EndpointsFilter) matches(dependency *lang.Dependency) bool {
+	if len(filter.Namespace) > 0 && filter.Namespace != dependency.Namespace {
+		return false
+	}
+	if len(filter.Dependency) > 0 && filter.Dependency != dependency.Name {
+		return false
+	}
+	if len(filter.Contract) > 0 && filter.Contract != dependency.Contract {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,19 @@
+package api
+
+import (
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+)
+
+func (api *coreAPI) handleEnforcementTrigger(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	api.checkOperation(request, lang.OperationTriggerEnforcement)
+
+	// enforcement is already pending if trigger can't be sent, so there is no need to wait for enforcer
+	select {
+	case api.enforcementTrigger <- struct{}{}:
+	default:
+	}
+
+	api.handleRevisionGet(writer, request, params)
+}
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// PolicyLintResultObject is an informational data structure with Kind and Constructor for PolicyLintResult
+var PolicyLintResultObject = &runtime.Info{
+	Kind:        "policy-lint-result",
+	Constructor: func() runtime.Object { return &PolicyLintResult{} },
+}
+
+// PolicyLintResult represents the list of issues found by policy linter
+type PolicyLintResult struct {
+	runtime.TypeKind `yaml:",inline"`
+	PolicyGeneration runtime.Generation
+	List             []*lang.LintFinding
+}
+
+func (api *coreAPI) handlePolicyLint(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	user := api.getUserRequired(request)
+	gen := params.ByName("gen")
+
+	if len(gen) == 0 {
+		gen = strconv.Itoa(int(runtime.LastGen))
+	}
+
+	policy, policyGen, err := api.store.GetPolicy(runtime.ParseGeneration(gen))
+	if err != nil {
+		panic(fmt.Sprintf("error while getting requested policy: %s", err))
+	}
+
+	if policy == nil {
+		// policy with the given generation not found
+		api.contentType.WriteOneWithStatus(writer, request, nil, http.StatusNotFound)
+		return
+	}
+
+	findings := lang.NewPolicyLinter(policy, api.externalData.UserLoader.LoadUsersAll()).Lint()
+
+	// only return findings for objects which user (and API token, if any) is allowed to view
+	view := policy.View(user)
+	result := []*lang.LintFinding{}
+	for _, finding := range findings {
+		parts := strings.Split(finding.Object, runtime.KeySeparator)
+		if len(parts) != 3 {
+			continue
+		}
+		obj, err := policy.GetObject(parts[1], parts[2], parts[0])
+		if err != nil || obj == nil || view.ViewObject(obj.(lang.Base)) != nil || !api.tokenAllowsNamespace(request, parts[0]) {
+			continue
+		}
+		result = append(result, finding)
+	}
+
+	api.contentType.WriteOne(writer, request, &PolicyLintResult{
+		TypeKind:         PolicyLintResultObject.GetTypeKind(),
+		PolicyGeneration: policyGen,
+		List:             result,
+	})
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+type streamingHandler struct {
+	handler http.Handler
+	suffix  string
+}
+
+// NewStreamingHandler returns HTTP handler which removes server write timeout for requests with path ending with the
+// provided suffix, as such requests stream their responses for as long as client keeps the connection open. It should
+// wrap all other handlers, as write deadline could be changed only on the original response writer
+func NewStreamingHandler(handler http.Handler, suffix string) http.Handler {
+	return &streamingHandler{handler, suffix}
+}
+
+func (h *streamingHandler) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if strings.HasSuffix(request.URL.Path, h.suffix) {
+		// if it's not supported, response will be just cut off by the write timeout and client will need to reconnect
+		http.NewResponseController(writer).SetWriteDeadline(time.Time{}) // nolint: errcheck
+	}
+
+	h.handler.ServeHTTP(writer, request)
+}
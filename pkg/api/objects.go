@@ -1,6 +1,7 @@
 package api
 
 import (
+	"github.com/Aptomi/aptomi/pkg/auth"
 	"github.com/Aptomi/aptomi/pkg/engine"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
@@ -11,10 +12,18 @@ var (
 	// Objects is a list of all objects used in API
 	Objects = runtime.AppendAll([]*runtime.Info{
 		EndpointsObject,
+		DependenciesObject,
 		PolicyUpdateResultObject,
+		PolicyLintResultObject,
+		PolicyWhatIfResultObject,
 		AuthSuccessObject,
 		AuthRequestObject,
+		AuthRefreshRequestObject,
+		APITokenRequestObject,
+		APITokenCreatedObject,
+		APITokensObject,
+		UserDirectoryObject,
 		ServerErrorObject,
 		version.BuildInfoObject,
-	}, lang.PolicyObjects, engine.Objects)
+	}, lang.PolicyObjects, engine.Objects, auth.Objects)
 )
@@ -14,6 +14,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 func (api *coreAPI) handlePolicyGet(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
@@ -32,10 +33,25 @@ func (api *coreAPI) handlePolicyGet(writer http.ResponseWriter, request *http.Re
 		// policy with the given generation not found
 		api.contentType.WriteOneWithStatus(writer, request, nil, http.StatusNotFound)
 	} else {
-		api.contentType.WriteOne(writer, request, policyData)
+		api.contentType.WriteOne(writer, request, api.filterPolicyData(request, policyData))
 	}
 }
 
+// filterPolicyData returns policy data with only the namespaces allowed by API token, which request is authenticated with
+func (api *coreAPI) filterPolicyData(request *http.Request, policyData *engine.PolicyData) *engine.PolicyData {
+	if api.getAPIToken(request) == nil {
+		return policyData
+	}
+	result := *policyData
+	result.Objects = make(map[string]map[string]map[string]runtime.Generation)
+	for ns, kindMap := range policyData.Objects {
+		if api.tokenAllowsNamespace(request, ns) {
+			result.Objects[ns] = kindMap
+		}
+	}
+	return &result
+}
+
 func (api *coreAPI) handlePolicyObjectGet(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
 	gen := params.ByName("gen")
 
@@ -51,6 +67,7 @@ func (api *coreAPI) handlePolicyObjectGet(writer http.ResponseWriter, request *h
 	ns := params.ByName("ns")
 	kind := params.ByName("kind")
 	name := params.ByName("name")
+	api.checkTokenNamespace(request, ns)
 
 	obj, err := policy.GetObject(kind, name, ns)
 	if err != nil {
@@ -185,7 +202,24 @@ func (api *coreAPI) handlePolicyUpdate(writer http.ResponseWriter, request *http
 	if err != nil {
 		panic(fmt.Sprintf("Error while loading current policy: %s", err))
 	}
+	now := time.Now()
 	for _, obj := range objects {
+		api.checkTokenNamespace(request, obj.GetNamespace())
+
+		// calculate admission and expiration time for dependencies, keeping them for the ones which already exist
+		if dependency, ok := obj.(*lang.Dependency); ok {
+			var existing *lang.Dependency
+			existingObj, errGet := currentPolicy.GetObject(lang.DependencyObject.Kind, dependency.Name, dependency.Namespace)
+			if errGet == nil && existingObj != nil {
+				existing = existingObj.(*lang.Dependency)
+			}
+			dependency.InitAdmission(existing, now)
+			errExpiration := dependency.InitExpiration(existing, now)
+			if errExpiration != nil {
+				panic(fmt.Sprintf("Error while adding updated object to policy: %s", errExpiration))
+			}
+		}
+
 		errAdd := currentPolicy.AddObject(obj)
 		if errAdd != nil {
 			panic(fmt.Sprintf("Error while adding updated object to policy: %s", errAdd))
@@ -236,6 +270,8 @@ func (api *coreAPI) handlePolicyDelete(writer http.ResponseWriter, request *http
 		panic(fmt.Sprintf("Error while loading current policy: %s", err))
 	}
 	for _, obj := range objects {
+		api.checkTokenNamespace(request, obj.GetNamespace())
+
 		errManage := currentPolicy.View(user).ManageObject(obj)
 		if errManage != nil {
 			panic(fmt.Sprintf("Error while removing object from policy: %s", errManage))
@@ -1,14 +1,24 @@
 package api
 
 import (
+	"bytes"
 	"fmt"
+	"github.com/Aptomi/aptomi/pkg/api/codec"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
+	log "github.com/Sirupsen/logrus"
 	"github.com/julienschmidt/httprouter"
 	"net/http"
 	"strconv"
 )
 
+// WatchPathSuffix is a suffix of API paths, which stream their responses
+const WatchPathSuffix = "/watch"
+
 func (api *coreAPI) handleRevisionGet(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	api.checkOperation(request, lang.OperationViewRevisions)
+
 	gen := params.ByName("gen")
 
 	if len(gen) == 0 {
@@ -28,6 +38,8 @@ func (api *coreAPI) handleRevisionGet(writer http.ResponseWriter, request *http.
 }
 
 func (api *coreAPI) handleRevisionGetByPolicy(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	api.checkOperation(request, lang.OperationViewRevisions)
+
 	policyGen := params.ByName("policy")
 
 	if len(policyGen) == 0 {
@@ -55,6 +67,8 @@ func (g *revisionsWrapper) GetKind() string {
 }
 
 func (api *coreAPI) handleRevisionsGetByPolicy(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	api.checkOperation(request, lang.OperationViewRevisions)
+
 	policyGen := params.ByName("policy")
 
 	if len(policyGen) == 0 {
@@ -72,3 +86,94 @@ func (api *coreAPI) handleRevisionsGetByPolicy(writer http.ResponseWriter, reque
 		api.contentType.WriteOne(writer, request, &revisionsWrapper{Data: revisions})
 	}
 }
+
+// handleRevisionWatch streams revision every time its progress is updated until it's finished or client disconnects.
+// Revisions are written as a stream of YAML documents or as a newline-delimited JSON depending on the content type
+func (api *coreAPI) handleRevisionWatch(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	api.checkOperation(request, lang.OperationViewRevisions)
+
+	// start watching before getting revision, so no updates are missed between them
+	watcher, err := api.store.WatchRevisions()
+	if err != nil {
+		panic(fmt.Sprintf("error while watching revisions: %s", err))
+	}
+	defer func() {
+		watcher.Stop()
+	}()
+
+	revision, err := api.store.GetRevision(runtime.ParseGeneration(params.ByName("gen")))
+	if err != nil {
+		panic(fmt.Sprintf("error while getting requested revision: %s", err))
+	}
+	if revision == nil {
+		api.contentType.WriteOneWithStatus(writer, request, nil, http.StatusNotFound)
+		return
+	}
+
+	contentType := api.contentType.GetAcceptedContentType(request.Header)
+	objCodec := api.contentType.GetResponseCodec(request.Header)
+	writer.Header().Set("Content-Type", contentType)
+	writer.WriteHeader(http.StatusOK)
+
+	// response is already started, so errors could be only logged from now on
+	var lastData []byte
+	write := func(revision *engine.Revision) bool {
+		data, encodeErr := objCodec.EncodeOne(revision)
+		if encodeErr != nil {
+			log.Warnf("Error while encoding revision %s for watch: %s", revision.GetGeneration(), encodeErr)
+			return false
+		}
+		// revision could be saved without any visible changes, there is no need to send it again
+		if bytes.Equal(data, lastData) {
+			return true
+		}
+		lastData = data
+		if contentType == codec.JSON {
+			data = append(data, '\n')
+		} else {
+			data = append([]byte("---\n"), data...)
+		}
+		_, writeErr := writer.Write(data)
+		if writeErr != nil {
+			return false
+		}
+		if flusher, ok := writer.(http.Flusher); ok {
+			flusher.Flush()
+		}
+		return true
+	}
+
+	if !write(revision) {
+		return
+	}
+	for revision.Status == engine.RevisionStatusInProgress {
+		select {
+		case <-request.Context().Done():
+			return
+		case event, ok := <-watcher.Events():
+			if !ok {
+				// watcher can't keep up with changes, re-create it and re-read revision to not miss its updates
+				watcher, err = api.store.WatchRevisions()
+				if err != nil {
+					log.Warnf("Error while watching revisions: %s", err)
+					return
+				}
+			} else if event.Generation != revision.GetGeneration() {
+				continue
+			}
+		}
+
+		updated, getErr := api.store.GetRevision(revision.GetGeneration())
+		if getErr != nil {
+			log.Warnf("Error while getting revision %s for watch: %s", revision.GetGeneration(), getErr)
+			return
+		}
+		if updated == nil {
+			return
+		}
+		revision = updated
+		if !write(revision) {
+			return
+		}
+	}
+}
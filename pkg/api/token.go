@@ -0,0 +1,239 @@
+package api
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/auth"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"time"
+)
+
+// APITokenRequestObject contains Info for the APITokenRequest type
+var APITokenRequestObject = &runtime.Info{
+	Kind:        "apitoken-request",
+	Constructor: func() runtime.Object { return &APITokenRequest{} },
+}
+
+// APITokenRequest represents request to create a new API token
+type APITokenRequest struct {
+	runtime.TypeKind `yaml:",inline"`
+	Name             string
+	Scopes           []string
+	Namespaces       []string `yaml:",omitempty"`
+
+	// TTL is a lifetime of the token (e.g. '720h'). Token never expires if TTL is not specified
+	TTL string `yaml:",omitempty"`
+}
+
+// APITokenCreatedObject contains Info for the APITokenCreated type
+var APITokenCreatedObject = &runtime.Info{
+	Kind:        "apitoken-created",
+	Constructor: func() runtime.Object { return &APITokenCreated{} },
+}
+
+// APITokenCreated represents newly created API token. Token string is only returned once and can't be retrieved later
+type APITokenCreated struct {
+	runtime.TypeKind `yaml:",inline"`
+	Token            string
+	Info             *auth.APIToken
+}
+
+// APITokensObject contains Info for the APITokens type
+var APITokensObject = &runtime.Info{
+	Kind:        "apitokens",
+	Constructor: func() runtime.Object { return &APITokens{} },
+}
+
+// APITokens represents list of API tokens
+type APITokens struct {
+	runtime.TypeKind `yaml:",inline"`
+	List             []*auth.APIToken
+}
+
+func (api *coreAPI) handleTokenCreate(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	api.checkNotAPIToken(request)
+	user := api.getUserRequired(request)
+
+	tokenReq, ok := api.contentType.ReadOne(request).(*APITokenRequest)
+	if !ok {
+		panic(fmt.Sprintf("Unexpected object received: %v", tokenReq))
+	}
+
+	now := time.Now()
+	token := &auth.APIToken{
+		TypeKind:   auth.APITokenObject.GetTypeKind(),
+		Name:       tokenReq.Name,
+		Owner:      user.Name,
+		Scopes:     tokenReq.Scopes,
+		Namespaces: tokenReq.Namespaces,
+		CreatedAt:  now,
+	}
+	if len(tokenReq.TTL) > 0 {
+		ttl, err := time.ParseDuration(tokenReq.TTL)
+		if err != nil || ttl <= 0 {
+			panic(fmt.Sprintf("Token TTL must be a valid positive duration (e.g. '720h'), but found '%s'", tokenReq.TTL))
+		}
+		token.ExpiresAt = now.Add(ttl)
+	}
+	if err := token.Validate(); err != nil {
+		panic(fmt.Sprintf("Invalid token: %s", err))
+	}
+
+	existing, err := api.store.GetToken(token.Name)
+	if err != nil {
+		panic(fmt.Sprintf("Error while checking existing tokens: %s", err))
+	}
+	if existing != nil {
+		panic(fmt.Sprintf("Token '%s' already exists", token.Name))
+	}
+
+	secret, err := auth.GenerateSecret()
+	if err != nil {
+		panic(fmt.Sprintf("Error while creating token: %s", err))
+	}
+	token.Hash = auth.HashSecret(secret)
+
+	err = api.store.SaveToken(token)
+	if err != nil {
+		panic(fmt.Sprintf("Error while saving token: %s", err))
+	}
+
+	api.contentType.WriteOne(writer, request, &APITokenCreated{
+		TypeKind: APITokenCreatedObject.GetTypeKind(),
+		Token:    auth.FormatTokenString(token.Name, secret),
+		Info:     withoutHash(token),
+	})
+}
+
+func (api *coreAPI) handleTokensGet(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	api.checkNotAPIToken(request)
+	user := api.getUserRequired(request)
+
+	tokens, err := api.store.ListTokens()
+	if err != nil {
+		panic(fmt.Sprintf("Error while getting tokens: %s", err))
+	}
+
+	result := []*auth.APIToken{}
+	for _, token := range tokens {
+		if token.Owner == user.Name {
+			result = append(result, withoutHash(token))
+		}
+	}
+
+	api.contentType.WriteOne(writer, request, &APITokens{
+		TypeKind: APITokensObject.GetTypeKind(),
+		List:     result,
+	})
+}
+
+func (api *coreAPI) handleTokenRevoke(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	api.checkNotAPIToken(request)
+	user := api.getUserRequired(request)
+
+	name := params.ByName("name")
+	token, err := api.store.GetToken(name)
+	if err != nil {
+		panic(fmt.Sprintf("Error while getting token: %s", err))
+	}
+	if token == nil || token.Owner != user.Name {
+		panic(fmt.Sprintf("Token '%s' not found", name))
+	}
+
+	err = api.store.DeleteToken(name)
+	if err != nil {
+		panic(fmt.Sprintf("Error while revoking token: %s", err))
+	}
+
+	api.contentType.WriteOne(writer, request, &APITokens{
+		TypeKind: APITokensObject.GetTypeKind(),
+		List:     []*auth.APIToken{withoutHash(token)},
+	})
+}
+
+// apiTokenLastUsedInterval is how often time of the last API token usage is saved, so every API call made with the
+// token doesn't result in a write to the store
+const apiTokenLastUsedInterval = time.Minute
+
+// checkAPIToken verifies API token with a given name and secret, and checks that the token has a given scope. If
+// token is valid, its owner gets stored into the request along with the token itself
+func (api *coreAPI) checkAPIToken(request *http.Request, name string, secret string, scope string) error {
+	token, err := api.store.GetToken(name)
+	if err != nil {
+		return err
+	}
+	if token == nil || !token.MatchesSecret(secret) {
+		return fmt.Errorf("invalid API token")
+	}
+
+	now := time.Now()
+	if token.IsExpired(now) {
+		return fmt.Errorf("API token '%s' is expired", token.Name)
+	}
+	if len(scope) == 0 {
+		return fmt.Errorf("API token '%s' can't be used for this API call", token.Name)
+	}
+	if !token.AllowsScope(scope) {
+		return fmt.Errorf("API token '%s' doesn't have scope '%s' required for this API call", token.Name, scope)
+	}
+
+	user := api.externalData.UserLoader.LoadUserByName(token.Owner)
+	if user == nil {
+		return fmt.Errorf("API token refers to non-existing user: %s", token.Owner)
+	}
+
+	if now.Sub(token.LastUsedAt) >= apiTokenLastUsedInterval {
+		err = api.store.UpdateTokenLastUsed(token.Name, now)
+		if err != nil {
+			return fmt.Errorf("error while updating API token: %s", err)
+		}
+	}
+
+	api.storeUser(request, user, token)
+
+	return nil
+}
+
+func (api *coreAPI) getAPIToken(request *http.Request) *auth.APIToken {
+	if token, ok := request.Context().Value(ctxTokenKey).(*auth.APIToken); ok {
+		return token
+	}
+	return nil
+}
+
+// checkNotAPIToken panics if request is authenticated with API token. API tokens can't be used to manage tokens
+func (api *coreAPI) checkNotAPIToken(request *http.Request) {
+	if token := api.getAPIToken(request); token != nil {
+		panic(fmt.Sprintf("API token '%s' can't be used to manage API tokens", token.Name))
+	}
+}
+
+// checkTokenNamespace panics if request is authenticated with API token, which doesn't allow access to a given
+// namespace
+func (api *coreAPI) checkTokenNamespace(request *http.Request, namespace string) {
+	if !api.tokenAllowsNamespace(request, namespace) {
+		panic(fmt.Sprintf("API token '%s' doesn't allow access to namespace '%s'", api.getAPIToken(request).Name, namespace))
+	}
+}
+
+// checkTokenAllNamespaces panics if request is authenticated with API token limited to a set of namespaces. It's used
+// for reads, which can't be narrowed down to namespaces (e.g. diagrams of the whole policy)
+func (api *coreAPI) checkTokenAllNamespaces(request *http.Request) {
+	if token := api.getAPIToken(request); token != nil && len(token.Namespaces) > 0 {
+		panic(fmt.Sprintf("API token '%s' is limited to namespaces %v and can't be used for this request", token.Name, token.Namespaces))
+	}
+}
+
+// tokenAllowsNamespace returns false if request is authenticated with API token, which doesn't allow access to a
+// given namespace. It's used to filter out namespaced data from the lists returned by API
+func (api *coreAPI) tokenAllowsNamespace(request *http.Request, namespace string) bool {
+	token := api.getAPIToken(request)
+	return token == nil || token.AllowsNamespace(namespace)
+}
+
+func withoutHash(token *auth.APIToken) *auth.APIToken {
+	result := *token
+	result.Hash = ""
+	return &result
+}
@@ -0,0 +1,192 @@
+package api
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/api/codec"
+	"github.com/Aptomi/aptomi/pkg/auth"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newTestToken saves API token with given scopes owned by a given user and returns token string
+func (api *testAPI) newTestToken(t *testing.T, owner string, name string, scopes ...string) string {
+	t.Helper()
+
+	secret, err := auth.GenerateSecret()
+	if err != nil {
+		t.Fatalf("can't generate token secret: %s", err)
+	}
+	err = api.store.SaveToken(&auth.APIToken{
+		TypeKind:  auth.APITokenObject.GetTypeKind(),
+		Name:      name,
+		Owner:     owner,
+		Scopes:    scopes,
+		Hash:      auth.HashSecret(secret),
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		t.Fatalf("can't save token: %s", err)
+	}
+
+	return auth.FormatTokenString(name, secret)
+}
+
+func (api *testAPI) doWithToken(token string, method string, path string) *httptest.ResponseRecorder {
+	request := httptest.NewRequest(method, path, nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+	response := httptest.NewRecorder()
+	api.router.ServeHTTP(response, request)
+	return response
+}
+
+func TestAPITokenScopes(t *testing.T) {
+	api := newTestAPI(t)
+	readOnly := api.newTestToken(t, testDomainAdmin, "read-only", auth.ScopeReadOnly)
+	policyApply := api.newTestToken(t, testDomainAdmin, "policy-apply", auth.ScopePolicyApply)
+
+	testCases := []struct {
+		method  string
+		path    string
+		allowed map[string]bool
+	}{
+		{http.MethodGet, "/api/v1/policy", map[string]bool{readOnly: true, policyApply: true}},
+		{http.MethodPost, "/api/v1/policy/whatif", map[string]bool{readOnly: true, policyApply: true}},
+		{http.MethodDelete, "/api/v1/policy", map[string]bool{policyApply: true}},
+		{http.MethodDelete, "/api/v1/actualstate", map[string]bool{}},
+		{http.MethodPost, "/api/v1/enforcement", map[string]bool{}},
+		{http.MethodGet, "/api/v1/backup", map[string]bool{}},
+		{http.MethodGet, "/api/v1/tokens", map[string]bool{}},
+	}
+	for _, tc := range testCases {
+		for _, token := range []string{readOnly, policyApply} {
+			response := func() (response *httptest.ResponseRecorder) {
+				// handlers may panic on empty request body, but it means that token was accepted
+				defer func() {
+					if err := recover(); err != nil {
+						response = httptest.NewRecorder()
+					}
+				}()
+				return api.doWithToken(token, tc.method, tc.path)
+			}()
+			assert.Equal(t, !tc.allowed[token], response.Code == http.StatusUnauthorized, "Token should be allowed (%t) to call %s %s, got: %s", tc.allowed[token], tc.method, tc.path, response.Body.String())
+		}
+	}
+}
+
+func TestAPITokenLastUsed(t *testing.T) {
+	api := newTestAPI(t)
+	token := api.newTestToken(t, testDomainAdmin, "ci", auth.ScopeReadOnly)
+
+	response := api.doWithToken(token, http.MethodGet, "/api/v1/policy")
+	assert.NotEqual(t, http.StatusUnauthorized, response.Code, "Token should be accepted")
+
+	saved, err := api.store.GetToken("ci")
+	if !assert.NoError(t, err, "Token should be loaded") || !assert.NotNil(t, saved, "Token should exist") {
+		return
+	}
+	lastUsed := saved.LastUsedAt
+	assert.False(t, lastUsed.IsZero(), "Token usage should be saved")
+
+	// token usage is saved not more often than once in a while
+	api.doWithToken(token, http.MethodGet, "/api/v1/policy")
+	saved, _ = api.store.GetToken("ci")
+	assert.True(t, lastUsed.Equal(saved.LastUsedAt), "Token usage shouldn't be saved on every API call")
+
+	// revoked token shouldn't be brought back by the usage update
+	assert.NoError(t, api.store.DeleteToken("ci"), "Token should be revoked")
+	assert.Error(t, api.store.UpdateTokenLastUsed("ci", time.Now()), "Usage of revoked token shouldn't be saved")
+	saved, err = api.store.GetToken("ci")
+	assert.NoError(t, err, "Token should be loaded")
+	assert.Nil(t, saved, "Revoked token shouldn't exist")
+}
+
+func TestAPITokenNamespaces(t *testing.T) {
+	api := newTestAPI(t)
+	_, _, err := api.store.UpdatePolicy([]lang.Base{
+		&lang.Dependency{
+			TypeKind: lang.DependencyObject.GetTypeKind(),
+			Metadata: lang.Metadata{Namespace: "main", Name: "dep-main"},
+			User:     testDomainAdmin,
+			Contract: "contract",
+		},
+		&lang.Dependency{
+			TypeKind: lang.DependencyObject.GetTypeKind(),
+			Metadata: lang.Metadata{Namespace: "other", Name: "dep-other"},
+			User:     testDomainAdmin,
+			Contract: "contract",
+		},
+	}, "test")
+	if !assert.NoError(t, err, "Policy should be updated") {
+		return
+	}
+
+	secret, err := auth.GenerateSecret()
+	if !assert.NoError(t, err, "Token secret should be generated") {
+		return
+	}
+	err = api.store.SaveToken(&auth.APIToken{
+		TypeKind:   auth.APITokenObject.GetTypeKind(),
+		Name:       "ci",
+		Owner:      testDomainAdmin,
+		Scopes:     []string{auth.ScopeReadOnly},
+		Namespaces: []string{"other"},
+		Hash:       auth.HashSecret(secret),
+		CreatedAt:  time.Now(),
+	})
+	if !assert.NoError(t, err, "Token should be saved") {
+		return
+	}
+	token := auth.FormatTokenString("ci", secret)
+
+	yamlCodec := api.contentType.GetCodecByContentType(codec.Default)
+	decode := func(response *httptest.ResponseRecorder) runtime.Object {
+		t.Helper()
+		if response.Code != http.StatusOK {
+			t.Fatalf("request failed: %s", response.Body.String())
+		}
+		result, errDecode := yamlCodec.DecodeOne(response.Body.Bytes())
+		if errDecode != nil {
+			t.Fatalf("can't decode response: %s", errDecode)
+		}
+		return result
+	}
+
+	// only namespaces allowed by token should be returned
+	policyData := decode(api.doWithToken(token, http.MethodGet, "/api/v1/policy")).(*engine.PolicyData)
+	assert.Contains(t, policyData.Objects, "other", "Allowed namespace should be returned")
+	assert.NotContains(t, policyData.Objects, "main", "Namespace not allowed by token shouldn't be returned")
+
+	dependencies := decode(api.doWithToken(token, http.MethodGet, "/api/v1/policy/dependencies")).(*Dependencies)
+	if assert.Len(t, dependencies.List, 1, "Only dependencies in allowed namespace should be returned") {
+		assert.Equal(t, "dep-other", dependencies.List[0].Name, "Only dependencies in allowed namespace should be returned")
+	}
+
+	// the same user without token should see everything
+	dependencies = decode(api.do(testDomainAdmin, http.MethodGet, "/api/v1/policy/dependencies", nil)).(*Dependencies)
+	assert.Len(t, dependencies.List, 2, "All dependencies should be returned without token")
+
+	// objects in namespaces not allowed by token and diagrams of the whole policy can't be read
+	for _, path := range []string{
+		"/api/v1/policy/gen/0/object/main/dependency/dep-main",
+		"/api/v1/policy/dependency/main/dep-main/status",
+		"/api/v1/policy/diagram/mode/policy",
+	} {
+		response := func() (response *httptest.ResponseRecorder) {
+			defer func() {
+				if errPanic := recover(); errPanic != nil {
+					response = httptest.NewRecorder()
+					response.Code = http.StatusInternalServerError
+					response.Body.WriteString(fmt.Sprintf("%s", errPanic))
+				}
+			}()
+			return api.doWithToken(token, http.MethodGet, path)
+		}()
+		assert.Contains(t, response.Body.String(), "API token 'ci'", "Token shouldn't allow to read %s", path)
+	}
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/external/users"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/julienschmidt/httprouter"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// UserDirectoryObject contains Info for the UserDirectory type
+var UserDirectoryObject = &runtime.Info{
+	Kind:        "user-directory",
+	Constructor: func() runtime.Object { return &UserDirectory{} },
+}
+
+// UserDirectory represents status of the cached user directory, including statistics of loading users into the cache
+type UserDirectory struct {
+	runtime.TypeKind `yaml:",inline"`
+	Stats            users.UserLoaderStats
+}
+
+// GetDefaultColumns returns default set of columns to be displayed
+func (directory *UserDirectory) GetDefaultColumns() []string {
+	return []string{"Users", "Loads", "Failures", "Last Loaded", "Last Load Duration", "Last Error"}
+}
+
+// AsColumns returns UserDirectory representation as columns
+func (directory *UserDirectory) AsColumns() map[string]string {
+	lastLoaded := "never"
+	if !directory.Stats.LastLoaded.IsZero() {
+		lastLoaded = directory.Stats.LastLoaded.Format(time.RFC3339)
+	}
+	return map[string]string{
+		"Users":              strconv.Itoa(directory.Stats.Users),
+		"Loads":              strconv.Itoa(directory.Stats.Loads),
+		"Failures":           strconv.Itoa(directory.Stats.Failures),
+		"Last Loaded":        lastLoaded,
+		"Last Load Duration": directory.Stats.LastLoadDuration.String(),
+		"Last Error":         directory.Stats.LastError,
+	}
+}
+
+func (api *coreAPI) handleUserDirectoryGet(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	api.getUserRequired(request)
+	loader := api.getCachedUserLoader()
+
+	api.contentType.WriteOne(writer, request, &UserDirectory{
+		TypeKind: UserDirectoryObject.GetTypeKind(),
+		Stats:    loader.Stats(),
+	})
+}
+
+func (api *coreAPI) handleUserDirectoryRefresh(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	api.checkOperation(request, lang.OperationRefreshUsers)
+	loader := api.getCachedUserLoader()
+
+	err := loader.Refresh()
+	if err != nil {
+		panic(fmt.Sprintf("Error while refreshing users: %s", err))
+	}
+
+	api.contentType.WriteOne(writer, request, &UserDirectory{
+		TypeKind: UserDirectoryObject.GetTypeKind(),
+		Stats:    loader.Stats(),
+	})
+}
+
+// getCachedUserLoader returns cached user directory, which could be combined with other user sources (e.g. users
+// authenticated by external identity providers)
+func (api *coreAPI) getCachedUserLoader() *users.UserLoaderCached {
+	loaders := []users.UserLoader{api.externalData.UserLoader}
+	if multiple, ok := api.externalData.UserLoader.(*users.UserLoaderMultipleSources); ok {
+		loaders = multiple.Loaders()
+	}
+	for _, loader := range loaders {
+		if cached, ok := loader.(*users.UserLoaderCached); ok {
+			return cached
+		}
+	}
+	panic("User directory caching is not enabled")
+}
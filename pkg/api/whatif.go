@@ -0,0 +1,162 @@
+package api
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/Sirupsen/logrus"
+	"github.com/julienschmidt/httprouter"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+// whatIfNamePrefix is a prefix of the names generated for simulated dependencies
+const whatIfNamePrefix = "whatif-"
+
+// PolicyWhatIfResultObject is an informational data structure with Kind and Constructor for PolicyWhatIfResult
+var PolicyWhatIfResultObject = &runtime.Info{
+	Kind:        "policy-whatif-result",
+	Constructor: func() runtime.Object { return &PolicyWhatIfResult{} },
+}
+
+// PolicyWhatIfResult represents results of simulating a dependency against the current policy (what user would
+// get if the dependency was declared)
+type PolicyWhatIfResult struct {
+	runtime.TypeKind `yaml:",inline"`
+	PolicyGeneration runtime.Generation
+	Dependency       string
+	Resolved         bool
+	Context          string   `yaml:",omitempty"`
+	Cluster          string   `yaml:",omitempty"`
+	Components       []string `yaml:",omitempty"`
+	Messages         []string `yaml:",omitempty"`
+}
+
+// GetDefaultColumns returns default set of columns to be displayed
+func (result *PolicyWhatIfResult) GetDefaultColumns() []string {
+	return []string{"Resolved", "Context", "Cluster", "Components", "Messages"}
+}
+
+// AsColumns returns PolicyWhatIfResult representation as columns
+func (result *PolicyWhatIfResult) AsColumns() map[string]string {
+	return map[string]string{
+		"Resolved":   fmt.Sprintf("%t", result.Resolved),
+		"Context":    result.Context,
+		"Cluster":    result.Cluster,
+		"Components": strings.Join(result.Components, "\n"),
+		"Messages":   strings.Join(result.Messages, "\n"),
+	}
+}
+
+func (api *coreAPI) handlePolicyWhatIf(writer http.ResponseWriter, request *http.Request, params httprouter.Params) {
+	objects := api.readLang(request)
+
+	user := api.getUserRequired(request)
+
+	if len(objects) != 1 {
+		panic(fmt.Sprintf("Exactly one dependency expected for what-if simulation, got %d objects", len(objects)))
+	}
+	dependency, ok := objects[0].(*lang.Dependency)
+	if !ok {
+		panic(fmt.Sprintf("Dependency expected for what-if simulation, got: %s", objects[0].GetKind()))
+	}
+	api.checkTokenNamespace(request, dependency.Namespace)
+	if len(dependency.User) <= 0 {
+		dependency.User = user.Name
+	}
+
+	// Add dependency into the current policy. It never gets persisted
+	policy, policyGen, err := api.store.GetPolicy(runtime.LastGen)
+	if err != nil {
+		panic(fmt.Sprintf("Error while loading current policy: %s", err))
+	}
+	// Simulated dependency should never replace the existing one, as it would change the results (e.g. quota usage),
+	// so it gets a unique name if name isn't specified
+	if len(dependency.Name) <= 0 {
+		dependency.Name = whatIfDependencyName(policy, dependency.Namespace)
+	} else if existing, _ := policy.GetObject(lang.DependencyObject.Kind, dependency.Name, dependency.Namespace); existing != nil {
+		panic(fmt.Sprintf("Dependency %s/%s already exists in the policy, name of the simulated dependency shouldn't be specified", dependency.Namespace, dependency.Name))
+	}
+
+	depKey := runtime.KeyForStorable(dependency)
+	result := &PolicyWhatIfResult{
+		TypeKind:         PolicyWhatIfResultObject.GetTypeKind(),
+		PolicyGeneration: policyGen,
+		Dependency:       depKey,
+		Components:       []string{},
+		Messages:         []string{},
+	}
+
+	// Simulated dependency is the newest one in the policy, so it gets admitted last (e.g. for quotas)
+	now := time.Now()
+	dependency.InitAdmission(nil, now)
+	errExpiration := dependency.InitExpiration(nil, now)
+	if errExpiration != nil {
+		result.Messages = append(result.Messages, fmt.Sprintf("Dependency rejected: %s", errExpiration))
+		api.contentType.WriteOne(writer, request, result)
+		return
+	}
+
+	errAdd := policy.AddObject(dependency)
+	if errAdd != nil {
+		panic(fmt.Sprintf("Error while adding dependency to policy: %s", errAdd))
+	}
+	errManage := policy.View(user).ManageObject(dependency)
+	if errManage != nil {
+		panic(fmt.Sprintf("Error while adding dependency to policy: %s", errManage))
+	}
+
+	// Dependency should pass the same validation as on policy update, otherwise it would be rejected on apply
+	errValidate := policy.Validate()
+	if errValidate != nil {
+		result.Messages = append(result.Messages, fmt.Sprintf("Dependency rejected: %s", errValidate))
+		api.contentType.WriteOne(writer, request, result)
+		return
+	}
+
+	resolver := resolve.NewPolicyResolver(policy, api.externalData, event.NewLog("api-policy-whatif", false))
+	resolution, err := resolver.ResolveAllDependencies()
+	if err != nil {
+		panic(fmt.Sprintf("Cannot resolve policy: %s", err))
+	}
+
+	// resolution messages come from the event log, so they are only returned to users allowed to view event logs
+	if viewEventLogs, _ := policy.View(user).CanPerform(lang.OperationViewEventLogs); viewEventLogs {
+		if eventLog := resolver.GetDependencyEventLog(dependency); eventLog != nil {
+			result.Messages = eventLog.GetMessages(logrus.WarnLevel)
+		}
+	}
+
+	serviceKey, resolved := resolution.GetDependencyInstanceMap()[depKey]
+	if resolved {
+		service := resolution.ComponentInstanceMap[serviceKey].Metadata.Key
+		result.Resolved = true
+		result.Context = service.ContextName
+		result.Cluster = service.ClusterName
+		for key, instance := range resolution.ComponentInstanceMap {
+			if instance.DependencyKeys[depKey] {
+				result.Components = append(result.Components, key)
+			}
+		}
+		sort.Strings(result.Components)
+	}
+
+	api.contentType.WriteOne(writer, request, result)
+}
+
+// whatIfDependencyName returns name for the simulated dependency, which isn't used by any dependency in the namespace
+func whatIfDependencyName(policy *lang.Policy, namespace string) string {
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	for {
+		name := whatIfNamePrefix + strings.ToLower(util.RandomID(random, 16))
+		if existing, _ := policy.GetObject(lang.DependencyObject.Kind, name, namespace); existing == nil {
+			return name
+		}
+	}
+}
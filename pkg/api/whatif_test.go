@@ -0,0 +1,132 @@
+package api
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/api/codec"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"testing"
+)
+
+func TestPolicyWhatIfMessages(t *testing.T) {
+	api := newTestAPI(t)
+
+	// custom role, which allows to manage dependencies, but not to view event logs
+	dependencyManager := &lang.ACLRole{
+		TypeKind: lang.ACLRoleObject.GetTypeKind(),
+		Metadata: lang.Metadata{Namespace: runtime.SystemNS, Name: "dependency-manager"},
+		Privileges: &lang.Privileges{
+			NamespaceObjects: map[string]*lang.Privilege{
+				lang.ServiceObject.Kind:    {View: true},
+				lang.ContractObject.Kind:   {View: true},
+				lang.DependencyObject.Kind: {View: true, Manage: true},
+			},
+		},
+	}
+	// contract, which can't be fulfilled, so resolution always produces messages
+	_, _, err := api.store.UpdatePolicy([]lang.Base{
+		&lang.Service{
+			TypeKind: lang.ServiceObject.GetTypeKind(),
+			Metadata: lang.Metadata{Namespace: "main", Name: "service"},
+		},
+		&lang.Contract{
+			TypeKind: lang.ContractObject.GetTypeKind(),
+			Metadata: lang.Metadata{Namespace: "main", Name: "contract"},
+			Contexts: []*lang.Context{{
+				Name:       "never",
+				Criteria:   &lang.Criteria{RequireAll: []string{"false"}},
+				Allocation: &lang.Allocation{Service: "service"},
+			}},
+		},
+		dependencyManager,
+		&lang.ACLRule{
+			TypeKind: lang.ACLRuleObject.GetTypeKind(),
+			Metadata: lang.Metadata{Namespace: runtime.SystemNS, Name: dependencyManager.Name},
+			Weight:   100,
+			Criteria: &lang.Criteria{RequireAll: []string{fmt.Sprintf("role == '%s'", dependencyManager.Name)}},
+			Actions:  &lang.RuleActions{AddRole: map[string]string{dependencyManager.Name: "main"}},
+		},
+	}, "test")
+	if !assert.NoError(t, err, "Policy should be updated") {
+		return
+	}
+	api.userLoader.AddUser(&lang.User{Name: dependencyManager.Name, Labels: map[string]string{"role": dependencyManager.Name}})
+
+	yamlCodec := api.contentType.GetCodecByContentType(codec.Default)
+	whatIf := func(user string) *PolicyWhatIfResult {
+		t.Helper()
+		body, errEncode := yamlCodec.EncodeOne(&lang.Dependency{
+			TypeKind: lang.DependencyObject.GetTypeKind(),
+			Metadata: lang.Metadata{Namespace: "main"},
+			Contract: "contract",
+		})
+		if errEncode != nil {
+			t.Fatalf("can't encode dependency: %s", errEncode)
+		}
+		response := api.do(user, http.MethodPost, "/api/v1/policy/whatif", bytes.NewReader(body))
+		if response.Code != http.StatusOK {
+			t.Fatalf("what-if simulation failed for user '%s': %s", user, response.Body.String())
+		}
+		result, errDecode := yamlCodec.DecodeOne(response.Body.Bytes())
+		if errDecode != nil {
+			t.Fatalf("can't decode what-if result: %s", errDecode)
+		}
+		return result.(*PolicyWhatIfResult)
+	}
+
+	result := whatIf(testServiceConsumer)
+	assert.False(t, result.Resolved, "Dependency on contract without matching context shouldn't be resolved")
+	assert.NotEmpty(t, result.Messages, "Resolution messages should be returned to user allowed to view event logs")
+
+	result = whatIf(dependencyManager.Name)
+	assert.False(t, result.Resolved, "Dependency on contract without matching context shouldn't be resolved")
+	assert.Empty(t, result.Messages, "Resolution messages shouldn't be returned to user not allowed to view event logs")
+}
+
+func TestPolicyWhatIfRejected(t *testing.T) {
+	api := newTestAPI(t)
+
+	yamlCodec := api.contentType.GetCodecByContentType(codec.Default)
+	whatIf := func(dependency *lang.Dependency) *PolicyWhatIfResult {
+		t.Helper()
+		body, errEncode := yamlCodec.EncodeOne(dependency)
+		if errEncode != nil {
+			t.Fatalf("can't encode dependency: %s", errEncode)
+		}
+		response := api.do(testServiceConsumer, http.MethodPost, "/api/v1/policy/whatif", bytes.NewReader(body))
+		if response.Code != http.StatusOK {
+			t.Fatalf("what-if simulation failed: %s", response.Body.String())
+		}
+		result, errDecode := yamlCodec.DecodeOne(response.Body.Bytes())
+		if errDecode != nil {
+			t.Fatalf("can't decode what-if result: %s", errDecode)
+		}
+		return result.(*PolicyWhatIfResult)
+	}
+
+	// dependency with invalid TTL
+	result := whatIf(&lang.Dependency{
+		TypeKind: lang.DependencyObject.GetTypeKind(),
+		Metadata: lang.Metadata{Namespace: "main"},
+		Contract: "contract",
+		TTL:      "forever",
+	})
+	assert.False(t, result.Resolved, "Dependency with invalid TTL shouldn't be resolved")
+	if assert.Len(t, result.Messages, 1, "Rejection message should be returned") {
+		assert.Contains(t, result.Messages[0], "Dependency rejected", "Rejection message should be returned")
+	}
+
+	// dependency on contract, which doesn't exist, so policy validation fails
+	result = whatIf(&lang.Dependency{
+		TypeKind: lang.DependencyObject.GetTypeKind(),
+		Metadata: lang.Metadata{Namespace: "main"},
+		Contract: "missing-contract",
+	})
+	assert.False(t, result.Resolved, "Dependency failing policy validation shouldn't be resolved")
+	if assert.Len(t, result.Messages, 1, "Rejection message should be returned") {
+		assert.Contains(t, result.Messages[0], "Dependency rejected", "Rejection message should be returned")
+	}
+}
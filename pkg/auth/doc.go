@@ -0,0 +1,5 @@
+// Package auth implements server-side objects for API authentication: long-lived API tokens, which allow service
+// accounts (e.g. CI pipelines) to call Aptomi API without user passwords, and revocation list for user tokens.
+// API tokens are stored in the object store in hashed form only, they act on behalf of the user who created them
+// and can be further restricted by scopes and namespaces.
+package auth
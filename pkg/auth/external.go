@@ -0,0 +1,214 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/external/users"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/dgrijalva/jwt-go"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// jwksRefreshInterval is a minimal interval between fetching keys from JWKS URL, when token is signed with unknown key
+const jwksRefreshInterval = time.Minute
+
+// externalNameSeparator separates identity provider alias from the user name
+const externalNameSeparator = ":"
+
+// ExternalVerifier verifies tokens issued by external identity provider (e.g. OIDC) and maps their claims to users
+type ExternalVerifier struct {
+	cfg config.ExternalAuth
+
+	keysMutex   sync.Mutex
+	keys        map[string]interface{}
+	keysFetched time.Time
+}
+
+// NewExternalVerifier creates a new ExternalVerifier for a given identity provider. If keys are configured via local
+// file, they get loaded immediately
+func NewExternalVerifier(cfg config.ExternalAuth) (*ExternalVerifier, error) {
+	if len(cfg.JWKSFile) == 0 && len(cfg.JWKSURL) == 0 {
+		return nil, fmt.Errorf("either JWKS file or JWKS URL should be specified for issuer '%s'", cfg.Issuer)
+	}
+	if len(cfg.Alias) == 0 || strings.Contains(cfg.Alias, externalNameSeparator) {
+		return nil, fmt.Errorf("alias for issuer '%s' should be non-empty and shouldn't contain '%s'", cfg.Issuer, externalNameSeparator)
+	}
+	if strings.EqualFold(cfg.Alias+externalNameSeparator, users.ReservedNamePrefix) {
+		return nil, fmt.Errorf("alias '%s' for issuer '%s' is reserved", cfg.Alias, cfg.Issuer)
+	}
+	if len(cfg.NameClaim) == 0 {
+		cfg.NameClaim = "sub"
+	}
+	if len(cfg.GroupsClaim) == 0 {
+		cfg.GroupsClaim = "groups"
+	}
+
+	verifier := &ExternalVerifier{cfg: cfg}
+	if len(cfg.JWKSFile) > 0 {
+		data, err := ioutil.ReadFile(cfg.JWKSFile)
+		if err != nil {
+			return nil, fmt.Errorf("error while reading JWKS file for issuer '%s': %s", cfg.Issuer, err)
+		}
+		verifier.keys, err = parseJWKS(data)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return verifier, nil
+}
+
+// GetTokenIssuer returns issuer of a given token without verifying it, so the right verifier can be picked
+func GetTokenIssuer(tokenString string) string {
+	parts := strings.Split(tokenString, ".")
+	if len(parts) != 3 {
+		return ""
+	}
+	data, err := jwt.DecodeSegment(parts[1])
+	if err != nil {
+		return ""
+	}
+	claims := make(map[string]interface{})
+	if json.Unmarshal(data, &claims) != nil {
+		return ""
+	}
+	issuer, _ := claims["iss"].(string)
+	return issuer
+}
+
+// Issuer returns issuer, which verifier accepts tokens from
+func (verifier *ExternalVerifier) Issuer() string {
+	return verifier.cfg.Issuer
+}
+
+// Alias returns alias of the issuer, which is used as a prefix of user names
+func (verifier *ExternalVerifier) Alias() string {
+	return verifier.cfg.Alias
+}
+
+// Verify verifies a given token and returns user, which is built from the token claims
+func (verifier *ExternalVerifier) Verify(tokenString string) (*lang.User, error) {
+	token, err := jwt.Parse(tokenString, verifier.getKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// expiration, not before and issued at claims are only verified by parser if they are present, but tokens
+	// without expiration are not accepted
+	claims := token.Claims.(jwt.MapClaims)
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return nil, fmt.Errorf("token should contain 'exp' claim and should not be expired")
+	}
+	if !claims.VerifyIssuer(verifier.cfg.Issuer, true) {
+		return nil, fmt.Errorf("token issuer doesn't match '%s'", verifier.cfg.Issuer)
+	}
+	if !util.ContainsString(claimStrings(claims["aud"]), verifier.cfg.Audience) {
+		return nil, fmt.Errorf("token audience doesn't match '%s'", verifier.cfg.Audience)
+	}
+
+	return verifier.userFromClaims(claims)
+}
+
+// userFromClaims maps token claims to user name and labels. User name is prefixed with the issuer alias, so users
+// from different identity providers and the user directory can't impersonate each other
+func (verifier *ExternalVerifier) userFromClaims(claims jwt.MapClaims) (*lang.User, error) {
+	name, _ := claims[verifier.cfg.NameClaim].(string)
+	if len(name) == 0 {
+		return nil, fmt.Errorf("token should contain non-empty '%s' claim", verifier.cfg.NameClaim)
+	}
+
+	labels := make(map[string]string)
+	for label, claim := range verifier.cfg.LabelClaims {
+		if values := claimStrings(claims[claim]); len(values) > 0 {
+			labels[label] = strings.Join(values, ",")
+		}
+	}
+	for _, group := range claimStrings(claims[verifier.cfg.GroupsClaim]) {
+		if label, ok := verifier.cfg.GroupLabels[group]; ok {
+			labels[label] = "true"
+		}
+	}
+
+	return &lang.User{Name: verifier.cfg.Alias + externalNameSeparator + name, Labels: labels}, nil
+}
+
+// getKey returns issuer key, which token is signed with. If keys are fetched from URL and key is not found, keys
+// will be re-fetched (but not more often than once per jwksRefreshInterval)
+func (verifier *ExternalVerifier) getKey(token *jwt.Token) (interface{}, error) {
+	keyID, _ := token.Header["kid"].(string)
+
+	verifier.keysMutex.Lock()
+	defer verifier.keysMutex.Unlock()
+
+	key, ok := verifier.keys[keyID]
+	if !ok && len(verifier.cfg.JWKSURL) > 0 && time.Since(verifier.keysFetched) >= jwksRefreshInterval {
+		keys, err := fetchJWKS(verifier.cfg.JWKSURL)
+		if err != nil {
+			return nil, err
+		}
+		verifier.keys = keys
+		verifier.keysFetched = time.Now()
+		key, ok = verifier.keys[keyID]
+	}
+	if !ok {
+		return nil, fmt.Errorf("token is signed with unknown key: %s", keyID)
+	}
+
+	switch key.(type) {
+	case *rsa.PublicKey:
+		if _, isRSA := token.Method.(*jwt.SigningMethodRSA); !isRSA {
+			return nil, fmt.Errorf("unexpected token signing method: %s", token.Header["alg"])
+		}
+	case *ecdsa.PublicKey:
+		if _, isECDSA := token.Method.(*jwt.SigningMethodECDSA); !isECDSA {
+			return nil, fmt.Errorf("unexpected token signing method: %s", token.Header["alg"])
+		}
+	}
+
+	return key, nil
+}
+
+func fetchJWKS(url string) (map[string]interface{}, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("error while fetching JWKS from %s: %s", url, err)
+	}
+	defer resp.Body.Close() // nolint: errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error while fetching JWKS from %s: %s", url, resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading JWKS from %s: %s", url, err)
+	}
+
+	return parseJWKS(data)
+}
+
+// claimStrings returns claim value as a list of strings. Claim can be either a string or a list
+func claimStrings(claim interface{}) []string {
+	switch value := claim.(type) {
+	case string:
+		return []string{value}
+	case []interface{}:
+		result := []string{}
+		for _, item := range value {
+			result = append(result, fmt.Sprintf("%v", item))
+		}
+		return result
+	case nil:
+		return nil
+	}
+	return []string{fmt.Sprintf("%v", claim)}
+}
@@ -0,0 +1,126 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/dgrijalva/jwt-go"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestExternalVerifier(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if !assert.NoError(t, err, "RSA key should be generated") {
+		return
+	}
+
+	dir, err := ioutil.TempDir("", "aptomi-jwks")
+	if !assert.NoError(t, err, "Temp dir should be created") {
+		return
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	jwksFile := filepath.Join(dir, "jwks.json")
+	err = ioutil.WriteFile(jwksFile, []byte(fmt.Sprintf(
+		`{"keys": [{"kty": "RSA", "kid": "key1", "use": "sig", "n": "%s", "e": "%s"}]}`,
+		base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	)), 0644)
+	if !assert.NoError(t, err, "JWKS file should be written") {
+		return
+	}
+
+	verifier, err := NewExternalVerifier(config.ExternalAuth{
+		Issuer:      "https://idp.example.com",
+		Alias:       "idp",
+		Audience:    "aptomi",
+		JWKSFile:    jwksFile,
+		LabelClaims: map[string]string{"email": "email", "team": "teams"},
+		GroupLabels: map[string]string{"ops": "is_operator"},
+	})
+	if !assert.NoError(t, err, "Verifier should be created") {
+		return
+	}
+	assert.Equal(t, "https://idp.example.com", verifier.Issuer())
+
+	sign := func(kid string, claims jwt.MapClaims) string {
+		token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+		token.Header["kid"] = kid
+		tokenString, errSign := token.SignedString(key)
+		assert.NoError(t, errSign, "Token should be signed")
+		return tokenString
+	}
+	claims := func(issuer string, audience interface{}) jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss":    issuer,
+			"aud":    audience,
+			"sub":    "alice",
+			"exp":    time.Now().Add(time.Hour).Unix(),
+			"email":  "alice@example.com",
+			"teams":  []interface{}{"red", "blue"},
+			"groups": []interface{}{"ops", "dev"},
+		}
+	}
+
+	// valid token
+	tokenString := sign("key1", claims("https://idp.example.com", []interface{}{"other", "aptomi"}))
+	assert.Equal(t, "https://idp.example.com", GetTokenIssuer(tokenString))
+	user, err := verifier.Verify(tokenString)
+	if assert.NoError(t, err, "Valid token should be accepted") {
+		assert.Equal(t, "idp:alice", user.Name, "User name should be prefixed with issuer alias")
+		assert.Equal(t, map[string]string{
+			"email":       "alice@example.com",
+			"team":        "red,blue",
+			"is_operator": "true",
+		}, user.Labels)
+	}
+
+	// invalid tokens
+	_, err = verifier.Verify(sign("key1", claims("https://idp.example.com", "someone-else")))
+	assert.Error(t, err, "Token with wrong audience should be rejected")
+
+	_, err = verifier.Verify(sign("key1", claims("https://other.example.com", "aptomi")))
+	assert.Error(t, err, "Token with wrong issuer should be rejected")
+
+	_, err = verifier.Verify(sign("key2", claims("https://idp.example.com", "aptomi")))
+	assert.Error(t, err, "Token signed with unknown key should be rejected")
+
+	expired := claims("https://idp.example.com", "aptomi")
+	expired["exp"] = time.Now().Add(-time.Hour).Unix()
+	_, err = verifier.Verify(sign("key1", expired))
+	assert.Error(t, err, "Expired token should be rejected")
+
+	withoutExpiration := claims("https://idp.example.com", "aptomi")
+	delete(withoutExpiration, "exp")
+	_, err = verifier.Verify(sign("key1", withoutExpiration))
+	assert.Error(t, err, "Token without expiration should be rejected")
+
+	notYetValid := claims("https://idp.example.com", "aptomi")
+	notYetValid["nbf"] = time.Now().Add(time.Hour).Unix()
+	_, err = verifier.Verify(sign("key1", notYetValid))
+	assert.Error(t, err, "Token which is not valid yet should be rejected")
+
+	hmacToken, _ := jwt.NewWithClaims(jwt.SigningMethodHS256, claims("https://idp.example.com", "aptomi")).SignedString([]byte("secret"))
+	_, err = verifier.Verify(hmacToken)
+	assert.Error(t, err, "Token signed with unexpected method should be rejected")
+}
+
+func TestExternalVerifierAlias(t *testing.T) {
+	for _, alias := range []string{"", "idp:corp", "system", "SYSTEM"} {
+		_, err := NewExternalVerifier(config.ExternalAuth{
+			Issuer:   "https://idp.example.com",
+			Alias:    alias,
+			Audience: "aptomi",
+			JWKSURL:  "https://idp.example.com/jwks",
+		})
+		assert.Error(t, err, "Verifier with alias '%s' shouldn't be created", alias)
+	}
+}
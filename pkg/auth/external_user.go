@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"strings"
+	"time"
+)
+
+// ExternalUserObject is an informational data structure with Kind and Constructor for ExternalUser
+var ExternalUserObject = &runtime.Info{
+	Kind:        "externaluser",
+	Storable:    true,
+	Constructor: func() runtime.Object { return &ExternalUser{} },
+}
+
+// ExternalUser is a record of the user authenticated by external identity provider. Such users don't exist in the
+// user directory, so they are recorded when they call the API, which allows policy resolution to find them (e.g.
+// when resolving dependencies they declared) with the labels taken from their last token
+type ExternalUser struct {
+	runtime.TypeKind `yaml:",inline"`
+
+	// Name is a name of the user
+	Name string
+
+	// Issuer is an issuer of the token user was authenticated with
+	Issuer string
+
+	// Labels are user labels mapped from the token claims
+	Labels map[string]string `yaml:",omitempty"`
+
+	// UpdatedAt is a time when user record was last updated
+	UpdatedAt time.Time
+}
+
+// GetName returns lower-cased user name, as user names are not case sensitive
+func (user *ExternalUser) GetName() string {
+	return strings.ToLower(user.Name)
+}
+
+// GetNamespace returns namespace of the external user, which is always system namespace
+func (user *ExternalUser) GetNamespace() string {
+	return runtime.SystemNS
+}
+
+// ToUser returns user built from the record
+func (user *ExternalUser) ToUser() *lang.User {
+	return &lang.User{Name: user.Name, Labels: user.Labels}
+}
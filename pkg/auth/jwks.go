@@ -0,0 +1,101 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+)
+
+// jsonWebKeySet represents a set of public keys in JWKS format (RFC 7517)
+type jsonWebKeySet struct {
+	Keys []jsonWebKey `json:"keys"`
+}
+
+// jsonWebKey represents a single public key in JWK format. Only RSA and EC keys are supported
+type jsonWebKey struct {
+	KeyType string `json:"kty"`
+	KeyID   string `json:"kid"`
+	Use     string `json:"use"`
+
+	// RSA key parameters
+	N string `json:"n"`
+	E string `json:"e"`
+
+	// EC key parameters
+	Curve string `json:"crv"`
+	X     string `json:"x"`
+	Y     string `json:"y"`
+}
+
+// parseJWKS parses public keys in JWKS format and returns map of key id -> public key. Keys, which are not intended
+// for signature verification, are skipped
+func parseJWKS(data []byte) (map[string]interface{}, error) {
+	keySet := &jsonWebKeySet{}
+	err := json.Unmarshal(data, keySet)
+	if err != nil {
+		return nil, fmt.Errorf("error while parsing JWKS: %s", err)
+	}
+
+	result := make(map[string]interface{})
+	for _, key := range keySet.Keys {
+		if len(key.Use) > 0 && key.Use != "sig" {
+			continue
+		}
+		publicKey, errKey := key.publicKey()
+		if errKey != nil {
+			return nil, fmt.Errorf("error while parsing key '%s' from JWKS: %s", key.KeyID, errKey)
+		}
+		result[key.KeyID] = publicKey
+	}
+
+	return result, nil
+}
+
+func (key *jsonWebKey) publicKey() (interface{}, error) {
+	switch key.KeyType {
+	case "RSA":
+		n, err := decodeBigInt(key.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(key.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch key.Curve {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve: %s", key.Curve)
+		}
+		x, err := decodeBigInt(key.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(key.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type: %s", key.KeyType)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, fmt.Errorf("error while decoding key parameter: %s", err)
+	}
+	return new(big.Int).SetBytes(data), nil
+}
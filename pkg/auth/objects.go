@@ -0,0 +1,12 @@
+package auth
+
+import "github.com/Aptomi/aptomi/pkg/runtime"
+
+var (
+	// Objects is the list of informational objects for all auth objects
+	Objects = []*runtime.Info{
+		APITokenObject,
+		RevokedTokenObject,
+		ExternalUserObject,
+	}
+)
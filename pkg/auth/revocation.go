@@ -0,0 +1,40 @@
+package auth
+
+import (
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"time"
+)
+
+// RevokedTokenObject is an informational data structure with Kind and Constructor for RevokedToken
+var RevokedTokenObject = &runtime.Info{
+	Kind:        "revokedtoken",
+	Storable:    true,
+	Constructor: func() runtime.Object { return &RevokedToken{} },
+}
+
+// RevokedToken is an entry in the server-side revocation list. It refers to user tokens by their ID (JWT 'jti'), so
+// they can't be used anymore even if they are not expired yet
+type RevokedToken struct {
+	runtime.TypeKind `yaml:",inline"`
+
+	// ID is an ID of the revoked token
+	ID string
+
+	// ExpiresAt is a time when revoked token expires, so the revocation entry is no longer needed after that
+	ExpiresAt time.Time
+}
+
+// GetName returns ID of the revoked token
+func (revoked *RevokedToken) GetName() string {
+	return revoked.ID
+}
+
+// GetNamespace returns namespace of the revoked token, which is always system namespace
+func (revoked *RevokedToken) GetNamespace() string {
+	return runtime.SystemNS
+}
+
+// IsExpired returns true if revoked token is expired at a given time, so the revocation entry can be removed
+func (revoked *RevokedToken) IsExpired(now time.Time) bool {
+	return !now.Before(revoked.ExpiresAt)
+}
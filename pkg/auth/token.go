@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/util"
+	"strings"
+	"time"
+)
+
+// APITokenObject is an informational data structure with Kind and Constructor for APIToken
+var APITokenObject = &runtime.Info{
+	Kind:        "apitoken",
+	Storable:    true,
+	Constructor: func() runtime.Object { return &APIToken{} },
+}
+
+const (
+	// ScopeReadOnly allows token to perform read-only API calls
+	ScopeReadOnly = "read-only"
+
+	// ScopePolicyApply allows token to apply and delete policy objects, in addition to read-only API calls
+	ScopePolicyApply = "policy-apply"
+)
+
+// Scopes is the list of all supported token scopes
+var Scopes = []string{ScopeReadOnly, ScopePolicyApply}
+
+// APITokenPrefix is a prefix for all API token strings, so they can be distinguished from user JWT tokens
+const APITokenPrefix = "aptomi"
+
+// apiTokenSeparator separates prefix, token name and token secret in the token string
+const apiTokenSeparator = ":"
+
+// APIToken is a long-lived API token for service accounts. Token acts on behalf of its owner (i.e. owner's ACL
+// privileges apply), but it can be further restricted by scopes and namespaces
+type APIToken struct {
+	runtime.TypeKind `yaml:",inline"`
+
+	// Name is a unique name of the token
+	Name string
+
+	// Owner is a name of the user who created the token and on behalf of which the token acts
+	Owner string
+
+	// Scopes is a list of scopes, which define what API calls are allowed for the token
+	Scopes []string
+
+	// Namespaces, if not empty, restricts policy changes and reads of namespaced data (policy objects, dependencies,
+	// endpoints) made with the token to a given set of namespaces
+	Namespaces []string `yaml:",omitempty"`
+
+	// Hash is a hash of the token secret. Token secret itself is never stored
+	Hash string `yaml:",omitempty"`
+
+	// CreatedAt is a time when token was created
+	CreatedAt time.Time
+
+	// ExpiresAt is a time when token expires. Zero time means that token never expires
+	ExpiresAt time.Time `yaml:",omitempty"`
+
+	// LastUsedAt is a time when token was last used to call the API
+	LastUsedAt time.Time `yaml:",omitempty"`
+}
+
+// GetName returns token name
+func (token *APIToken) GetName() string {
+	return token.Name
+}
+
+// GetNamespace returns token namespace, which is always system namespace
+func (token *APIToken) GetNamespace() string {
+	return runtime.SystemNS
+}
+
+// IsExpired returns true if token is expired at a given time
+func (token *APIToken) IsExpired(now time.Time) bool {
+	return !token.ExpiresAt.IsZero() && !now.Before(token.ExpiresAt)
+}
+
+// HasScope returns true if token has a given scope
+func (token *APIToken) HasScope(scope string) bool {
+	return util.ContainsString(token.Scopes, scope)
+}
+
+// AllowsScope returns true if token has a given scope or a scope which includes it (policy-apply scope includes
+// read-only scope). Empty scope is never allowed, so API calls which don't declare a scope can't be made with tokens
+func (token *APIToken) AllowsScope(scope string) bool {
+	if len(scope) == 0 {
+		return false
+	}
+	return token.HasScope(scope) || scope == ScopeReadOnly && token.HasScope(ScopePolicyApply)
+}
+
+// AllowsNamespace returns true if token allows access to a given namespace
+func (token *APIToken) AllowsNamespace(namespace string) bool {
+	return len(token.Namespaces) == 0 || util.ContainsString(token.Namespaces, namespace)
+}
+
+// MatchesSecret returns true if a given secret matches the token hash
+func (token *APIToken) MatchesSecret(secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(token.Hash), []byte(HashSecret(secret))) == 1
+}
+
+// Validate checks that token has a valid name and scopes
+func (token *APIToken) Validate() error {
+	if len(token.Name) == 0 || strings.Contains(token.Name, apiTokenSeparator) || strings.Contains(token.Name, runtime.KeySeparator) {
+		return fmt.Errorf("token name must be non-empty and must not contain '%s' or '%s'", apiTokenSeparator, runtime.KeySeparator)
+	}
+	if len(token.Scopes) == 0 {
+		return fmt.Errorf("token must have at least one scope from %s", Scopes)
+	}
+	for _, scope := range token.Scopes {
+		if !util.ContainsString(Scopes, scope) {
+			return fmt.Errorf("token scope must be in %s, but found '%s'", Scopes, scope)
+		}
+	}
+	return nil
+}
+
+// GenerateSecret generates a new random token secret
+func GenerateSecret() (string, error) {
+	data := make([]byte, 32)
+	_, err := rand.Read(data)
+	if err != nil {
+		return "", fmt.Errorf("error while generating token secret: %s", err)
+	}
+	return hex.EncodeToString(data), nil
+}
+
+// HashSecret returns a hash of a given token secret
+func HashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+// FormatTokenString returns token string, which should be passed to the API by the client
+func FormatTokenString(name string, secret string) string {
+	return strings.Join([]string{APITokenPrefix, name, secret}, apiTokenSeparator)
+}
+
+// ParseTokenString parses token string into token name and token secret. It returns false if a given string is not
+// an API token string
+func ParseTokenString(tokenString string) (name string, secret string, ok bool) {
+	parts := strings.Split(tokenString, apiTokenSeparator)
+	if len(parts) != 3 || parts[0] != APITokenPrefix || len(parts[1]) == 0 || len(parts[2]) == 0 {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
+
+// GetDefaultColumns returns default set of columns to be displayed
+func (token *APIToken) GetDefaultColumns() []string {
+	return []string{"Name", "Owner", "Scopes", "Namespaces", "Expires At", "Last Used At"}
+}
+
+// AsColumns returns APIToken representation as columns
+func (token *APIToken) AsColumns() map[string]string {
+	return map[string]string{
+		"Name":         token.Name,
+		"Owner":        token.Owner,
+		"Scopes":       strings.Join(token.Scopes, ", "),
+		"Namespaces":   strings.Join(token.Namespaces, ", "),
+		"Expires At":   formatTime(token.ExpiresAt, "never"),
+		"Last Used At": formatTime(token.LastUsedAt, "never"),
+	}
+}
+
+func formatTime(t time.Time, zero string) string {
+	if t.IsZero() {
+		return zero
+	}
+	return t.Format(time.RFC3339)
+}
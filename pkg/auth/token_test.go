@@ -0,0 +1,66 @@
+package auth
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestTokenString(t *testing.T) {
+	secret, err := GenerateSecret()
+	if !assert.NoError(t, err, "Token secret should be generated") {
+		return
+	}
+
+	name, parsedSecret, ok := ParseTokenString(FormatTokenString("ci", secret))
+	assert.True(t, ok, "Token string should be parsed")
+	assert.Equal(t, "ci", name, "Token name should be parsed correctly")
+	assert.Equal(t, secret, parsedSecret, "Token secret should be parsed correctly")
+
+	for _, tokenString := range []string{"", "aptomi:ci", "aptomi::secret", "other:ci:secret", "header.payload.signature"} {
+		_, _, ok = ParseTokenString(tokenString)
+		assert.False(t, ok, "Token string should not be parsed: %s", tokenString)
+	}
+
+	token := &APIToken{Name: "ci", Hash: HashSecret(secret)}
+	assert.True(t, token.MatchesSecret(secret), "Token should match its secret")
+	assert.False(t, token.MatchesSecret(secret+"x"), "Token should not match other secret")
+}
+
+func TestTokenRestrictions(t *testing.T) {
+	now := time.Now()
+	token := &APIToken{
+		Name:       "ci",
+		Scopes:     []string{ScopeReadOnly},
+		Namespaces: []string{"main"},
+		ExpiresAt:  now.Add(time.Hour),
+	}
+
+	assert.NoError(t, token.Validate(), "Token should be valid")
+	assert.True(t, token.HasScope(ScopeReadOnly), "Token should have read-only scope")
+	assert.False(t, token.HasScope(ScopePolicyApply), "Token should not have policy-apply scope")
+	assert.True(t, token.AllowsScope(ScopeReadOnly), "Token should allow read-only API calls")
+	assert.False(t, token.AllowsScope(ScopePolicyApply), "Token should not allow policy-apply API calls")
+	assert.False(t, token.AllowsScope(""), "Token should not allow API calls without scope")
+	assert.True(t, token.AllowsNamespace("main"), "Token should allow namespace")
+	assert.False(t, token.AllowsNamespace("other"), "Token should not allow namespace")
+	assert.False(t, token.IsExpired(now), "Token should not be expired")
+	assert.True(t, token.IsExpired(now.Add(2*time.Hour)), "Token should be expired")
+
+	token.Scopes = []string{ScopePolicyApply}
+	assert.True(t, token.AllowsScope(ScopeReadOnly), "Policy-apply scope should include read-only scope")
+
+	token.Namespaces = nil
+	token.ExpiresAt = time.Time{}
+	assert.True(t, token.AllowsNamespace("other"), "Token without namespaces should allow all namespaces")
+	assert.False(t, token.IsExpired(now.Add(100*365*24*time.Hour)), "Token without expiration should never expire")
+
+	for _, invalid := range []*APIToken{
+		{Name: "", Scopes: []string{ScopeReadOnly}},
+		{Name: "a:b", Scopes: []string{ScopeReadOnly}},
+		{Name: "ci"},
+		{Name: "ci", Scopes: []string{"unknown"}},
+	} {
+		assert.Error(t, invalid.Validate(), "Token should be invalid: %v", invalid)
+	}
+}
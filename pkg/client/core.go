@@ -3,6 +3,7 @@ package client
 import (
 	"github.com/Aptomi/aptomi/pkg/api"
 	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/version"
 )
@@ -14,7 +15,9 @@ type Core interface {
 	Revision() Revision
 	State() State
 	User() User
+	Token() Token
 	Version() Version
+	Backup() Backup
 }
 
 // Policy is the interface for managing Policy
@@ -22,11 +25,14 @@ type Policy interface {
 	Show(gen runtime.Generation) (*engine.PolicyData, error)
 	Apply([]runtime.Object) (*api.PolicyUpdateResult, error)
 	Delete([]runtime.Object) (*api.PolicyUpdateResult, error)
+	Dependencies() (*api.Dependencies, error)
+	Lint(gen runtime.Generation) (*api.PolicyLintResult, error)
+	WhatIf(dependency *lang.Dependency) (*api.PolicyWhatIfResult, error)
 }
 
 // Endpoints is the interface for getting info about endpoints
 type Endpoints interface {
-	Show() (*api.Endpoints, error)
+	Show(filter *api.EndpointsFilter) (*api.Endpoints, error)
 }
 
 // Revision is the interface for getting Revisions
@@ -35,17 +41,34 @@ type Revision interface {
 	ShowByPolicy(policyGen runtime.Generation) (*engine.Revision, error)
 }
 
-// State is the interface for resetting Actual State
+// State is the interface for resetting Actual State and triggering policy enforcement
 type State interface {
 	Reset() (*engine.Revision, error)
+	Enforce() (*engine.Revision, error)
 }
 
 // User is the interface for auth and user management
 type User interface {
 	Login(username, password string) (*api.AuthSuccess, error)
+	Refresh(refreshToken string) (*api.AuthSuccess, error)
+	Logout() error
+	Directory() (*api.UserDirectory, error)
+	RefreshDirectory() (*api.UserDirectory, error)
+}
+
+// Token is the interface for managing long-lived API tokens
+type Token interface {
+	Create(request *api.APITokenRequest) (*api.APITokenCreated, error)
+	List() (*api.APITokens, error)
+	Revoke(name string) (*api.APITokens, error)
 }
 
 // Version is the interface for getting current server version
 type Version interface {
 	Show() (*version.BuildInfo, error)
 }
+
+// Backup is the interface for downloading backup of all objects stored by the server
+type Backup interface {
+	Download() ([]byte, error)
+}
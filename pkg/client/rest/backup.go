@@ -0,0 +1,15 @@
+package rest
+
+import (
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+)
+
+type backupClient struct {
+	cfg        *config.Client
+	httpClient http.Client
+}
+
+func (client *backupClient) Download() ([]byte, error) {
+	return client.httpClient.GETRaw("/backup")
+}
@@ -36,6 +36,14 @@ func (client *coreClient) User() client.User {
 	return &userClient{client.cfg, client.httpClient}
 }
 
+func (client *coreClient) Token() client.Token {
+	return &tokenClient{client.cfg, client.httpClient}
+}
+
 func (client *coreClient) Version() client.Version {
 	return &versionClient{client.cfg, client.httpClient}
 }
+
+func (client *coreClient) Backup() client.Backup {
+	return &backupClient{client.cfg, client.httpClient}
+}
@@ -13,8 +13,12 @@ type endpointsClient struct {
 	httpClient http.Client
 }
 
-func (client *endpointsClient) Show() (*api.Endpoints, error) {
-	response, err := client.httpClient.GET("/endpoints", api.EndpointsObject)
+func (client *endpointsClient) Show(filter *api.EndpointsFilter) (*api.Endpoints, error) {
+	path := "/endpoints"
+	if query := filter.AsQuery().Encode(); len(query) > 0 {
+		path += "?" + query
+	}
+	response, err := client.httpClient.GET(path, api.EndpointsObject)
 	if err != nil {
 		return nil, err
 	}
@@ -15,6 +15,7 @@ import (
 // Client is the interface for doing HTTP requests that operates using runtime objects
 type Client interface {
 	GET(path string, expected *runtime.Info) (runtime.Object, error)
+	GETRaw(path string) ([]byte, error)
 	POST(path string, expected *runtime.Info, body runtime.Object) (runtime.Object, error)
 	POSTSlice(path string, expected *runtime.Info, body []runtime.Object) (runtime.Object, error)
 	DELETE(path string, expected *runtime.Info) (runtime.Object, error)
@@ -23,6 +24,7 @@ type Client interface {
 
 type httpClient struct {
 	contentType *codec.ContentTypeHandler
+	mediaType   string
 	http        *http.Client
 	cfg         *config.Client
 }
@@ -33,19 +35,41 @@ func NewClient(cfg *config.Client) Client {
 		Timeout: cfg.HTTP.Timeout,
 	}
 	contentTypeHandler := codec.NewContentTypeHandler(runtime.NewRegistry().Append(api.Objects...))
+	mediaType := codec.Default
+	if cfg.HTTP.ContentType == "json" {
+		mediaType = codec.JSON
+	}
 
-	return &httpClient{contentTypeHandler, client, cfg}
+	return &httpClient{contentTypeHandler, mediaType, client, cfg}
 }
 
 func (client *httpClient) GET(path string, expected *runtime.Info) (runtime.Object, error) {
 	return client.request(http.MethodGet, path, expected, nil)
 }
 
+// GETRaw returns raw response body for successful requests, which is used for responses that aren't runtime objects
+func (client *httpClient) GETRaw(path string) ([]byte, error) {
+	resp, respData, err := client.do(http.MethodGet, path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		_, err = client.decode(resp, respData, nil)
+		if err == nil {
+			err = fmt.Errorf("unexpected response status: %s", resp.Status)
+		}
+		return nil, err
+	}
+
+	return respData, nil
+}
+
 func (client *httpClient) POST(path string, expected *runtime.Info, body runtime.Object) (runtime.Object, error) {
 	var bodyData io.Reader
 
 	if body != nil {
-		data, err := client.contentType.GetCodecByContentType(codec.Default).EncodeOne(body)
+		data, err := client.contentType.GetCodecByContentType(client.mediaType).EncodeOne(body)
 		if err != nil {
 			return nil, fmt.Errorf("error while encoding body for post request: %s", err)
 		}
@@ -59,7 +83,7 @@ func (client *httpClient) POSTSlice(path string, expected *runtime.Info, body []
 	var bodyData io.Reader
 
 	if body != nil {
-		data, err := client.contentType.GetCodecByContentType(codec.Default).EncodeMany(body)
+		data, err := client.contentType.GetCodecByContentType(client.mediaType).EncodeMany(body)
 		if err != nil {
 			return nil, fmt.Errorf("error while encoding body for post request: %s", err)
 		}
@@ -77,7 +101,7 @@ func (client *httpClient) DELETESlice(path string, expected *runtime.Info, body
 	var bodyData io.Reader
 
 	if body != nil {
-		data, err := client.contentType.GetCodecByContentType(codec.Default).EncodeMany(body)
+		data, err := client.contentType.GetCodecByContentType(client.mediaType).EncodeMany(body)
 		if err != nil {
 			return nil, fmt.Errorf("error while encoding body for delete request: %s", err)
 		}
@@ -88,28 +112,42 @@ func (client *httpClient) DELETESlice(path string, expected *runtime.Info, body
 }
 
 func (client *httpClient) request(method string, path string, expected *runtime.Info, body io.Reader) (runtime.Object, error) {
-	req, err := http.NewRequest(method, client.cfg.API.URL()+path, body)
+	resp, respData, err := client.do(method, path, body)
 	if err != nil {
 		return nil, err
 	}
 
+	return client.decode(resp, respData, expected)
+}
+
+func (client *httpClient) do(method string, path string, body io.Reader) (*http.Response, []byte, error) {
+	req, err := http.NewRequest(method, client.cfg.API.URL()+path, body)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	if len(client.cfg.Auth.Token) > 0 {
 		req.Header.Set("Authorization", "Bearer "+client.cfg.Auth.Token)
 	}
-	req.Header.Set("Content-Type", codec.Default)
+	req.Header.Set("Content-Type", client.mediaType)
+	req.Header.Set("Accept", client.mediaType)
 	req.Header.Set("User-Agent", "aptomictl")
 
 	resp, err := client.http.Do(req)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	defer resp.Body.Close() // nolint: errcheck
 
 	respData, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return nil, fmt.Errorf("error while reading bytes from response Body: %s", err)
+		return nil, nil, fmt.Errorf("error while reading bytes from response Body: %s", err)
 	}
 
+	return resp, respData, nil
+}
+
+func (client *httpClient) decode(resp *http.Response, respData []byte, expected *runtime.Info) (runtime.Object, error) {
 	if len(respData) == 0 {
 		return nil, fmt.Errorf("empty response")
 	}
@@ -6,6 +6,7 @@ import (
 	"github.com/Aptomi/aptomi/pkg/client/rest/http"
 	"github.com/Aptomi/aptomi/pkg/config"
 	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 )
 
@@ -52,3 +53,42 @@ func (client *policyClient) Delete(updated []runtime.Object) (*api.PolicyUpdateR
 
 	return response.(*api.PolicyUpdateResult), nil
 }
+
+func (client *policyClient) Dependencies() (*api.Dependencies, error) {
+	response, err := client.httpClient.GET("/policy/dependencies", api.DependenciesObject)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverError, ok := response.(*api.ServerError); ok {
+		return nil, fmt.Errorf("server error: %s", serverError.Error)
+	}
+
+	return response.(*api.Dependencies), nil
+}
+
+func (client *policyClient) Lint(gen runtime.Generation) (*api.PolicyLintResult, error) {
+	response, err := client.httpClient.GET(fmt.Sprintf("/policy/lint/gen/%d", gen), api.PolicyLintResultObject)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverError, ok := response.(*api.ServerError); ok {
+		return nil, fmt.Errorf("server error: %s", serverError.Error)
+	}
+
+	return response.(*api.PolicyLintResult), nil
+}
+
+func (client *policyClient) WhatIf(dependency *lang.Dependency) (*api.PolicyWhatIfResult, error) {
+	response, err := client.httpClient.POSTSlice("/policy/whatif", api.PolicyWhatIfResultObject, []runtime.Object{dependency})
+	if err != nil {
+		return nil, err
+	}
+
+	if serverError, ok := response.(*api.ServerError); ok {
+		return nil, fmt.Errorf("server error: %s", serverError.Error)
+	}
+
+	return response.(*api.PolicyWhatIfResult), nil
+}
@@ -19,3 +19,12 @@ func (client *stateClient) Reset() (*engine.Revision, error) {
 
 	return revision.(*engine.Revision), nil
 }
+
+func (client *stateClient) Enforce() (*engine.Revision, error) {
+	revision, err := client.httpClient.POST("/enforcement", engine.RevisionObject, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return revision.(*engine.Revision), nil
+}
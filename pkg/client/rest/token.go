@@ -0,0 +1,53 @@
+package rest
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/api"
+	"github.com/Aptomi/aptomi/pkg/client/rest/http"
+	"github.com/Aptomi/aptomi/pkg/config"
+)
+
+type tokenClient struct {
+	cfg        *config.Client
+	httpClient http.Client
+}
+
+func (client *tokenClient) Create(request *api.APITokenRequest) (*api.APITokenCreated, error) {
+	request.TypeKind = api.APITokenRequestObject.GetTypeKind()
+	response, err := client.httpClient.POST("/token", api.APITokenCreatedObject, request)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverError, ok := response.(*api.ServerError); ok {
+		return nil, fmt.Errorf("server error: %s", serverError.Error)
+	}
+
+	return response.(*api.APITokenCreated), nil
+}
+
+func (client *tokenClient) List() (*api.APITokens, error) {
+	response, err := client.httpClient.GET("/tokens", api.APITokensObject)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverError, ok := response.(*api.ServerError); ok {
+		return nil, fmt.Errorf("server error: %s", serverError.Error)
+	}
+
+	return response.(*api.APITokens), nil
+}
+
+func (client *tokenClient) Revoke(name string) (*api.APITokens, error) {
+	response, err := client.httpClient.DELETE("/token/"+name, api.APITokensObject)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverError, ok := response.(*api.ServerError); ok {
+		return nil, fmt.Errorf("server error: %s", serverError.Error)
+	}
+
+	return response.(*api.APITokens), nil
+}
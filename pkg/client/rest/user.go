@@ -1,6 +1,7 @@
 package rest
 
 import (
+	"fmt"
 	"github.com/Aptomi/aptomi/pkg/api"
 	"github.com/Aptomi/aptomi/pkg/client/rest/http"
 	"github.com/Aptomi/aptomi/pkg/config"
@@ -24,3 +25,47 @@ func (client *userClient) Login(username, password string) (*api.AuthSuccess, er
 
 	return authSuccess.(*api.AuthSuccess), nil
 }
+
+func (client *userClient) Refresh(refreshToken string) (*api.AuthSuccess, error) {
+	refreshReq := &api.AuthRefreshRequest{
+		TypeKind:     api.AuthRefreshRequestObject.GetTypeKind(),
+		RefreshToken: refreshToken,
+	}
+	authSuccess, err := client.httpClient.POST("/user/refresh", api.AuthSuccessObject, refreshReq)
+	if err != nil {
+		return nil, err
+	}
+
+	return authSuccess.(*api.AuthSuccess), nil
+}
+
+func (client *userClient) Logout() error {
+	_, err := client.httpClient.POST("/user/logout", api.AuthSuccessObject, nil)
+	return err
+}
+
+func (client *userClient) Directory() (*api.UserDirectory, error) {
+	response, err := client.httpClient.GET("/user/directory", api.UserDirectoryObject)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverError, ok := response.(*api.ServerError); ok {
+		return nil, fmt.Errorf("server error: %s", serverError.Error)
+	}
+
+	return response.(*api.UserDirectory), nil
+}
+
+func (client *userClient) RefreshDirectory() (*api.UserDirectory, error) {
+	response, err := client.httpClient.POST("/user/directory/refresh", api.UserDirectoryObject, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if serverError, ok := response.(*api.ServerError); ok {
+		return nil, fmt.Errorf("server error: %s", serverError.Error)
+	}
+
+	return response.(*api.UserDirectory), nil
+}
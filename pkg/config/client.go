@@ -16,6 +16,9 @@ type Client struct {
 // HTTP is the config for low level HTTP client
 type HTTP struct {
 	Timeout time.Duration `yaml:",omitempty" `
+
+	// ContentType is a format used for requests to the server and its responses, one of: yaml (default) or json
+	ContentType string `yaml:"contenttype,omitempty" validate:"omitempty,oneof=yaml json"`
 }
 
 // IsDebug returns true if debug mode enabled
@@ -25,5 +28,6 @@ func (c Client) IsDebug() bool {
 
 // ClientAuth represents client auth configs
 type ClientAuth struct {
-	Token string `yaml:",omitempty" validate:"-"`
+	Token        string `yaml:",omitempty" validate:"-"`
+	RefreshToken string `yaml:"refreshtoken,omitempty" validate:"-"`
 }
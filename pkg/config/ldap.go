@@ -17,6 +17,38 @@ type LDAP struct {
 	FilterByName string `validate:"required"`
 
 	LabelToAttributes map[string]string `validate:"required"`
+
+	// Groups is an optional config for retrieving user group membership and mapping it to user labels
+	Groups *LDAPGroups `validate:"omitempty"`
+}
+
+// LDAPGroups represents configs for retrieving user group membership from LDAP. Groups can be retrieved either from
+// the user attribute (e.g. 'memberOf'), or by a separate group query
+type LDAPGroups struct {
+	// MemberOfAttribute is an attribute, which contains DNs of the groups an entry belongs to (e.g. 'memberOf'). Group
+	// name is taken from the first RDN of group DN. If it's not specified, groups are retrieved by a group query
+	MemberOfAttribute string `validate:"-"`
+
+	// BaseDN is a base DN for the group query (LDAP base DN, if not specified)
+	BaseDN string `validate:"-"`
+
+	// Filter is LDAP filter query for the groups, which a given member belongs to. Member DN is substituted
+	// instead of %s, e.g. '(&(objectClass=groupOfNames)(member=%s))'
+	Filter string `validate:"-"`
+
+	// NameAttribute is a group attribute, which is used as a group name for the group query ('cn', if not specified)
+	NameAttribute string `validate:"-"`
+
+	// Nested enables retrieval of nested groups, i.e. if user belongs to a group, which belongs to another
+	// group, user will get both groups
+	Nested bool `validate:"-"`
+
+	// Label is a name of the label, which will be set to the sorted comma-separated list of user groups
+	Label string `validate:"-"`
+
+	// LabelPrefix, if specified, enables setting one label per user group. Label name will be prefix followed by
+	// the group name, and its value will be 'true'
+	LabelPrefix string `validate:"-"`
 }
 
 // GetAttributes returns the list of attributes to be retrieved from LDAP
@@ -25,6 +57,9 @@ func (cfg *LDAP) GetAttributes() []string {
 	for _, attr := range cfg.LabelToAttributes {
 		result = append(result, attr)
 	}
+	if cfg.Groups != nil && len(cfg.Groups.MemberOfAttribute) > 0 {
+		result = append(result, cfg.Groups.MemberOfAttribute)
+	}
 	sort.Strings(result)
 	return result
 }
@@ -16,3 +16,16 @@ func TestConfigLDAP(t *testing.T) {
 	attrNames := config.GetAttributes()
 	assert.Equal(t, []string{"ldap_attr_1", "ldap_attr_2", "ldap_attr_3"}, attrNames, "The list of attributes to be retrieved from LDAP must be correct")
 }
+
+func TestConfigLDAPMemberOf(t *testing.T) {
+	config := &LDAP{
+		LabelToAttributes: map[string]string{
+			"aptomi_label_1": "ldap_attr_1",
+		},
+		Groups: &LDAPGroups{
+			MemberOfAttribute: "memberOf",
+		},
+	}
+	attrNames := config.GetAttributes()
+	assert.Equal(t, []string{"ldap_attr_1", "memberOf"}, attrNames, "The list of attributes to be retrieved from LDAP must include group membership attribute")
+}
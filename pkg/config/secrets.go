@@ -0,0 +1,59 @@
+package config
+
+// SecretSources represents configs for the secret loaders. Secrets from all sources get merged, with later sources
+// overriding secrets from the earlier ones in the following order: Dir, EncryptedFile, Vault, Env
+type SecretSources struct {
+	// Dir is a list of directories with plaintext secrets*.yaml files
+	Dir []string `validate:"dive,dir"`
+
+	// EncryptedFile is a list of files with encrypted secrets
+	EncryptedFile []EncryptedSecrets `validate:"dive"`
+
+	// Vault is a list of Vault-compatible HTTP secret stores
+	Vault []VaultSecrets `validate:"dive"`
+
+	// Env enables loading secrets from environment variables
+	Env *EnvSecrets `validate:"omitempty"`
+}
+
+// EncryptedSecrets represents config for loading secrets from the encrypted file
+type EncryptedSecrets struct {
+	// File is a path to the encrypted file with secrets
+	File string `validate:"required,file"`
+
+	// Keys is a list of base64-encoded 256-bit keys, which are tried in order to decrypt the file. Having more than
+	// one key allows to rotate keys without downtime
+	Keys []string `validate:"min=1"`
+}
+
+// VaultSecrets represents config for loading secrets from Vault-compatible HTTP secret store. User secrets are read
+// from '<path>/users/<user>', namespace secrets from '<path>/namespaces/<namespace>' and service secrets from
+// '<path>/services/<namespace>/<service>'
+type VaultSecrets struct {
+	// Address is an address of the secret store, e.g. 'https://vault.example.com:8200'
+	Address string `validate:"required,url"`
+
+	// Token is a token used to access secret store
+	Token string `validate:"-"`
+
+	// TokenFile is a path to the file with a token, it's used if token is not specified
+	TokenFile string `validate:"omitempty,file"`
+
+	// Mount is a mount path of the key/value secrets engine ('secret', if not specified)
+	Mount string `validate:"-"`
+
+	// Path is a path under the mount, where Aptomi secrets are stored ('aptomi', if not specified)
+	Path string `validate:"-"`
+
+	// KVVersion is a version of the key/value secrets engine (1 or 2, 1 if not specified)
+	KVVersion int `validate:"omitempty,min=1,max=2"`
+}
+
+// EnvSecrets represents config for loading secrets from environment variables. Secrets are read as JSON objects
+// from '<prefix>USER_<user>', '<prefix>NAMESPACE_<namespace>' and '<prefix>SERVICE_<namespace>__<service>' variables,
+// where names are converted to upper case and '-' is replaced with '_'. Names containing characters other than
+// letters, digits and single '-' between them can't be converted unambiguously, so their secrets can't be loaded
+type EnvSecrets struct {
+	// Prefix is a prefix of environment variables ('APTOMI_SECRETS_', if not specified)
+	Prefix string `validate:"-"`
+}
@@ -11,15 +11,19 @@ type Server struct {
 	Plugins              Plugins         `validate:"required"`
 	Users                UserSources     `validate:"required"`
 	SecretsDir           string          `validate:"omitempty,dir"` // secrets is not a first-class citizen yet, so it's not required
+	Secrets              SecretSources   `validate:"omitempty"`
 	Enforcer             Enforcer        `validate:"required"`
 	DomainAdminOverrides map[string]bool `validate:"-"`
-	Auth                 ServerAuth      `validate:"-"`
+	Auth                 ServerAuth      `validate:"omitempty"`
 }
 
 // UserSources represents configs for the user loaders that could be file and LDAP loaders
 type UserSources struct {
 	LDAP []LDAP   `validate:"dive"`
 	File []string `validate:"dive,file"`
+
+	// CacheTTL is a period after which cached users get refreshed in background
+	CacheTTL time.Duration `validate:"-"`
 }
 
 // IsDebug returns true if debug mode enabled
@@ -29,7 +33,51 @@ func (s Server) IsDebug() bool {
 
 // DB represents configs for DB
 type DB struct {
+	// Type is a type of the object store to use: "bolt" (default), "sqlite3" or "memory". If it isn't specified and
+	// connection is "memory://", in-memory store is used
+	Type       string
 	Connection string `validate:"required"`
+
+	// Retention defines which old generations of policy and revisions are kept in DB
+	Retention Retention `validate:"-"`
+
+	// Encryption defines encryption of objects stored in DB
+	Encryption Encryption `validate:"-"`
+
+	// BackupDir is a directory for backups made automatically before migrating DB to the new schema version. If it
+	// isn't set, directory of the DB file is used. It's required to migrate DB, which connection isn't a file path
+	// (e.g. SQL DSN)
+	BackupDir string `validate:"-"`
+}
+
+// Encryption represents configs for encryption of objects stored in DB. Every object is encrypted with its own data
+// key, which is encrypted with the current key from the key file. Other keys from the key file are used to decrypt
+// objects encrypted before key rotation
+type Encryption struct {
+	// KeyFile is a path to the file with encryption keys, objects are stored unencrypted if it isn't set
+	KeyFile string
+}
+
+// Retention represents configs for removing old generations of policy and revisions from DB. Generation is kept if
+// it's one of the last N generations or if it's younger than MaxAge. Policy generations referenced by kept revisions
+// are always kept. If neither N nor MaxAge is set for policy or revisions, all their generations are kept
+type Retention struct {
+	// PolicyGenerations is a number of last policy generations to keep
+	PolicyGenerations int
+
+	// Revisions is a number of last revisions to keep
+	Revisions int
+
+	// MaxAge is a period during which all policy generations and revisions are kept
+	MaxAge time.Duration
+
+	// Interval is a period between background compactions. If not set, compaction runs every hour
+	Interval time.Duration
+}
+
+// IsEnabled returns true if any retention limit is configured and old generations should be removed
+func (r Retention) IsEnabled() bool {
+	return r.PolicyGenerations > 0 || r.Revisions > 0 || r.MaxAge > 0
 }
 
 // Enforcer represents configs for Enforcer background process that periodically gets latest policy, calculating
@@ -43,5 +91,57 @@ type Enforcer struct {
 
 // ServerAuth represents server auth config
 type ServerAuth struct {
+	// Secret is the current secret used to sign user tokens
 	Secret string `validate:"-"`
+
+	// KeyID identifies the current secret. It's put into the header of every issued token ('kid'), so the
+	// corresponding secret can be found when token gets verified
+	KeyID string `validate:"-"`
+
+	// PreviousSecrets is a map of key id -> secret, which are no longer used to sign new tokens, but still accepted
+	// for verifying already issued ones. It allows to rotate secrets gradually. Secret with an empty key id is used
+	// for tokens issued without 'kid' header, before KeyID was configured
+	PreviousSecrets map[string]string `validate:"-"`
+
+	// AccessTokenTTL is a lifetime of access tokens
+	AccessTokenTTL time.Duration `validate:"-"`
+
+	// RefreshTokenTTL is a lifetime of refresh tokens, which allow to get new access tokens without logging in again
+	RefreshTokenTTL time.Duration `validate:"-"`
+
+	// External is a list of external identity providers (e.g. OIDC), whose tokens are accepted in addition to
+	// tokens issued by Aptomi
+	External []ExternalAuth `validate:"dive"`
+}
+
+// ExternalAuth represents config for verifying tokens issued by external identity provider and mapping their claims
+// to Aptomi users
+type ExternalAuth struct {
+	// Issuer is an expected value of 'iss' claim. It's used to pick identity provider for a given token
+	Issuer string `validate:"required"`
+
+	// Alias is a prefix of the names of users authenticated by identity provider ('<alias>:<name>'), so they never
+	// collide with users from the user directory or other identity providers. Alias 'system' is reserved
+	Alias string `validate:"required"`
+
+	// Audience is an expected value of 'aud' claim
+	Audience string `validate:"required"`
+
+	// JWKSFile is a path to the local file with issuer keys in JWKS format
+	JWKSFile string `validate:"omitempty,file"`
+
+	// JWKSURL is an URL to fetch issuer keys in JWKS format from, if JWKSFile is not specified
+	JWKSURL string `validate:"omitempty,url"`
+
+	// NameClaim is a claim, which is used as a user name after alias ('sub', if not specified)
+	NameClaim string `validate:"-"`
+
+	// LabelClaims is a map of label name -> claim, which is used to set user labels. List claims are joined with ','
+	LabelClaims map[string]string `validate:"-"`
+
+	// GroupsClaim is a claim, which contains the list of user groups ('groups', if not specified)
+	GroupsClaim string `validate:"-"`
+
+	// GroupLabels is a map of group -> label name, which is set to 'true' for users in this group
+	GroupLabels map[string]string `validate:"-"`
 }
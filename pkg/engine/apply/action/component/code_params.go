@@ -0,0 +1,20 @@
+package component
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine/apply/action"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/external/secrets"
+	"github.com/Aptomi/aptomi/pkg/util"
+)
+
+// Calculated code params contain placeholders instead of secrets. Code plugins need actual values, so placeholders get
+// replaced right before calling plugin. Revealed values are masked in the event log, which is passed to plugin
+func revealCodeParams(instance *resolve.ComponentInstance, context *action.Context) (util.NestedParameterMap, error) {
+	params, revealed, err := secrets.Reveal(instance.CalculatedCodeParams, context.ExternalData.SecretLoader, instance.GetSecretOwners(context.DesiredPolicy))
+	if err != nil {
+		return nil, fmt.Errorf("error while revealing secrets in code params, component instance: %s: %s", instance.GetKey(), err)
+	}
+	context.EventLog.Redact(revealed...)
+	return params, nil
+}
@@ -84,5 +84,10 @@ func (a *CreateAction) processDeployment(context *action.Context) error {
 		return err
 	}
 
-	return plugin.Create(instance.GetDeployName(), instance.CalculatedCodeParams, context.EventLog)
+	params, err := revealCodeParams(instance, context)
+	if err != nil {
+		return err
+	}
+
+	return plugin.Create(instance.GetDeployName(), params, context.EventLog)
 }
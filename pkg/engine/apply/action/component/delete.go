@@ -84,5 +84,10 @@ func (a *DeleteAction) processDeployment(context *action.Context) error {
 		return err
 	}
 
-	return plugin.Destroy(instance.GetDeployName(), instance.CalculatedCodeParams, context.EventLog)
+	params, err := revealCodeParams(instance, context)
+	if err != nil {
+		return err
+	}
+
+	return plugin.Destroy(instance.GetDeployName(), params, context.EventLog)
 }
@@ -90,7 +90,12 @@ func (a *EndpointsAction) processEndpoints(context *action.Context) error {
 		return err
 	}
 
-	endpoints, err := plugin.Endpoints(instance.GetDeployName(), instance.CalculatedCodeParams, context.EventLog)
+	params, err := revealCodeParams(instance, context)
+	if err != nil {
+		return err
+	}
+
+	endpoints, err := plugin.Endpoints(instance.GetDeployName(), params, context.EventLog)
 	if err != nil {
 		return err
 	}
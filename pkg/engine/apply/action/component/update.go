@@ -84,5 +84,10 @@ func (a *UpdateAction) processDeployment(context *action.Context) error {
 		return err
 	}
 
-	return plugin.Update(instance.GetDeployName(), instance.CalculatedCodeParams, context.EventLog)
+	params, err := revealCodeParams(instance, context)
+	if err != nil {
+		return err
+	}
+
+	return plugin.Update(instance.GetDeployName(), params, context.EventLog)
 }
@@ -391,6 +391,14 @@ func (loader *SecretLoaderImpl) LoadSecretsByUserName(string) map[string]string
 	return nil
 }
 
+func (loader *SecretLoaderImpl) LoadSecretsByNamespace(string) map[string]string {
+	return nil
+}
+
+func (loader *SecretLoaderImpl) LoadSecretsByService(string, string) map[string]string {
+	return nil
+}
+
 func RunEngine(t *testing.T, testName string, desiredPolicy *lang.Policy, externalData *external.Data) {
 	fmt.Printf("Running engine for '%s'\n", testName)
 
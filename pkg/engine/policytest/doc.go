@@ -0,0 +1,5 @@
+// Package policytest implements unit testing for Aptomi policies. Test suites are declarative YAML files, which
+// describe users (with labels and secrets), dependencies and expected outcomes of policy resolution (resolved or
+// rejected, chosen context, cluster, component instances and their code parameters). Policy resolution is performed
+// fully offline, with users and secrets taken from the test suite instead of external sources.
+package policytest
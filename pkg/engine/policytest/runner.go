@@ -0,0 +1,229 @@
+package policytest
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/engine/resolve"
+	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/external"
+	"github.com/Aptomi/aptomi/pkg/external/secrets"
+	"github.com/Aptomi/aptomi/pkg/external/users"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/Sirupsen/logrus"
+	"sort"
+	"strings"
+)
+
+// CaseResult is a result of running a single test case
+type CaseResult struct {
+	// Suite is a name of the test suite
+	Suite string
+
+	// Name is a name of the test case
+	Name string
+
+	// Failures is a list of mismatches between expected and actual outcomes
+	Failures []string
+
+	// Messages is a list of warnings and errors logged by policy resolver, useful for understanding failures
+	Messages []string
+}
+
+// Passed returns true if test case passed
+func (result *CaseResult) Passed() bool {
+	return len(result.Failures) == 0
+}
+
+func (result *CaseResult) fail(format string, args ...interface{}) {
+	result.Failures = append(result.Failures, fmt.Sprintf(format, args...))
+}
+
+// Run runs all test cases from the given test suites against the policy, which consists of the given objects.
+// Dependencies defined in the policy objects are ignored, only dependencies from the test cases are resolved
+func Run(objects []lang.Base, suites []*Suite) []*CaseResult {
+	result := []*CaseResult{}
+	for _, suite := range suites {
+		for _, testCase := range suite.Cases {
+			result = append(result, runCase(objects, suite, testCase))
+		}
+	}
+	return result
+}
+
+func runCase(objects []lang.Base, suite *Suite, testCase *Case) *CaseResult {
+	result := &CaseResult{Suite: suite.Name, Name: testCase.Name}
+
+	// build policy
+	policy := lang.NewPolicy()
+	for _, obj := range objects {
+		if obj.GetKind() == lang.DependencyObject.Kind {
+			continue
+		}
+		err := policy.AddObject(obj)
+		if err != nil {
+			result.fail("can't add object to policy: %s", err)
+			return result
+		}
+	}
+	for _, dependency := range testCase.Dependencies {
+		err := policy.AddObject(dependency)
+		if err != nil {
+			result.fail("can't add dependency to policy: %s", err)
+			return result
+		}
+	}
+
+	// set up users and secrets, so that policy can be resolved offline
+	userLoader := users.NewUserLoaderMock()
+	secretLoader := secrets.NewSecretLoaderMock()
+	for _, user := range suite.Users {
+		userLoader.AddUser(&lang.User{Name: user.Name, Labels: user.Labels})
+		for name, value := range user.Secrets {
+			secretLoader.AddSecret(user.Name, name, value)
+		}
+	}
+
+	// resolve policy
+	eventLog := event.NewLog("policy-test", false)
+	resolver := resolve.NewPolicyResolver(policy, external.NewData(userLoader, secretLoader), eventLog)
+	resolution, err := resolver.ResolveAllDependencies()
+
+	result.Messages = eventLog.GetMessages(logrus.WarnLevel)
+
+	if err != nil {
+		result.fail("policy resolution failed: %s", err)
+		return result
+	}
+
+	for _, expect := range testCase.Expect {
+		checkExpectation(result, policy, resolution, secretLoader, expect)
+	}
+
+	return result
+}
+
+// checks a single expectation against the policy resolution data
+func checkExpectation(result *CaseResult, policy *lang.Policy, resolution *resolve.PolicyResolution, secretLoader secrets.SecretLoader, expect *Expectation) {
+	var dependency *lang.Dependency
+	for _, obj := range policy.GetObjectsByKind(lang.DependencyObject.Kind) {
+		if obj.GetName() == expect.Dependency {
+			dependency = obj.(*lang.Dependency)
+		}
+	}
+	if dependency == nil {
+		result.fail("dependency '%s': not found in the test case", expect.Dependency)
+		return
+	}
+
+	depKey := runtime.KeyForStorable(dependency)
+	serviceKey, resolved := resolution.GetDependencyInstanceMap()[depKey]
+	if resolved != expect.IsResolved() {
+		result.fail("dependency '%s': expected resolved=%t, got resolved=%t", expect.Dependency, expect.IsResolved(), resolved)
+		return
+	}
+	if !resolved {
+		return
+	}
+
+	service := resolution.ComponentInstanceMap[serviceKey].Metadata.Key
+	if len(expect.Context) > 0 && expect.Context != service.ContextName {
+		result.fail("dependency '%s': expected context '%s', got '%s'", expect.Dependency, expect.Context, service.ContextName)
+	}
+	if len(expect.Cluster) > 0 && expect.Cluster != service.ClusterName {
+		result.fail("dependency '%s': expected cluster '%s', got '%s'", expect.Dependency, expect.Cluster, service.ClusterName)
+	}
+
+	// collect all component instances, which are instantiated for the dependency
+	instances := make(map[string]*resolve.ComponentInstance)
+	for key, instance := range resolution.ComponentInstanceMap {
+		if instance.DependencyKeys[depKey] {
+			instances[key] = instance
+		}
+	}
+
+	for _, key := range expect.Components {
+		if _, ok := instances[key]; !ok {
+			keys := []string{}
+			for actualKey := range instances {
+				keys = append(keys, actualKey)
+			}
+			sort.Strings(keys)
+			result.fail("dependency '%s': expected component '%s' to be instantiated, got components:\n      %s", expect.Dependency, key, strings.Join(keys, "\n      "))
+		}
+	}
+
+	for name, expectedParams := range expect.CodeParams {
+		instance := instances[name]
+		if instance == nil {
+			// look up component by name within the service instance of the dependency
+			for _, candidate := range instances {
+				if candidate.Metadata.Key.ComponentName == name && candidate.Metadata.Key.GetParentServiceKey().GetKey() == serviceKey {
+					instance = candidate
+				}
+			}
+		}
+		if instance == nil {
+			result.fail("dependency '%s': expected component '%s' to be instantiated, but it's not found", expect.Dependency, name)
+			continue
+		}
+
+		// compare against code params with secrets revealed, the same way as they are passed to code plugins
+		params, _, err := secrets.Reveal(instance.CalculatedCodeParams, secretLoader, instance.GetSecretOwners(policy))
+		if err != nil {
+			result.fail("dependency '%s': component '%s' code params: %s", expect.Dependency, name, err)
+			continue
+		}
+
+		for _, diff := range diffSubset("", map[string]interface{}(expectedParams), map[string]interface{}(params)) {
+			result.fail("dependency '%s': component '%s' code params: %s", expect.Dependency, name, diff)
+		}
+	}
+}
+
+// diffSubset returns a list of differences, if expected value is not a subset of the actual value
+func diffSubset(path string, expected interface{}, actual interface{}) []string {
+	expectedMap, expectedIsMap := toMap(expected)
+	if !expectedIsMap {
+		if fmt.Sprintf("%v", expected) != fmt.Sprintf("%v", actual) {
+			return []string{fmt.Sprintf("'%s' expected '%v', got '%v'", path, expected, actual)}
+		}
+		return nil
+	}
+
+	actualMap, actualIsMap := toMap(actual)
+	if !actualIsMap {
+		return []string{fmt.Sprintf("'%s' expected a map, got '%v'", path, actual)}
+	}
+
+	keys := []string{}
+	for key := range expectedMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	result := []string{}
+	for _, key := range keys {
+		keyPath := key
+		if len(path) > 0 {
+			keyPath = path + "." + key
+		}
+		actualValue, ok := actualMap[key]
+		if !ok {
+			result = append(result, fmt.Sprintf("'%s' expected '%v', but it's missing", keyPath, expectedMap[key]))
+			continue
+		}
+		result = append(result, diffSubset(keyPath, expectedMap[key], actualValue)...)
+	}
+	return result
+}
+
+func toMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case util.NestedParameterMap:
+		return v, true
+	}
+	return nil, false
+}
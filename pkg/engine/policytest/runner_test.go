@@ -0,0 +1,186 @@
+package policytest
+
+import (
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/codec/yaml"
+	"github.com/stretchr/testify/assert"
+	yamlv2 "gopkg.in/yaml.v2"
+	"testing"
+)
+
+const testPolicy = `
+- kind: aclrule
+  metadata:
+    namespace: system
+    name: consumers
+  criteria:
+    require-all:
+      - true
+  actions:
+    add-role:
+      service-consumer: main
+
+- kind: cluster
+  metadata:
+    namespace: system
+    name: cluster1
+  type: kubernetes
+  config:
+    namespace: default
+
+- kind: service
+  metadata:
+    namespace: main
+    name: service1
+  components:
+    - name: component1
+      code:
+        type: helm
+        params:
+          cluster: "{{ .Labels.cluster }}"
+          user: "{{ .User.Name }}"
+          token: "token-{{ .User.Secrets.token }}"
+          nested:
+            replicas: 1
+
+- kind: contract
+  metadata:
+    namespace: main
+    name: contract1
+  contexts:
+    - name: prod
+      criteria:
+        require-all:
+          - team == 'ops'
+      allocation:
+        service: service1
+    - name: dev
+      criteria:
+        require-all:
+          - team == 'dev'
+      allocation:
+        service: service1
+        keys:
+          - "{{ .User.Name }}"
+
+- kind: dependency
+  metadata:
+    namespace: main
+    name: ignored
+  user: unknown
+  contract: contract1
+`
+
+const testSuite = `
+name: suite1
+users:
+  - name: alice
+    labels:
+      team: dev
+    secrets:
+      token: alicetoken
+  - name: bob
+    labels:
+      team: ops
+    secrets:
+      token: bobtoken
+  - name: carol
+    labels:
+      team: finance
+
+tests:
+  - name: passing
+    dependencies:
+      - metadata:
+          namespace: main
+          name: alice
+        user: alice
+        contract: contract1
+        labels:
+          cluster: cluster1
+      - metadata:
+          namespace: main
+          name: bob
+        user: bob
+        contract: contract1
+        labels:
+          cluster: cluster1
+      - metadata:
+          namespace: main
+          name: carol
+        user: carol
+        contract: contract1
+        labels:
+          cluster: cluster1
+    expect:
+      - dependency: alice
+        context: dev
+        cluster: cluster1
+        components:
+          - cluster1#main#contract1#dev#alice#component1
+        code-params:
+          component1:
+            user: alice
+            token: token-alicetoken
+            nested:
+              replicas: 1
+      - dependency: bob
+        context: prod
+      - dependency: carol
+        resolved: false
+
+  - name: failing
+    dependencies:
+      - metadata:
+          namespace: main
+          name: alice
+        user: alice
+        contract: contract1
+        labels:
+          cluster: cluster1
+    expect:
+      - dependency: alice
+        context: prod
+        code-params:
+          component1:
+            user: bob
+            missing: value
+      - dependency: unknown
+`
+
+func TestPolicyTestRunner(t *testing.T) {
+	objects, err := yaml.NewCodec(runtime.NewRegistry().Append(lang.PolicyObjects...)).DecodeOneOrMany([]byte(testPolicy))
+	if !assert.NoError(t, err, "Policy should be decoded successfully") {
+		return
+	}
+	policyObjects := []lang.Base{}
+	for _, obj := range objects {
+		policyObjects = append(policyObjects, obj.(lang.Base))
+	}
+
+	suite := &Suite{}
+	if !assert.NoError(t, yamlv2.Unmarshal([]byte(testSuite), suite), "Test suite should be decoded successfully") {
+		return
+	}
+	for _, testCase := range suite.Cases {
+		for _, dependency := range testCase.Dependencies {
+			dependency.TypeKind = lang.DependencyObject.GetTypeKind()
+		}
+	}
+
+	results := Run(policyObjects, []*Suite{suite})
+	if !assert.Equal(t, 2, len(results), "Number of test case results should be correct") {
+		return
+	}
+
+	assert.True(t, results[0].Passed(), "Test case should pass: %v", results[0].Failures)
+
+	assert.False(t, results[1].Passed(), "Test case should fail")
+	assert.Equal(t, []string{
+		"dependency 'alice': expected context 'prod', got 'dev'",
+		"dependency 'alice': component 'component1' code params: 'missing' expected 'value', but it's missing",
+		"dependency 'alice': component 'component1' code params: 'user' expected 'bob', got 'alice'",
+		"dependency 'unknown': not found in the test case",
+	}, results[1].Failures, "Test case failures should be correct")
+}
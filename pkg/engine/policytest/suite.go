@@ -0,0 +1,114 @@
+package policytest
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/util"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"sort"
+)
+
+// Suite is a set of policy test cases, which share the same set of users
+type Suite struct {
+	// Name is a name of the test suite. If it's not specified, the name of the file will be used
+	Name string
+
+	// Users is a list of users (with labels and secrets) available to all test cases within the suite
+	Users []*User
+
+	// Cases is a list of test cases
+	Cases []*Case `yaml:"tests"`
+}
+
+// User is a user definition for the test suite
+type User struct {
+	// Name is a unique name of a user
+	Name string
+
+	// Labels is a set of labels attached to the user
+	Labels map[string]string
+
+	// Secrets is a set of secrets available for the user
+	Secrets map[string]string
+}
+
+// Case is a single policy test case. Policy gets resolved with the given set of dependencies (dependencies
+// defined in the policy itself are ignored), and then the outcome gets compared with expectations
+type Case struct {
+	// Name is a name of the test case
+	Name string
+
+	// Dependencies is a list of dependencies to resolve
+	Dependencies []*lang.Dependency
+
+	// Expect is a list of expected outcomes for the dependencies
+	Expect []*Expectation
+}
+
+// Expectation describes an expected outcome of policy resolution for a single dependency. All fields, except
+// Dependency, are optional. Only specified fields are checked
+type Expectation struct {
+	// Dependency is a name of the dependency
+	Dependency string
+
+	// Resolved defines whether dependency is expected to be resolved or rejected (default is true)
+	Resolved *bool `yaml:"resolved,omitempty"`
+
+	// Context is a name of the expected context
+	Context string `yaml:"context,omitempty"`
+
+	// Cluster is a name of the expected cluster
+	Cluster string `yaml:"cluster,omitempty"`
+
+	// Components is a list of component instance keys, which are expected to be instantiated for the dependency
+	Components []string `yaml:"components,omitempty"`
+
+	// CodeParams is a map from component name (or component instance key) to the subset of expected code parameters
+	CodeParams map[string]util.NestedParameterMap `yaml:"code-params,omitempty"`
+}
+
+// IsResolved returns whether dependency is expected to be resolved
+func (expect *Expectation) IsResolved() bool {
+	return expect.Resolved == nil || *expect.Resolved
+}
+
+// LoadSuites loads test suites from the given files and directories
+func LoadSuites(paths []string) ([]*Suite, error) {
+	files, err := util.FindYamlFiles(paths)
+	if err != nil {
+		return nil, fmt.Errorf("error while searching for policy test files: %s", err)
+	}
+	sort.Strings(files)
+
+	result := []*Suite{}
+	for _, file := range files {
+		data, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("can't read file %s error: %s", file, err)
+		}
+
+		suite := &Suite{}
+		err = yaml.Unmarshal(data, suite)
+		if err != nil {
+			return nil, fmt.Errorf("can't unmarshal file %s error: %s", file, err)
+		}
+		if len(suite.Name) <= 0 {
+			suite.Name = file
+		}
+
+		for _, testCase := range suite.Cases {
+			for _, dependency := range testCase.Dependencies {
+				dependency.TypeKind = lang.DependencyObject.GetTypeKind()
+			}
+		}
+
+		result = append(result, suite)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("no policy test files found in %s", paths)
+	}
+
+	return result, nil
+}
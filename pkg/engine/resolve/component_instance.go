@@ -3,6 +3,7 @@ package resolve
 import (
 	"fmt"
 	"github.com/Aptomi/aptomi/pkg/errors"
+	"github.com/Aptomi/aptomi/pkg/external/secrets"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/util"
@@ -123,6 +124,20 @@ func (instance *ComponentInstance) GetRunningTime() time.Duration {
 	return time.Since(instance.CreatedAt)
 }
 
+// GetSecretOwners returns owners, which secrets can be revealed in code params of this component instance. These are
+// its namespace, its service and users of dependencies, which keep it instantiated. Users are taken from a given
+// policy, so dependencies which are no longer in the policy don't allow anything
+func (instance *ComponentInstance) GetSecretOwners(policy *lang.Policy) *secrets.Owners {
+	users := []string{}
+	for _, obj := range policy.GetObjectsByKind(lang.DependencyObject.Kind) {
+		dependency := obj.(*lang.Dependency)
+		if instance.DependencyKeys[runtime.KeyForStorable(dependency)] {
+			users = append(users, dependency.User)
+		}
+	}
+	return secrets.NewOwners(instance.Metadata.Key.Namespace, instance.Metadata.Key.ServiceName, users...)
+}
+
 func (instance *ComponentInstance) addDependency(dependencyKey string) {
 	instance.DependencyKeys[dependencyKey] = true
 }
@@ -11,7 +11,9 @@ import (
 	"github.com/Aptomi/aptomi/pkg/util"
 	sysruntime "runtime"
 	"runtime/debug"
+	"sort"
 	"sync"
+	"time"
 )
 
 // MaxConcurrentGoRoutines is the number of concurrently running goroutines for policy evaluation and processing.
@@ -51,19 +53,31 @@ type PolicyResolver struct {
 	// Reference to the calculated PolicyResolution
 	resolution *PolicyResolution
 
+	// Usage of quotas by resolved dependencies
+	quotaUsage *quotaUsage
+
 	// Buffered event log - gets populated during policy resolution
 	eventLog *event.Log
+
+	// Buffered event logs for every dependency: dependencyKey -> eventLog
+	dependencyEventLogs map[string]*event.Log
+
+	// Time of the policy resolution, used to calculate remaining lifetime of dependencies
+	now time.Time
 }
 
 // NewPolicyResolver creates a new policy resolver
 func NewPolicyResolver(policy *lang.Policy, externalData *external.Data, eventLog *event.Log) *PolicyResolver {
 	return &PolicyResolver{
-		policy:          policy,
-		externalData:    externalData,
-		expressionCache: expression.NewCache(),
-		templateCache:   template.NewCache(),
-		resolution:      NewPolicyResolution(true),
-		eventLog:        eventLog,
+		policy:              policy,
+		externalData:        externalData,
+		expressionCache:     expression.NewCache(),
+		templateCache:       template.NewCache(),
+		resolution:          NewPolicyResolution(true),
+		quotaUsage:          newQuotaUsage(),
+		eventLog:            eventLog,
+		dependencyEventLogs: make(map[string]*event.Log),
+		now:                 time.Now(),
 	}
 }
 
@@ -82,25 +96,41 @@ func (resolver *PolicyResolver) ResolveAllDependencies() (*PolicyResolution, err
 	// Allocate semaphore
 	var semaphore = make(chan int, MaxConcurrentGoRoutines)
 	dependencies := resolver.policy.GetObjectsByKind(lang.DependencyObject.Kind)
-	var errs = make(chan error, len(dependencies))
+	var nodes = make([]*resolutionNode, len(dependencies))
+	var errs = make([]error, len(dependencies))
+	var wg sync.WaitGroup
+
+	// Sort dependencies, so they always get combined in the same order (it matters for quotas). Dependencies admitted
+	// earlier take quota slots first, so a new dependency never evicts the one which already exists
+	sort.Slice(dependencies, func(i, j int) bool {
+		di, dj := dependencies[i].(*lang.Dependency), dependencies[j].(*lang.Dependency)
+		if !di.AdmittedAt.Equal(dj.AdmittedAt) {
+			return di.AdmittedAt.Before(dj.AdmittedAt)
+		}
+		return runtime.KeyForStorable(di) < runtime.KeyForStorable(dj)
+	})
 
 	// Run every declared dependency via policy and resolve it
-	for _, d := range dependencies {
+	for idx, d := range dependencies {
 		// resolve dependency via applying policy
 		semaphore <- 1
-		go func(d *lang.Dependency) {
-			node, resolveErr := resolver.resolveDependency(d)
-			errs <- resolver.combineData(node, resolveErr)
+		wg.Add(1)
+		go func(idx int, d *lang.Dependency) {
+			defer wg.Done()
+			nodes[idx], errs[idx] = resolver.resolveDependency(d)
 			<-semaphore
-		}(d.(*lang.Dependency))
+		}(idx, d.(*lang.Dependency))
 	}
 
+	// Wait for all go routines to end
+	wg.Wait()
+
 	errMsg := ""
 
-	// Wait for all go routines to end
+	// Combine resolution data in order
 	errFound := 0
-	for i := 0; i < len(dependencies); i++ {
-		resolveErr := <-errs
+	for idx := range dependencies {
+		resolveErr := resolver.combineData(nodes[idx], errs[idx])
 		if resolveErr != nil {
 			errFound++
 			errMsg += "\n - " + resolveErr.Error()
@@ -129,6 +159,12 @@ func (resolver *PolicyResolver) ResolveAllDependencies() (*PolicyResolution, err
 	return resolver.resolution, nil
 }
 
+// GetDependencyEventLog returns event log with all entries recorded while resolving a given dependency. It can be
+// called only after ResolveAllDependencies
+func (resolver *PolicyResolver) GetDependencyEventLog(dependency *lang.Dependency) *event.Log {
+	return resolver.dependencyEventLogs[runtime.KeyForStorable(dependency)]
+}
+
 // Resolves a single dependency
 func (resolver *PolicyResolver) resolveDependency(d *lang.Dependency) (node *resolutionNode, resolveErr error) {
 	// create new resolution node
@@ -158,8 +194,13 @@ func (resolver *PolicyResolver) combineData(node *resolutionNode, resolutionErr
 	// aggregate logs in the end, especially if resolutionErr occurred
 	defer func() {
 		if node != nil {
+			dependencyEventLog := event.NewLog(resolver.eventLog.GetScope(), false)
 			for _, eventLog := range node.eventLogsCombined {
 				resolver.eventLog.Append(eventLog)
+				dependencyEventLog.Append(eventLog)
+			}
+			if node.dependency != nil {
+				resolver.dependencyEventLogs[runtime.KeyForStorable(node.dependency)] = dependencyEventLog
 			}
 		}
 		resolver.combineMutex.Unlock()
@@ -180,11 +221,18 @@ func (resolver *PolicyResolver) combineData(node *resolutionNode, resolutionErr
 		return nil
 	}
 
+	// exit if dependency doesn't fit into quotas
+	err := node.checkQuotas(resolver.quotaUsage)
+	if err != nil {
+		node.resolved = false
+		return node.cannotResolveInstance(err)
+	}
+
 	// add a record for dependency resolution
 	resolver.resolution.dependencyInstanceMap[runtime.KeyForStorable(node.dependency)] = node.serviceKey.GetKey()
 
 	// append component instance data
-	err := resolver.resolution.AppendData(node.resolution)
+	err = resolver.resolution.AppendData(node.resolution)
 	if err != nil {
 		node.eventLog.LogError(err)
 		return err
@@ -350,6 +350,14 @@ func (node *resolutionNode) processRules() (*lang.RuleActionResult, error) {
 	if err != nil {
 		return nil, err
 	}
+
+	// if rules limit dependency lifetime, make sure its remaining lifetime is within that limit
+	if result.MaxTTL > 0 {
+		lifetime, limited := node.dependency.GetLifetime(node.resolver.now)
+		if !limited || lifetime > result.MaxTTL {
+			return nil, node.errorDependencyTTLExceedsMax(result.MaxTTL)
+		}
+	}
 	return result, nil
 }
 
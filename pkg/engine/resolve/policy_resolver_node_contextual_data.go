@@ -1,6 +1,7 @@
 package resolve
 
 import (
+	"github.com/Aptomi/aptomi/pkg/external/secrets"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/lang/expression"
 	"github.com/Aptomi/aptomi/pkg/lang/template"
@@ -59,10 +60,12 @@ func (node *resolutionNode) getContextualDataForCodeDiscoveryTemplate() *templat
 	return template.NewParams(
 		struct {
 			User      interface{}
+			Service   interface{}
 			Labels    interface{}
 			Discovery interface{}
 		}{
 			User:      node.proxyUser(node.user),
+			Service:   node.proxyServiceWithSecrets(node.service),
 			Labels:    node.labels.Labels,
 			Discovery: node.proxyDiscovery(node.discoveryTreeNode, node.componentKey),
 		},
@@ -84,7 +87,22 @@ func (node *resolutionNode) proxyService(service *lang.Service) interface{} {
 	}
 }
 
-// How user is visible from the policy language
+// How service is visible from the templates, including secrets of the service and its namespace. Secrets are
+// exposed as placeholders, which get replaced with actual values only when they are passed to code plugins
+func (node *resolutionNode) proxyServiceWithSecrets(service *lang.Service) interface{} {
+	return struct {
+		lang.Metadata
+		Labels  interface{}
+		Secrets interface{}
+	}{
+		Metadata: service.Metadata,
+		Labels:   service.Labels,
+		Secrets:  secrets.PlaceholdersForService(node.resolver.externalData.SecretLoader, service.Namespace, service.Name),
+	}
+}
+
+// How user is visible from the policy language. Secrets are exposed as placeholders, which get replaced with actual
+// values only when they are passed to code plugins
 func (node *resolutionNode) proxyUser(user *lang.User) interface{} {
 	result := struct {
 		Name    interface{}
@@ -93,7 +111,7 @@ func (node *resolutionNode) proxyUser(user *lang.User) interface{} {
 	}{
 		Name:    user.Name,
 		Labels:  user.Labels,
-		Secrets: node.resolver.externalData.SecretLoader.LoadSecretsByUserName(user.Name),
+		Secrets: secrets.PlaceholdersForUser(node.resolver.externalData.SecretLoader, user.Name),
 	}
 	return result
 }
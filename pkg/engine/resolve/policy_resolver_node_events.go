@@ -7,6 +7,7 @@ import (
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"strings"
+	"time"
 )
 
 /*
@@ -28,6 +29,30 @@ func (node *resolutionNode) errorDependencyNotAllowedByRules() error {
 	)
 }
 
+func (node *resolutionNode) errorDependencyTTLExceedsMax(maxTTL time.Duration) error {
+	ttl := "unlimited"
+	if lifetime, limited := node.dependency.GetLifetime(node.resolver.now); limited {
+		ttl = lifetime.Round(time.Second).String()
+	}
+	return errors.NewErrorWithDetails(
+		fmt.Sprintf("Dependency TTL exceeds the maximum allowed by rules: '%s' -> '%s' (TTL %s, max %s)", node.dependency.User, node.dependency.Contract, ttl, maxTTL),
+		errors.Details{
+			"ttl":    ttl,
+			"maxTTL": maxTTL.String(),
+		},
+	)
+}
+
+func (node *resolutionNode) errorQuotaExceeded(quota *lang.Quota, group string, what string, limit int) error {
+	return errors.NewErrorWithDetails(
+		fmt.Sprintf("Quota exceeded: '%s' -> '%s' (quota '%s', %s '%s', max %d %s)", node.dependency.User, node.dependency.Contract, runtime.KeyForStorable(quota), quota.Scope, group, limit, what),
+		errors.Details{
+			"quota": quota,
+			"group": group,
+		},
+	)
+}
+
 func (node *resolutionNode) userNotAllowedToConsumeService(err error) error {
 	return errors.NewErrorWithDetails(
 		fmt.Sprintf("User '%s' not allowed to consume service: %s", node.dependency.User, err),
@@ -88,6 +113,18 @@ func (node *resolutionNode) errorWhenProcessingRule(rule *lang.Rule, cause error
 	return NewCriticalError(err)
 }
 
+func (node *resolutionNode) errorWhenProcessingQuota(quota *lang.Quota, cause error) error {
+	err := errors.NewErrorWithDetails(
+		fmt.Sprintf("Error while processing quota '%s' for dependency '%s' -> '%s': %s", runtime.KeyForStorable(quota), node.dependency.User, node.dependency.Contract, cause),
+		errors.Details{
+			"quota":  quota,
+			"labels": node.labels.Labels,
+			"cause":  cause,
+		},
+	)
+	return NewCriticalError(err)
+}
+
 func (node *resolutionNode) errorWhenResolvingAllocationKeys(cause error) error {
 	err := errors.NewErrorWithDetails(
 		fmt.Sprintf("Error while resolving allocation keys for contract '%s', context '%s': %s", node.contract.Name, node.context.Name, cause),
@@ -7,8 +7,11 @@ import (
 	"github.com/Aptomi/aptomi/pkg/lang/builder"
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/util"
+	"github.com/Sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestPolicyResolverContract(t *testing.T) {
@@ -341,6 +344,204 @@ func TestPolicyResolverPickClusterViaRules(t *testing.T) {
 	assert.Equal(t, cluster2.Name, instance2.CalculatedLabels.Labels[lang.LabelCluster], "Cluster should be set correctly via rules")
 }
 
+func TestPolicyResolverDependencyMaxTTL(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+
+	// create a service
+	service := b.AddService()
+	b.AddServiceComponent(service, b.CodeComponent(nil, nil))
+	contract := b.AddContract(service, b.CriteriaTrue())
+
+	// add rule to set cluster
+	cluster := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelCluster, cluster.Name)))
+
+	// add rule, which limits TTL for dependencies with a certain label
+	b.AddRule(b.Criteria("label1 == 'value1'", "true", "false"), &lang.RuleActions{MaxTTL: "1h"})
+
+	// add dependency without TTL (should not be resolved)
+	d1 := b.AddDependency(b.AddUser(), contract)
+	d1.Labels["label1"] = "value1"
+
+	// add dependency with TTL exceeding the limit (should not be resolved)
+	d2 := b.AddDependency(b.AddUser(), contract)
+	d2.Labels["label1"] = "value1"
+	d2.TTL = "2h"
+
+	// add dependency with TTL within the limit (should be resolved)
+	d3 := b.AddDependency(b.AddUser(), contract)
+	d3.Labels["label1"] = "value1"
+	d3.TTL = "30m"
+
+	// add dependency which is not affected by the rule (should be resolved)
+	d4 := b.AddDependency(b.AddUser(), contract)
+
+	// add dependency with short TTL, but with expiration time far in the future (should not be resolved)
+	d5 := b.AddDependency(b.AddUser(), contract)
+	d5.Labels["label1"] = "value1"
+	d5.TTL = "30m"
+	d5.ExpiresAt = time.Now().Add(24 * time.Hour)
+
+	// policy resolution should be completed successfully
+	resolution := resolvePolicy(t, b, ResSuccess, "TTL exceeds the maximum allowed by rules")
+
+	// check that only dependencies within TTL limits got resolved
+	assert.NotContains(t, resolution.GetDependencyInstanceMap(), runtime.KeyForStorable(d1), "Dependency without TTL should not be resolved")
+	assert.NotContains(t, resolution.GetDependencyInstanceMap(), runtime.KeyForStorable(d2), "Dependency with TTL exceeding the limit should not be resolved")
+	assert.Contains(t, resolution.GetDependencyInstanceMap(), runtime.KeyForStorable(d3), "Dependency with TTL within the limit should be resolved")
+	assert.Contains(t, resolution.GetDependencyInstanceMap(), runtime.KeyForStorable(d4), "Dependency not affected by the rule should be resolved")
+	assert.NotContains(t, resolution.GetDependencyInstanceMap(), runtime.KeyForStorable(d5), "Dependency with expiration time exceeding the limit should not be resolved")
+}
+
+func TestPolicyResolverDependencyEventLog(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+
+	// create a service
+	service := b.AddService()
+	b.AddServiceComponent(service, b.CodeComponent(nil, nil))
+	contract := b.AddContract(service, b.CriteriaTrue())
+
+	// add rule to set cluster
+	cluster := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelCluster, cluster.Name)))
+
+	// add rule, which rejects dependencies with a certain label
+	b.AddRule(b.Criteria("label1 == 'value1'", "true", "false"), &lang.RuleActions{Dependency: lang.DependencyAction(lang.Reject)})
+
+	// add dependency which gets rejected and dependency which gets resolved
+	d1 := b.AddDependency(b.AddUser(), contract)
+	d1.Labels["label1"] = "value1"
+	d2 := b.AddDependency(b.AddUser(), contract)
+
+	resolver := NewPolicyResolver(b.Policy(), b.External(), event.NewLog("test-resolve", false))
+	_, err := resolver.ResolveAllDependencies()
+	if !assert.NoError(t, err, "Policy resolution should be completed successfully") {
+		return
+	}
+
+	// check that every dependency has its own event log
+	messages1 := strings.Join(resolver.GetDependencyEventLog(d1).GetMessages(logrus.WarnLevel), "\n")
+	assert.Contains(t, messages1, "Rules do not allow dependency", "Event log for rejected dependency should contain rejection reason")
+	messages2 := strings.Join(resolver.GetDependencyEventLog(d2).GetMessages(logrus.WarnLevel), "\n")
+	assert.Empty(t, messages2, "Event log for resolved dependency should not contain warnings")
+}
+
+func TestPolicyResolverQuotaPerUser(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+
+	// create a service with two contexts, so dependencies can result in different service instances
+	service := b.AddService()
+	b.AddServiceComponent(service, b.CodeComponent(nil, nil))
+	contract := b.AddContractMultipleContexts(service,
+		b.Criteria("label1 == 'value1'", "true", "false"),
+		b.Criteria("label2 == 'value2'", "true", "false"),
+	)
+
+	// add rule to set cluster
+	cluster := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelCluster, cluster.Name)))
+
+	// allow every user to have at most two dependencies
+	b.AddQuota(lang.QuotaScopeUser, 2, 0)
+
+	// add three dependencies for one user and one dependency for another user
+	user1 := b.AddUser()
+	user2 := b.AddUser()
+	deps := []*lang.Dependency{}
+	for i := 0; i < 3; i++ {
+		d := b.AddDependency(user1, contract)
+		d.Labels["label1"] = "value1"
+		deps = append(deps, d)
+	}
+	d4 := b.AddDependency(user2, contract)
+	d4.Labels["label1"] = "value1"
+
+	// policy resolution should be completed successfully
+	resolution := resolvePolicy(t, b, ResSuccess, "Quota exceeded")
+
+	// check that only two dependencies of the first user got resolved
+	resolvedCnt := 0
+	for _, d := range deps {
+		if _, ok := resolution.GetDependencyInstanceMap()[runtime.KeyForStorable(d)]; ok {
+			resolvedCnt++
+		}
+	}
+	assert.Equal(t, 2, resolvedCnt, "Only two dependencies of the first user should be resolved")
+	assert.Contains(t, resolution.GetDependencyInstanceMap(), runtime.KeyForStorable(d4), "Dependency of the second user should be resolved")
+}
+
+func TestPolicyResolverQuotaAdmissionOrder(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+
+	service := b.AddService()
+	b.AddServiceComponent(service, b.CodeComponent(nil, nil))
+	contract := b.AddContract(service, b.CriteriaTrue())
+	cluster := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelCluster, cluster.Name)))
+
+	// allow every user to have only one dependency
+	b.AddQuota(lang.QuotaScopeUser, 1, 0)
+
+	// existing dependency goes after the new one in alphabetical order, but it was admitted earlier
+	user := b.AddUser()
+	existing := b.AddDependency(user, contract)
+	added := b.AddDependency(user, contract)
+	if runtime.KeyForStorable(existing) < runtime.KeyForStorable(added) {
+		existing, added = added, existing
+	}
+	now := time.Now()
+	existing.AdmittedAt = now.Add(-time.Hour)
+	added.AdmittedAt = now
+
+	resolution := resolvePolicy(t, b, ResSuccess, "Quota exceeded")
+	assert.Contains(t, resolution.GetDependencyInstanceMap(), runtime.KeyForStorable(existing), "Existing dependency should keep its quota slot")
+	assert.NotContains(t, resolution.GetDependencyInstanceMap(), runtime.KeyForStorable(added), "New dependency shouldn't evict the existing one")
+}
+
+func TestPolicyResolverQuotaInstancesPerCluster(t *testing.T) {
+	b := builder.NewPolicyBuilder()
+
+	// create a service with two contexts, so dependencies can result in different service instances
+	service := b.AddService()
+	b.AddServiceComponent(service, b.CodeComponent(nil, nil))
+	contract := b.AddContractMultipleContexts(service,
+		b.Criteria("label1 == 'value1'", "true", "false"),
+		b.Criteria("label2 == 'value2'", "true", "false"),
+	)
+
+	// add rule to set cluster
+	cluster := b.AddCluster()
+	b.AddRule(b.CriteriaTrue(), b.RuleActions(lang.NewLabelOperationsSetSingleLabel(lang.LabelCluster, cluster.Name)))
+
+	// allow only one service instance per cluster
+	b.AddQuota(lang.QuotaScopeCluster, 0, 1)
+
+	// add two dependencies sharing the same service instance, and one dependency requiring another instance
+	d1 := b.AddDependency(b.AddUser(), contract)
+	d1.Labels["label1"] = "value1"
+	d2 := b.AddDependency(b.AddUser(), contract)
+	d2.Labels["label1"] = "value1"
+	d3 := b.AddDependency(b.AddUser(), contract)
+	d3.Labels["label2"] = "value2"
+
+	// policy resolution should be completed successfully
+	resolution := resolvePolicy(t, b, ResSuccess, "Quota exceeded")
+
+	// check that only one service instance got allocated
+	instances := make(map[string]bool)
+	for _, serviceKey := range resolution.GetDependencyInstanceMap() {
+		instances[serviceKey] = true
+	}
+	assert.Equal(t, 1, len(instances), "Only one service instance should be allocated")
+
+	// check that dependencies sharing the same instance got resolved together
+	_, d1Resolved := resolution.GetDependencyInstanceMap()[runtime.KeyForStorable(d1)]
+	_, d2Resolved := resolution.GetDependencyInstanceMap()[runtime.KeyForStorable(d2)]
+	_, d3Resolved := resolution.GetDependencyInstanceMap()[runtime.KeyForStorable(d3)]
+	assert.Equal(t, d1Resolved, d2Resolved, "Dependencies sharing the same instance should be resolved together")
+	assert.NotEqual(t, d1Resolved, d3Resolved, "Dependencies on different instances should not be resolved together")
+}
+
 func TestPolicyResolverInternalPanic(t *testing.T) {
 	b := builder.NewPolicyBuilder()
 	b.PanicWhenLoadingUsers()
@@ -0,0 +1,111 @@
+package resolve
+
+import (
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"sort"
+	"strings"
+)
+
+// quotaUsage keeps track of dependencies and service instances counted against quotas, as resolved dependencies
+// get combined into the overall policy resolution
+type quotaUsage struct {
+	// dependencies is a number of dependencies counted: quota key + group -> count
+	dependencies map[string]int
+
+	// instances is a set of service instances counted: quota key + group -> service key -> true
+	instances map[string]map[string]bool
+}
+
+// newQuotaUsage creates a new empty quotaUsage
+func newQuotaUsage() *quotaUsage {
+	return &quotaUsage{
+		dependencies: make(map[string]int),
+		instances:    make(map[string]map[string]bool),
+	}
+}
+
+// record counts a dependency and its service instance against a given quota group
+func (usage *quotaUsage) record(groupKey string, serviceKey string) {
+	usage.dependencies[groupKey]++
+	if usage.instances[groupKey] == nil {
+		usage.instances[groupKey] = make(map[string]bool)
+	}
+	usage.instances[groupKey][serviceKey] = true
+}
+
+// getQuotas returns quotas which apply to a given dependency (defined globally in system namespace, and defined in
+// the namespace of the dependency), sorted by their keys
+func (resolver *PolicyResolver) getQuotas(dependency *lang.Dependency) []*lang.Quota {
+	namespaces := []string{runtime.SystemNS}
+	if dependency.Namespace != runtime.SystemNS {
+		namespaces = append(namespaces, dependency.Namespace)
+	}
+
+	result := []*lang.Quota{}
+	for _, ns := range namespaces {
+		policyNamespace := resolver.policy.Namespace[ns]
+		if policyNamespace == nil {
+			continue
+		}
+		for _, quota := range policyNamespace.Quotas {
+			result = append(result, quota)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return runtime.KeyForStorable(result[i]) < runtime.KeyForStorable(result[j])
+	})
+	return result
+}
+
+// getQuotaGroup returns the name of a group, against which resolved dependency will be counted for a given quota.
+// If quota cannot be applied to a dependency (e.g. user doesn't have a label), false will be returned
+func (node *resolutionNode) getQuotaGroup(quota *lang.Quota) (string, bool) {
+	switch quota.Scope {
+	case lang.QuotaScopeUser:
+		return strings.ToLower(node.user.Name), true
+	case lang.QuotaScopeLabel:
+		value, ok := node.user.Labels[quota.Label]
+		return value, ok
+	case lang.QuotaScopeNamespace:
+		return node.dependency.Namespace, true
+	case lang.QuotaScopeCluster:
+		return node.serviceKey.ClusterName, true
+	}
+	return "", false
+}
+
+// checkQuotas verifies that a resolved dependency fits into all quotas which apply to it. If it does, the dependency
+// and its service instance get counted against the quotas. Otherwise, an error is returned and nothing gets counted
+func (node *resolutionNode) checkQuotas(usage *quotaUsage) error {
+	groupKeys := []string{}
+	serviceKey := node.serviceKey.GetKey()
+	for _, quota := range node.resolver.getQuotas(node.dependency) {
+		matched, err := quota.Matches(node.getContextualDataForRuleExpression(), node.resolver.expressionCache)
+		if err != nil {
+			return node.errorWhenProcessingQuota(quota, err)
+		}
+		if !matched {
+			continue
+		}
+
+		group, ok := node.getQuotaGroup(quota)
+		if !ok {
+			continue
+		}
+
+		groupKey := runtime.KeyForStorable(quota) + "#" + group
+		if quota.MaxDependencies > 0 && usage.dependencies[groupKey] >= quota.MaxDependencies {
+			return node.errorQuotaExceeded(quota, group, "dependencies", quota.MaxDependencies)
+		}
+		if quota.MaxInstances > 0 && !usage.instances[groupKey][serviceKey] && len(usage.instances[groupKey]) >= quota.MaxInstances {
+			return node.errorQuotaExceeded(quota, group, "service instances", quota.MaxInstances)
+		}
+		groupKeys = append(groupKeys, groupKey)
+	}
+
+	for _, groupKey := range groupKeys {
+		usage.record(groupKey, serviceKey)
+	}
+	return nil
+}
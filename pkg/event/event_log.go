@@ -20,6 +20,7 @@ type attachedObjects struct {
 type Log struct {
 	logger     *logrus.Logger
 	attachedTo *attachedObjects
+	hookRedact *HookRedact
 	hookMemory *HookMemory
 	scope      string
 	log        bool
@@ -37,6 +38,10 @@ func NewLog(scope string, log bool) *Log {
 		Level:     logrus.DebugLevel,
 	}
 
+	// redaction hook goes first, so other hooks never see sensitive values
+	hookRedact := &HookRedact{}
+	logger.Hooks.Add(hookRedact)
+
 	hookMemory := &HookMemory{}
 	logger.Hooks.Add(hookMemory)
 
@@ -47,6 +52,7 @@ func NewLog(scope string, log bool) *Log {
 	return &Log{
 		logger:     logger,
 		attachedTo: &attachedObjects{},
+		hookRedact: hookRedact,
 		hookMemory: hookMemory,
 		scope:      scope,
 		log:        log,
@@ -93,6 +99,11 @@ func (eventLog *Log) WithFields(fields Fields) *logrus.Entry {
 	return eventLog.logger.WithFields(logrus.Fields(fields))
 }
 
+// Redact marks given values as sensitive (e.g. secrets), so they get masked in all subsequent event log entries
+func (eventLog *Log) Redact(values ...string) {
+	eventLog.hookRedact.Add(values...)
+}
+
 // AttachTo attaches all entries in this event log to a certain object
 // E.g. dependency, user, contract, context, serviceKey
 func (eventLog *Log) AttachTo(object interface{}) {
@@ -134,6 +145,17 @@ func (eventLog *Log) LogWarning(err error) {
 	}
 }
 
+// GetMessages returns messages of all buffered event log entries with the given level or more severe
+func (eventLog *Log) GetMessages(level logrus.Level) []string {
+	result := []string{}
+	for _, e := range eventLog.hookMemory.entries {
+		if e.Level <= level {
+			result = append(result, e.Message)
+		}
+	}
+	return result
+}
+
 // Save takes all buffered event log entries and saves them
 func (eventLog *Log) Save(hook logrus.Hook) {
 	for _, e := range eventLog.hookMemory.entries {
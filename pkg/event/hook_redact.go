@@ -0,0 +1,81 @@
+package event
+
+import (
+	"fmt"
+	"github.com/Sirupsen/logrus"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// RedactedValue is what sensitive values are replaced with in event log entries
+const RedactedValue = "******"
+
+// MinRedactedLength is a minimal length of sensitive values, which get masked. Shorter values would mask the same
+// characters all over the event log, making it unreadable, while not hiding anything meaningful
+const MinRedactedLength = 3
+
+// HookRedact implements event log hook, which masks sensitive values (e.g. secrets) in event log entries. It should
+// be fired before all other hooks, so they never see sensitive values
+type HookRedact struct {
+	mutex  sync.RWMutex
+	values map[string]bool
+
+	// sorted contains the same values sorted from the longest to the shortest, so the value containing another
+	// value gets masked completely
+	sorted []string
+}
+
+// Levels defines on which log levels this hook should be fired
+func (hook *HookRedact) Levels() []logrus.Level {
+	return logrus.AllLevels
+}
+
+// Add adds sensitive values, which should be masked
+func (hook *HookRedact) Add(values ...string) {
+	hook.mutex.Lock()
+	defer hook.mutex.Unlock()
+	if hook.values == nil {
+		hook.values = make(map[string]bool)
+	}
+	for _, value := range values {
+		if len(value) >= MinRedactedLength && !hook.values[value] {
+			hook.values[value] = true
+			hook.sorted = append(hook.sorted, value)
+		}
+	}
+	sort.Slice(hook.sorted, func(i, j int) bool {
+		if len(hook.sorted[i]) != len(hook.sorted[j]) {
+			return len(hook.sorted[i]) > len(hook.sorted[j])
+		}
+		return hook.sorted[i] < hook.sorted[j]
+	})
+}
+
+// Fire processes a single log entry, masking sensitive values in its message and fields
+func (hook *HookRedact) Fire(e *logrus.Entry) error {
+	hook.mutex.RLock()
+	defer hook.mutex.RUnlock()
+	if len(hook.values) <= 0 {
+		return nil
+	}
+
+	e.Message = hook.redact(e.Message)
+	for key, value := range e.Data {
+		str, ok := value.(string)
+		if !ok {
+			str = fmt.Sprintf("%v", value)
+		}
+		if redacted := hook.redact(str); redacted != str {
+			e.Data[key] = redacted
+		}
+	}
+	return nil
+}
+
+func (hook *HookRedact) redact(str string) string {
+	for _, value := range hook.sorted {
+		str = strings.Replace(str, value, RedactedValue, -1)
+	}
+	return str
+}
@@ -0,0 +1,23 @@
+package event
+
+import (
+	"github.com/Sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestHookRedact(t *testing.T) {
+	hook := &HookRedact{}
+	hook.Add("secret", "my-secret-password", "x", "")
+
+	entry := &logrus.Entry{
+		Message: "password is my-secret-password, token is secret, x is not masked",
+		Data:    logrus.Fields{"password": "my-secret-password", "count": 42},
+	}
+	assert.NoError(t, hook.Fire(entry), "Hook should be fired")
+
+	// longer value containing another value should be masked completely
+	assert.Equal(t, "password is ******, token is ******, x is not masked", entry.Message)
+	assert.Equal(t, RedactedValue, entry.Data["password"])
+	assert.Equal(t, 42, entry.Data["count"], "Fields without sensitive values shouldn't be changed")
+}
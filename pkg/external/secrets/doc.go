@@ -1,2 +1,3 @@
-// Package secrets implements support for retrieving user Secrets from external sources (File).
+// Package secrets implements support for retrieving user, namespace and service Secrets from external sources
+// (plaintext and encrypted files, Vault-compatible HTTP secret stores and environment variables).
 package secrets
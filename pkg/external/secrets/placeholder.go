@@ -0,0 +1,205 @@
+package secrets
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/util"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Secrets are never exposed to templates directly. Instead, templates get placeholders, which refer to secrets by
+// their scope, owner and name. This way calculated parameters, event logs, errors and stored state only contain
+// placeholders, while actual secret values are substituted right before parameters are passed to code plugins
+
+const (
+	// ScopeUser is a scope of user secrets
+	ScopeUser = "user"
+
+	// ScopeNamespace is a scope of namespace secrets
+	ScopeNamespace = "namespace"
+
+	// ScopeService is a scope of service secrets
+	ScopeService = "service"
+)
+
+var placeholderRegex = regexp.MustCompile(`<secret:([a-z]+):([^:<>]+):([^:<>]+)>`)
+
+// Placeholder returns placeholder for a secret with a given scope, owner (user name, namespace or namespace/service)
+// and name
+func Placeholder(scope string, owner string, name string) string {
+	return fmt.Sprintf("<secret:%s:%s:%s>", scope, url.QueryEscape(owner), url.QueryEscape(name))
+}
+
+// PlaceholdersForUser returns a map of secret name -> placeholder for all secrets of a given user
+func PlaceholdersForUser(loader SecretLoader, user string) map[string]string {
+	result := make(map[string]string)
+	for name := range loader.LoadSecretsByUserName(user) {
+		result[name] = Placeholder(ScopeUser, user, name)
+	}
+	return result
+}
+
+// PlaceholdersForService returns a map of secret name -> placeholder for all secrets of a given service, including
+// secrets of its namespace (service secrets override namespace secrets with the same name)
+func PlaceholdersForService(loader SecretLoader, namespace string, service string) map[string]string {
+	result := make(map[string]string)
+	for name := range loader.LoadSecretsByNamespace(namespace) {
+		result[name] = Placeholder(ScopeNamespace, namespace, name)
+	}
+	for name := range loader.LoadSecretsByService(namespace, service) {
+		result[name] = Placeholder(ScopeService, serviceKey(namespace, service), name)
+	}
+	return result
+}
+
+// Owners defines whose secrets are allowed to be revealed. Placeholders are just strings, so anyone who controls
+// templates can write a placeholder for a secret of any user, namespace or service. Only placeholders of the users,
+// who consume component instance, and of its own namespace and service are revealed
+type Owners struct {
+	// Users is a set of user names (user name -> true)
+	Users map[string]bool
+
+	// Namespace is a namespace, which secrets are allowed to be revealed
+	Namespace string
+
+	// Service is a name of the service in Namespace, which secrets are allowed to be revealed
+	Service string
+}
+
+// NewOwners creates a new set of secret owners for a given namespace, service and users
+func NewOwners(namespace string, service string, users ...string) *Owners {
+	owners := &Owners{
+		Users:     make(map[string]bool),
+		Namespace: namespace,
+		Service:   service,
+	}
+	for _, user := range users {
+		owners.Users[strings.ToLower(user)] = true
+	}
+	return owners
+}
+
+// allows returns true if secrets of a given owner within a given scope are allowed to be revealed
+func (owners *Owners) allows(scope string, owner string) bool {
+	switch scope {
+	case ScopeUser:
+		return owners.Users[strings.ToLower(owner)]
+	case ScopeNamespace:
+		return owner == owners.Namespace
+	case ScopeService:
+		return owner == serviceKey(owners.Namespace, owners.Service)
+	}
+	return false
+}
+
+// Reveal returns a copy of parameters, where all secret placeholders are replaced with actual secret values. Only
+// placeholders of the given owners are revealed, any other placeholder results in error. It also returns the list of
+// revealed values, so they can be masked if they end up in logs
+func Reveal(params util.NestedParameterMap, loader SecretLoader, owners *Owners) (util.NestedParameterMap, []string, error) {
+	if params == nil {
+		return nil, nil, nil
+	}
+
+	revealer := &revealer{loader: loader, owners: owners}
+	result, err := revealer.revealValue(params)
+	if err != nil {
+		return nil, nil, err
+	}
+	return result.(util.NestedParameterMap), revealer.revealed, nil
+}
+
+type revealer struct {
+	loader   SecretLoader
+	owners   *Owners
+	revealed []string
+}
+
+func (r *revealer) revealValue(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return r.revealString(v)
+	case util.NestedParameterMap:
+		result := util.NestedParameterMap{}
+		for key, item := range v {
+			revealed, err := r.revealValue(item)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = revealed
+		}
+		return result, nil
+	case map[string]interface{}:
+		result := make(map[string]interface{})
+		for key, item := range v {
+			revealed, err := r.revealValue(item)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = revealed
+		}
+		return result, nil
+	case []interface{}:
+		result := make([]interface{}, len(v))
+		for idx, item := range v {
+			revealed, err := r.revealValue(item)
+			if err != nil {
+				return nil, err
+			}
+			result[idx] = revealed
+		}
+		return result, nil
+	}
+	return value, nil
+}
+
+func (r *revealer) revealString(str string) (string, error) {
+	var err error
+	result := placeholderRegex.ReplaceAllStringFunc(str, func(placeholder string) string {
+		match := placeholderRegex.FindStringSubmatch(placeholder)
+		value, errSecret := r.secretValue(match[1], match[2], match[3])
+		if errSecret != nil {
+			err = errSecret
+			return placeholder
+		}
+		r.revealed = append(r.revealed, value)
+		return value
+	})
+	return result, err
+}
+
+func (r *revealer) secretValue(scope string, ownerEscaped string, nameEscaped string) (string, error) {
+	owner, err := url.QueryUnescape(ownerEscaped)
+	if err != nil {
+		return "", fmt.Errorf("invalid secret owner '%s': %s", ownerEscaped, err)
+	}
+	name, err := url.QueryUnescape(nameEscaped)
+	if err != nil {
+		return "", fmt.Errorf("invalid secret name '%s': %s", nameEscaped, err)
+	}
+	if !r.owners.allows(scope, owner) {
+		return "", fmt.Errorf("secret '%s' (%s '%s') doesn't belong to component instance consumers, namespace or service", name, scope, owner)
+	}
+
+	var secrets map[string]string
+	switch scope {
+	case ScopeUser:
+		secrets = r.loader.LoadSecretsByUserName(owner)
+	case ScopeNamespace:
+		secrets = r.loader.LoadSecretsByNamespace(owner)
+	case ScopeService:
+		parts := strings.SplitN(owner, "/", 2)
+		if len(parts) != 2 {
+			return "", fmt.Errorf("invalid service secret owner '%s'", owner)
+		}
+		secrets = r.loader.LoadSecretsByService(parts[0], parts[1])
+	default:
+		return "", fmt.Errorf("unknown secret scope '%s'", scope)
+	}
+
+	value, ok := secrets[name]
+	if !ok {
+		return "", fmt.Errorf("secret '%s' not found (%s '%s')", name, scope, owner)
+	}
+	return value, nil
+}
@@ -0,0 +1,49 @@
+package secrets
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ScopedSecrets represents a set of secrets, which belong either to a user, to a namespace or to a service
+// in a namespace
+type ScopedSecrets struct {
+	User      string `yaml:",omitempty"`
+	Namespace string `yaml:",omitempty"`
+	Service   string `yaml:",omitempty"`
+	Secrets   map[string]string
+}
+
+// secretsIndex holds secrets grouped by their scope
+type secretsIndex struct {
+	users      map[string]map[string]string
+	namespaces map[string]map[string]string
+	services   map[string]map[string]string
+}
+
+func newSecretsIndex() *secretsIndex {
+	return &secretsIndex{
+		users:      make(map[string]map[string]string),
+		namespaces: make(map[string]map[string]string),
+		services:   make(map[string]map[string]string),
+	}
+}
+
+// add adds a set of secrets into the index. If the index already has secrets for the same scope, they get replaced
+func (index *secretsIndex) add(secrets *ScopedSecrets) error {
+	switch {
+	case len(secrets.User) > 0 && len(secrets.Namespace) <= 0 && len(secrets.Service) <= 0:
+		index.users[strings.ToLower(secrets.User)] = secrets.Secrets
+	case len(secrets.User) <= 0 && len(secrets.Namespace) > 0 && len(secrets.Service) <= 0:
+		index.namespaces[secrets.Namespace] = secrets.Secrets
+	case len(secrets.User) <= 0 && len(secrets.Namespace) > 0 && len(secrets.Service) > 0:
+		index.services[serviceKey(secrets.Namespace, secrets.Service)] = secrets.Secrets
+	default:
+		return fmt.Errorf("secrets should belong either to a user, to a namespace or to a service in a namespace (user: '%s', namespace: '%s', service: '%s')", secrets.User, secrets.Namespace, secrets.Service)
+	}
+	return nil
+}
+
+func serviceKey(namespace string, service string) string {
+	return namespace + "/" + service
+}
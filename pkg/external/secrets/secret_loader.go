@@ -1,8 +1,14 @@
 package secrets
 
-// SecretLoader is an interface which allows aptomi to load secrets for users
+// SecretLoader is an interface which allows aptomi to load secrets for users, namespaces and services
 // from different sources (e.g. file, external store, etc)
 type SecretLoader interface {
 	// LoadSecretsByUserName should load a set of secrets for a given user
 	LoadSecretsByUserName(string) map[string]string
+
+	// LoadSecretsByNamespace should load a set of secrets for a given namespace
+	LoadSecretsByNamespace(string) map[string]string
+
+	// LoadSecretsByService should load a set of secrets for a given service in a given namespace
+	LoadSecretsByService(namespace string, service string) map[string]string
 }
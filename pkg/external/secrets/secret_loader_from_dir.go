@@ -9,48 +9,52 @@ import (
 	"path/filepath"
 	"sort"
 	"strings"
-	"sync"
 	"time"
 )
 
-// SecretLoaderFromDir allows to load secrets for users from a given directory
+// SecretLoaderFromDir allows to load secrets from plaintext secrets*.yaml files in a given directory
 type SecretLoaderFromDir struct {
 	baseDir string
 	cache   *cache.Cache
 }
 
-// UserSecrets represents a single user secret (user name and a map of secrets)
-type UserSecrets struct {
-	User    string
-	Secrets map[string]string
-}
-
-// NewSecretLoaderFromDir returns new UserLoaderFromDir, given a directory where files should be read from
-func NewSecretLoaderFromDir(baseDir string) SecretLoader {
+// NewSecretLoaderFromDir returns new SecretLoaderFromDir, given a directory where files should be read from
+func NewSecretLoaderFromDir(baseDir string) *SecretLoaderFromDir {
 	return &SecretLoaderFromDir{
 		baseDir: baseDir,
 		cache:   cache.New(time.Minute, time.Minute),
 	}
 }
 
-// LoadSecretsAll loads all secrets
+// LoadSecretsAll loads secrets of all users
 func (loader *SecretLoaderFromDir) LoadSecretsAll() map[string]map[string]string {
-	// this can be called concurrently by the engine, so it needs to be thread safe
-	cachedSecrets, _ := loader.cache.Get("secrets")
-	if cachedSecrets != nil {
-		return cachedSecrets.(map[string]map[string]string)
-	}
+	return loader.loadIndex().users
+}
+
+// LoadSecretsByUserName loads secrets for a single user
+func (loader *SecretLoaderFromDir) LoadSecretsByUserName(user string) map[string]string {
+	return loader.loadIndex().users[strings.ToLower(user)]
+}
 
-	// synchronize and retrieve secrets
-	mutex := sync.Mutex{}
-	mutex.Lock()
-	defer func() { mutex.Unlock() }()
+// LoadSecretsByNamespace loads secrets for a given namespace
+func (loader *SecretLoaderFromDir) LoadSecretsByNamespace(namespace string) map[string]string {
+	return loader.loadIndex().namespaces[namespace]
+}
+
+// LoadSecretsByService loads secrets for a given service in a given namespace
+func (loader *SecretLoaderFromDir) LoadSecretsByService(namespace string, service string) map[string]string {
+	return loader.loadIndex().services[serviceKey(namespace, service)]
+}
 
-	// retrieve secrets
-	result := make(map[string]map[string]string)
+func (loader *SecretLoaderFromDir) loadIndex() *secretsIndex {
+	// this can be called concurrently by the engine, so it needs to be thread safe
+	cachedIndex, _ := loader.cache.Get("secrets")
+	if cachedIndex != nil {
+		return cachedIndex.(*secretsIndex)
+	}
 
+	result := newSecretsIndex()
 	if len(loader.baseDir) <= 0 {
-		// log.Warnf("Skip loading secrets because baseDir not specified")
 		return result
 	}
 
@@ -62,9 +66,11 @@ func (loader *SecretLoaderFromDir) LoadSecretsAll() map[string]map[string]string
 
 	sort.Strings(files)
 	for _, f := range files {
-		secrets := loadUserSecretsFromFile(f)
-		for _, secret := range secrets {
-			result[strings.ToLower(secret.User)] = secret.Secrets
+		for _, secrets := range loadSecretsFromFile(f) {
+			err = result.add(secrets)
+			if err != nil {
+				panic(fmt.Errorf("error while loading secrets from file %s: %s", f, err))
+			}
 		}
 	}
 
@@ -72,13 +78,8 @@ func (loader *SecretLoaderFromDir) LoadSecretsAll() map[string]map[string]string
 	return result
 }
 
-// LoadSecretsByUserName loads secrets for a single user
-func (loader *SecretLoaderFromDir) LoadSecretsByUserName(user string) map[string]string {
-	return loader.LoadSecretsAll()[strings.ToLower(user)]
-}
-
 // Loads secrets from file
-func loadUserSecretsFromFile(fileName string) []*UserSecrets {
+func loadSecretsFromFile(fileName string) []*ScopedSecrets {
 	log.Debugf("Loading secrets from file: %s", fileName)
-	return *yaml.LoadObjectFromFileDefaultEmpty(fileName, &[]*UserSecrets{}).(*[]*UserSecrets)
+	return *yaml.LoadObjectFromFileDefaultEmpty(fileName, &[]*ScopedSecrets{}).(*[]*ScopedSecrets)
 }
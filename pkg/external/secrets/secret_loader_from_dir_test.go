@@ -34,3 +34,12 @@ func TestLoadSecrets(t *testing.T) {
 		assert.Equal(t, "bigsecretvalue", secrets["bigsecret"])
 	}
 }
+
+func TestLoadScopedSecrets(t *testing.T) {
+	secretLoader := NewSecretLoaderFromDir("../../testdata/unittests")
+
+	assert.Equal(t, map[string]string{"dbPassword": "namespacedbpassword", "region": "us-east"}, secretLoader.LoadSecretsByNamespace("main"))
+	assert.Equal(t, map[string]string{"dbPassword": "servicedbpassword"}, secretLoader.LoadSecretsByService("main", "twitter-stats"))
+	assert.Empty(t, secretLoader.LoadSecretsByNamespace("other"))
+	assert.Empty(t, secretLoader.LoadSecretsByService("main", "other"))
+}
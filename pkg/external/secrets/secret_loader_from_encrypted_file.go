@@ -0,0 +1,155 @@
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/config"
+	log "github.com/Sirupsen/logrus"
+	"github.com/patrickmn/go-cache"
+	"gopkg.in/yaml.v2"
+	"io"
+	"io/ioutil"
+	"strings"
+	"time"
+)
+
+// SecretLoaderFromEncryptedFile allows to load secrets from the file encrypted with AES-256-GCM. Decrypted file has
+// the same format as plaintext secrets*.yaml files
+type SecretLoaderFromEncryptedFile struct {
+	cfg   config.EncryptedSecrets
+	cache *cache.Cache
+}
+
+// NewSecretLoaderFromEncryptedFile returns new SecretLoaderFromEncryptedFile, given a file and keys to decrypt it
+func NewSecretLoaderFromEncryptedFile(cfg config.EncryptedSecrets) *SecretLoaderFromEncryptedFile {
+	return &SecretLoaderFromEncryptedFile{
+		cfg:   cfg,
+		cache: cache.New(time.Minute, time.Minute),
+	}
+}
+
+// LoadSecretsByUserName loads secrets for a single user
+func (loader *SecretLoaderFromEncryptedFile) LoadSecretsByUserName(user string) map[string]string {
+	return loader.loadIndex().users[strings.ToLower(user)]
+}
+
+// LoadSecretsByNamespace loads secrets for a given namespace
+func (loader *SecretLoaderFromEncryptedFile) LoadSecretsByNamespace(namespace string) map[string]string {
+	return loader.loadIndex().namespaces[namespace]
+}
+
+// LoadSecretsByService loads secrets for a given service in a given namespace
+func (loader *SecretLoaderFromEncryptedFile) LoadSecretsByService(namespace string, service string) map[string]string {
+	return loader.loadIndex().services[serviceKey(namespace, service)]
+}
+
+func (loader *SecretLoaderFromEncryptedFile) loadIndex() *secretsIndex {
+	// this can be called concurrently by the engine, so it needs to be thread safe
+	cachedIndex, _ := loader.cache.Get("secrets")
+	if cachedIndex != nil {
+		return cachedIndex.(*secretsIndex)
+	}
+
+	log.Debugf("Loading encrypted secrets from file: %s", loader.cfg.File)
+	data, err := ioutil.ReadFile(loader.cfg.File)
+	if err != nil {
+		panic(fmt.Errorf("unable to read encrypted secrets file '%s': %s", loader.cfg.File, err))
+	}
+
+	var plaintext []byte
+	for _, key := range loader.cfg.Keys {
+		plaintext, err = DecryptSecrets(data, key)
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		panic(fmt.Errorf("unable to decrypt secrets file '%s' with any of the configured keys: %s", loader.cfg.File, err))
+	}
+
+	secrets := []*ScopedSecrets{}
+	err = yaml.Unmarshal(plaintext, &secrets)
+	if err != nil {
+		panic(fmt.Errorf("unable to unmarshal secrets from file '%s': %s", loader.cfg.File, err))
+	}
+
+	result := newSecretsIndex()
+	for _, s := range secrets {
+		err = result.add(s)
+		if err != nil {
+			panic(fmt.Errorf("error while loading secrets from file %s: %s", loader.cfg.File, err))
+		}
+	}
+
+	loader.cache.Set("secrets", result, cache.DefaultExpiration)
+	return result
+}
+
+// GenerateSecretsKey generates a new random base64-encoded 256-bit key for encrypting secrets
+func GenerateSecretsKey() (string, error) {
+	key := make([]byte, 32)
+	_, err := io.ReadFull(rand.Reader, key)
+	if err != nil {
+		return "", fmt.Errorf("error while generating key: %s", err)
+	}
+	return base64.StdEncoding.EncodeToString(key), nil
+}
+
+// EncryptSecrets encrypts plaintext secrets with a given base64-encoded key. Result is base64-encoded nonce followed
+// by the ciphertext
+func EncryptSecrets(plaintext []byte, key string) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	_, err = io.ReadFull(rand.Reader, nonce)
+	if err != nil {
+		return nil, fmt.Errorf("error while generating nonce: %s", err)
+	}
+
+	sealed := gcm.Seal(nonce, nonce, plaintext, nil)
+	return []byte(base64.StdEncoding.EncodeToString(sealed)), nil
+}
+
+// DecryptSecrets decrypts secrets encrypted by EncryptSecrets with a given base64-encoded key
+func DecryptSecrets(data []byte, key string) ([]byte, error) {
+	gcm, err := newGCM(key)
+	if err != nil {
+		return nil, err
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("error while decoding encrypted secrets: %s", err)
+	}
+	if len(sealed) < gcm.NonceSize() {
+		return nil, fmt.Errorf("encrypted secrets are too short")
+	}
+
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while decrypting secrets: %s", err)
+	}
+	return plaintext, nil
+}
+
+func newGCM(key string) (cipher.AEAD, error) {
+	keyBytes, err := base64.StdEncoding.DecodeString(key)
+	if err != nil {
+		return nil, fmt.Errorf("error while decoding key: %s", err)
+	}
+	if len(keyBytes) != 32 {
+		return nil, fmt.Errorf("key should be 256 bits long, but it's %d bits long", len(keyBytes)*8)
+	}
+
+	block, err := aes.NewCipher(keyBytes)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,45 @@
+package secrets
+
+import (
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoadSecretsFromEncryptedFile(t *testing.T) {
+	oldKey, err := GenerateSecretsKey()
+	assert.NoError(t, err, "Key should be generated")
+	key, err := GenerateSecretsKey()
+	assert.NoError(t, err, "Key should be generated")
+
+	plaintext, err := ioutil.ReadFile("../../testdata/unittests/secrets.yaml")
+	assert.NoError(t, err, "Plaintext secrets should be read")
+	encrypted, err := EncryptSecrets(plaintext, key)
+	assert.NoError(t, err, "Secrets should be encrypted")
+
+	_, err = DecryptSecrets(encrypted, oldKey)
+	assert.Error(t, err, "Secrets should not be decrypted with a wrong key")
+
+	dir, err := ioutil.TempDir("", "aptomi-secrets")
+	if !assert.NoError(t, err, "Temp dir should be created") {
+		return
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	file := filepath.Join(dir, "secrets.enc")
+	err = ioutil.WriteFile(file, encrypted, 0600)
+	assert.NoError(t, err, "Encrypted secrets should be written")
+
+	// file should be decrypted with any of the configured keys
+	secretLoader := NewSecretLoaderFromEncryptedFile(config.EncryptedSecrets{File: file, Keys: []string{oldKey, key}})
+	assert.Equal(t, "aliceappkey", secretLoader.LoadSecretsByUserName("Alice")["twitterAppKey"])
+	assert.Equal(t, "namespacedbpassword", secretLoader.LoadSecretsByNamespace("main")["dbPassword"])
+	assert.Equal(t, "servicedbpassword", secretLoader.LoadSecretsByService("main", "twitter-stats")["dbPassword"])
+
+	// loader should panic if file can't be decrypted
+	secretLoader = NewSecretLoaderFromEncryptedFile(config.EncryptedSecrets{File: file, Keys: []string{oldKey}})
+	assert.Panics(t, func() { secretLoader.LoadSecretsByUserName("Alice") }, "Loader should panic if file can't be decrypted")
+}
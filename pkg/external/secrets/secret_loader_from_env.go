@@ -0,0 +1,75 @@
+package secrets
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// SecretLoaderFromEnv allows to load secrets from environment variables, where each variable contains a JSON object
+// with secrets for a user, a namespace or a service
+type SecretLoaderFromEnv struct {
+	prefix string
+}
+
+// NewSecretLoaderFromEnv returns new SecretLoaderFromEnv, given a prefix of environment variables
+func NewSecretLoaderFromEnv(cfg config.EnvSecrets) *SecretLoaderFromEnv {
+	prefix := cfg.Prefix
+	if len(prefix) <= 0 {
+		prefix = "APTOMI_SECRETS_"
+	}
+	return &SecretLoaderFromEnv{prefix: prefix}
+}
+
+// LoadSecretsByUserName loads secrets for a single user
+func (loader *SecretLoaderFromEnv) LoadSecretsByUserName(user string) map[string]string {
+	return loader.loadSecrets("USER", user)
+}
+
+// LoadSecretsByNamespace loads secrets for a given namespace
+func (loader *SecretLoaderFromEnv) LoadSecretsByNamespace(namespace string) map[string]string {
+	return loader.loadSecrets("NAMESPACE", namespace)
+}
+
+// LoadSecretsByService loads secrets for a given service in a given namespace
+func (loader *SecretLoaderFromEnv) LoadSecretsByService(namespace string, service string) map[string]string {
+	return loader.loadSecrets("SERVICE", namespace, service)
+}
+
+// envNameSeparator separates names (e.g. namespace and service) in environment variable names. Names can't contain
+// consecutive underscores after conversion, so the separator is unambiguous
+const envNameSeparator = "__"
+
+// envNamePattern matches names, which can be converted to environment variable names unambiguously
+var envNamePattern = regexp.MustCompile("^[A-Za-z0-9]+(-[A-Za-z0-9]+)*$")
+
+func (loader *SecretLoaderFromEnv) loadSecrets(scope string, names ...string) map[string]string {
+	envNames := []string{}
+	for _, name := range names {
+		// secrets for names, which can't be converted unambiguously, can't be stored in environment variables
+		if !envNamePattern.MatchString(name) {
+			return nil
+		}
+		envNames = append(envNames, envName(name))
+	}
+	variable := loader.prefix + scope + "_" + strings.Join(envNames, envNameSeparator)
+
+	value, ok := os.LookupEnv(variable)
+	if !ok {
+		return nil
+	}
+
+	result, err := decodeSecretsJSON([]byte(value))
+	if err != nil {
+		panic(fmt.Errorf("error while loading secrets from environment variable %s: %s", variable, err))
+	}
+	return result
+}
+
+// envName converts name to the form used in environment variable names, i.e. converts it to upper case and replaces
+// '-' with '_'. Names are not case sensitive, so only names matching envNamePattern are converted unambiguously
+func envName(name string) string {
+	return strings.ToUpper(strings.Replace(name, "-", "_", -1))
+}
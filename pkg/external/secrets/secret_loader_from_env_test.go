@@ -0,0 +1,37 @@
+package secrets
+
+import (
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"testing"
+)
+
+func TestLoadSecretsFromEnv(t *testing.T) {
+	env := map[string]string{
+		"TEST_SECRETS_USER_ALICE":                      `{"twitterAppKey": "aliceappkey"}`,
+		"TEST_SECRETS_NAMESPACE_MAIN":                  `{"dbPassword": "namespacedbpassword"}`,
+		"TEST_SECRETS_SERVICE_MAIN__TWITTER_STATS":     `{"dbPassword": "servicedbpassword"}`,
+		"TEST_SECRETS_SERVICE_MAIN__TWITTER_ANALYTICS": `invalid`,
+		"TEST_SECRETS_USER_JOHN_SMITH":                 `{"twitterAppKey": "johnappkey"}`,
+	}
+	for name, value := range env {
+		assert.NoError(t, os.Setenv(name, value), "Environment variable should be set")
+		defer os.Unsetenv(name) // nolint: errcheck
+	}
+
+	secretLoader := NewSecretLoaderFromEnv(config.EnvSecrets{Prefix: "TEST_SECRETS_"})
+	assert.Equal(t, map[string]string{"twitterAppKey": "aliceappkey"}, secretLoader.LoadSecretsByUserName("Alice"))
+	assert.Equal(t, map[string]string{"dbPassword": "namespacedbpassword"}, secretLoader.LoadSecretsByNamespace("main"))
+	assert.Equal(t, map[string]string{"dbPassword": "servicedbpassword"}, secretLoader.LoadSecretsByService("main", "twitter-stats"))
+	assert.Empty(t, secretLoader.LoadSecretsByUserName("Bob"))
+
+	// names, which map to the same variable name, shouldn't get each other secrets
+	assert.Empty(t, secretLoader.LoadSecretsByService("main-twitter", "stats"), "Service secrets should be separated from namespace")
+	assert.Empty(t, secretLoader.LoadSecretsByService("main_twitter", "stats"), "Names with '_' shouldn't be loaded")
+	assert.Equal(t, map[string]string{"twitterAppKey": "johnappkey"}, secretLoader.LoadSecretsByUserName("john-smith"))
+	assert.Empty(t, secretLoader.LoadSecretsByUserName("john_smith"), "Names with '_' shouldn't be loaded")
+	assert.Empty(t, secretLoader.LoadSecretsByUserName("john smith"), "Names with spaces shouldn't be loaded")
+	assert.Empty(t, secretLoader.LoadSecretsByUserName("john--smith"), "Names with consecutive '-' shouldn't be loaded")
+	assert.Panics(t, func() { secretLoader.LoadSecretsByService("main", "twitter-analytics") }, "Loader should panic on invalid secrets")
+}
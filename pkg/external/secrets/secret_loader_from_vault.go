@@ -0,0 +1,160 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/patrickmn/go-cache"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SecretLoaderFromVault allows to load secrets from Vault-compatible HTTP secret store, using key/value secrets engine
+type SecretLoaderFromVault struct {
+	cfg        config.VaultSecrets
+	token      string
+	httpClient *http.Client
+	cache      *cache.Cache
+}
+
+// NewSecretLoaderFromVault returns new SecretLoaderFromVault, given the secret store config
+func NewSecretLoaderFromVault(cfg config.VaultSecrets) (*SecretLoaderFromVault, error) {
+	token := cfg.Token
+	if len(token) <= 0 && len(cfg.TokenFile) > 0 {
+		data, err := ioutil.ReadFile(cfg.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read Vault token file '%s': %s", cfg.TokenFile, err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+	if len(cfg.Mount) <= 0 {
+		cfg.Mount = "secret"
+	}
+	if len(cfg.Path) <= 0 {
+		cfg.Path = "aptomi"
+	}
+	if cfg.KVVersion <= 0 {
+		cfg.KVVersion = 1
+	}
+
+	return &SecretLoaderFromVault{
+		cfg:        cfg,
+		token:      token,
+		httpClient: &http.Client{Timeout: 10 * time.Second},
+		cache:      cache.New(time.Minute, time.Minute),
+	}, nil
+}
+
+// LoadSecretsByUserName loads secrets for a single user
+func (loader *SecretLoaderFromVault) LoadSecretsByUserName(user string) map[string]string {
+	return loader.loadSecrets("users", strings.ToLower(user))
+}
+
+// LoadSecretsByNamespace loads secrets for a given namespace
+func (loader *SecretLoaderFromVault) LoadSecretsByNamespace(namespace string) map[string]string {
+	return loader.loadSecrets("namespaces", namespace)
+}
+
+// LoadSecretsByService loads secrets for a given service in a given namespace
+func (loader *SecretLoaderFromVault) LoadSecretsByService(namespace string, service string) map[string]string {
+	return loader.loadSecrets("services", namespace, service)
+}
+
+// loadSecrets reads secrets from a given path under the configured path. Missing secrets are treated as empty
+func (loader *SecretLoaderFromVault) loadSecrets(pathElems ...string) map[string]string {
+	path := loader.cfg.Path
+	for _, elem := range pathElems {
+		path += "/" + url.PathEscape(elem)
+	}
+
+	// this can be called concurrently by the engine, so it needs to be thread safe
+	cachedSecrets, found := loader.cache.Get(path)
+	if found {
+		return cachedSecrets.(map[string]string)
+	}
+
+	result, err := loader.read(path)
+	if err != nil {
+		// we need secrets, but they cannot be loaded. for now, let's panic (same as for other external data)
+		panic(err)
+	}
+
+	loader.cache.Set(path, result, cache.DefaultExpiration)
+	return result
+}
+
+func (loader *SecretLoaderFromVault) read(path string) (map[string]string, error) {
+	mountPath := loader.cfg.Mount
+	if loader.cfg.KVVersion == 2 {
+		mountPath += "/data"
+	}
+	address := strings.TrimRight(loader.cfg.Address, "/") + "/v1/" + mountPath + "/" + path
+
+	req, err := http.NewRequest("GET", address, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error while creating request to Vault: %s", err)
+	}
+	if len(loader.token) > 0 {
+		req.Header.Set("X-Vault-Token", loader.token)
+	}
+
+	resp, err := loader.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading secrets from Vault '%s': %s", path, err)
+	}
+	defer resp.Body.Close() // nolint: errcheck
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("error while reading secrets from Vault '%s': %s", path, resp.Status)
+	}
+
+	body := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	err = json.NewDecoder(resp.Body).Decode(&body)
+	if err != nil {
+		return nil, fmt.Errorf("error while decoding secrets from Vault '%s': %s", path, err)
+	}
+
+	data := body.Data
+	if loader.cfg.KVVersion == 2 {
+		// key/value secrets engine v2 wraps secrets into one more data field, along with metadata
+		dataV2 := struct {
+			Data json.RawMessage `json:"data"`
+		}{}
+		err = json.Unmarshal(data, &dataV2)
+		if err != nil {
+			return nil, fmt.Errorf("error while decoding secrets from Vault '%s': %s", path, err)
+		}
+		data = dataV2.Data
+	}
+
+	return decodeSecretsJSON(data)
+}
+
+// decodeSecretsJSON decodes JSON object with secrets. Non-string values are converted to strings
+func decodeSecretsJSON(data []byte) (map[string]string, error) {
+	values := make(map[string]interface{})
+	if len(data) > 0 {
+		err := json.Unmarshal(data, &values)
+		if err != nil {
+			return nil, fmt.Errorf("secrets should be a JSON object: %s", err)
+		}
+	}
+
+	result := make(map[string]string)
+	for name, value := range values {
+		if str, ok := value.(string); ok {
+			result[name] = str
+		} else {
+			result[name] = fmt.Sprintf("%v", value)
+		}
+	}
+	return result, nil
+}
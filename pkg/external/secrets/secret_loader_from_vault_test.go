@@ -0,0 +1,72 @@
+package secrets
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newVaultStandIn returns a local stand-in for Vault, which serves a fixed set of secrets for a given key/value
+// secrets engine version
+func newVaultStandIn(kvVersion int) *httptest.Server {
+	secrets := map[string]string{
+		"aptomi/users/alice":                     `{"twitterAppKey": "aliceappkey", "port": 8080}`,
+		"aptomi/namespaces/main":                 `{"dbPassword": "namespacedbpassword"}`,
+		"aptomi/services/main/twitter-stats":     `{"dbPassword": "servicedbpassword"}`,
+		"aptomi/services/main/twitter%2Fbroken":  `not a json`,
+		"aptomi/services/main/twitter-analytics": `{}`,
+	}
+	prefix := "/v1/secret/"
+	if kvVersion == 2 {
+		prefix = "/v1/secret/data/"
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.Header.Get("X-Vault-Token") != "token" {
+			writer.WriteHeader(http.StatusForbidden)
+			return
+		}
+		path := request.URL.EscapedPath()
+		if len(path) < len(prefix) || path[:len(prefix)] != prefix {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		data, ok := secrets[path[len(prefix):]]
+		if !ok {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if kvVersion == 2 {
+			data = fmt.Sprintf(`{"data": %s, "metadata": {"version": 1}}`, data)
+		}
+		fmt.Fprintf(writer, `{"data": %s}`, data) // nolint: errcheck
+	}))
+}
+
+func TestLoadSecretsFromVault(t *testing.T) {
+	for _, kvVersion := range []int{1, 2} {
+		server := newVaultStandIn(kvVersion)
+
+		secretLoader, err := NewSecretLoaderFromVault(config.VaultSecrets{Address: server.URL, Token: "token", KVVersion: kvVersion})
+		if !assert.NoError(t, err, "Vault secret loader should be created") {
+			server.Close()
+			continue
+		}
+
+		assert.Equal(t, map[string]string{"twitterAppKey": "aliceappkey", "port": "8080"}, secretLoader.LoadSecretsByUserName("Alice"), "User secrets should be loaded (KV v%d)", kvVersion)
+		assert.Equal(t, map[string]string{"dbPassword": "namespacedbpassword"}, secretLoader.LoadSecretsByNamespace("main"), "Namespace secrets should be loaded (KV v%d)", kvVersion)
+		assert.Equal(t, map[string]string{"dbPassword": "servicedbpassword"}, secretLoader.LoadSecretsByService("main", "twitter-stats"), "Service secrets should be loaded (KV v%d)", kvVersion)
+		assert.Empty(t, secretLoader.LoadSecretsByUserName("Bob"), "Missing secrets should be empty (KV v%d)", kvVersion)
+		assert.Empty(t, secretLoader.LoadSecretsByService("main", "twitter-analytics"), "Empty secrets should be empty (KV v%d)", kvVersion)
+		assert.Panics(t, func() { secretLoader.LoadSecretsByService("main", "twitter/broken") }, "Loader should panic on invalid secrets (KV v%d)", kvVersion)
+
+		// loader should panic if it's not allowed to access secrets
+		secretLoader, _ = NewSecretLoaderFromVault(config.VaultSecrets{Address: server.URL, Token: "wrong", KVVersion: kvVersion})
+		assert.Panics(t, func() { secretLoader.LoadSecretsByUserName("Alice") }, "Loader should panic if access is denied (KV v%d)", kvVersion)
+
+		server.Close()
+	}
+}
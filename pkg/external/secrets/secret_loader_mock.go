@@ -1,20 +1,34 @@
 package secrets
 
-// SecretLoaderMock allows to mock secret loader and use in-memory user storage
+// SecretLoaderMock allows to mock secret loader and use in-memory secret storage
 type SecretLoaderMock struct {
-	secrets map[string]map[string]string
+	secrets          map[string]map[string]string
+	namespaceSecrets map[string]map[string]string
+	serviceSecrets   map[string]map[string]string
 }
 
 // NewSecretLoaderMock returns new SecretLoaderMock
 func NewSecretLoaderMock() *SecretLoaderMock {
 	return &SecretLoaderMock{
-		secrets: make(map[string]map[string]string),
+		secrets:          make(map[string]map[string]string),
+		namespaceSecrets: make(map[string]map[string]string),
+		serviceSecrets:   make(map[string]map[string]string),
 	}
 }
 
 // AddSecret adds a secret for a given user
 func (loader *SecretLoaderMock) AddSecret(userName string, secretName string, secretValue string) {
-	loader.secrets[userName][secretName] = secretValue
+	addMockSecret(loader.secrets, userName, secretName, secretValue)
+}
+
+// AddNamespaceSecret adds a secret for a given namespace
+func (loader *SecretLoaderMock) AddNamespaceSecret(namespace string, secretName string, secretValue string) {
+	addMockSecret(loader.namespaceSecrets, namespace, secretName, secretValue)
+}
+
+// AddServiceSecret adds a secret for a given service in a given namespace
+func (loader *SecretLoaderMock) AddServiceSecret(namespace string, service string, secretName string, secretValue string) {
+	addMockSecret(loader.serviceSecrets, serviceKey(namespace, service), secretName, secretValue)
 }
 
 // LoadSecretsAll loads all secrets
@@ -26,3 +40,20 @@ func (loader *SecretLoaderMock) LoadSecretsAll() map[string]map[string]string {
 func (loader *SecretLoaderMock) LoadSecretsByUserName(userName string) map[string]string {
 	return loader.secrets[userName]
 }
+
+// LoadSecretsByNamespace loads secrets for a given namespace
+func (loader *SecretLoaderMock) LoadSecretsByNamespace(namespace string) map[string]string {
+	return loader.namespaceSecrets[namespace]
+}
+
+// LoadSecretsByService loads secrets for a given service in a given namespace
+func (loader *SecretLoaderMock) LoadSecretsByService(namespace string, service string) map[string]string {
+	return loader.serviceSecrets[serviceKey(namespace, service)]
+}
+
+func addMockSecret(secrets map[string]map[string]string, key string, secretName string, secretValue string) {
+	if _, ok := secrets[key]; !ok {
+		secrets[key] = make(map[string]string)
+	}
+	secrets[key][secretName] = secretValue
+}
@@ -0,0 +1,50 @@
+package secrets
+
+// SecretLoaderMultipleSources allows to combine different secret sources into a single loader. Secrets from all
+// sources get merged, with later sources overriding secrets with the same name from the earlier ones
+type SecretLoaderMultipleSources struct {
+	loaders []SecretLoader
+}
+
+// NewSecretLoaderMultipleSources returns new SecretLoaderMultipleSources
+func NewSecretLoaderMultipleSources(loaders []SecretLoader) *SecretLoaderMultipleSources {
+	return &SecretLoaderMultipleSources{loaders: loaders}
+}
+
+// LoadSecretsByUserName loads secrets for a single user
+func (loader *SecretLoaderMultipleSources) LoadSecretsByUserName(user string) map[string]string {
+	return loader.merge(func(l SecretLoader) map[string]string {
+		return l.LoadSecretsByUserName(user)
+	})
+}
+
+// LoadSecretsByNamespace loads secrets for a given namespace
+func (loader *SecretLoaderMultipleSources) LoadSecretsByNamespace(namespace string) map[string]string {
+	return loader.merge(func(l SecretLoader) map[string]string {
+		return l.LoadSecretsByNamespace(namespace)
+	})
+}
+
+// LoadSecretsByService loads secrets for a given service in a given namespace
+func (loader *SecretLoaderMultipleSources) LoadSecretsByService(namespace string, service string) map[string]string {
+	return loader.merge(func(l SecretLoader) map[string]string {
+		return l.LoadSecretsByService(namespace, service)
+	})
+}
+
+func (loader *SecretLoaderMultipleSources) merge(load func(SecretLoader) map[string]string) map[string]string {
+	var result map[string]string
+	for _, l := range loader.loaders {
+		secrets := load(l)
+		if len(secrets) <= 0 {
+			continue
+		}
+		if result == nil {
+			result = make(map[string]string)
+		}
+		for name, value := range secrets {
+			result[name] = value
+		}
+	}
+	return result
+}
@@ -0,0 +1,23 @@
+package secrets
+
+import (
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestLoadSecretsMultipleSources(t *testing.T) {
+	first := NewSecretLoaderMock()
+	first.AddSecret("alice", "key", "first")
+	first.AddSecret("alice", "token", "first")
+	first.AddNamespaceSecret("main", "dbPassword", "first")
+
+	second := NewSecretLoaderMock()
+	second.AddSecret("alice", "token", "second")
+	second.AddServiceSecret("main", "twitter-stats", "dbPassword", "second")
+
+	secretLoader := NewSecretLoaderMultipleSources([]SecretLoader{first, second})
+	assert.Equal(t, map[string]string{"key": "first", "token": "second"}, secretLoader.LoadSecretsByUserName("alice"), "Later sources should override secrets")
+	assert.Equal(t, map[string]string{"dbPassword": "first"}, secretLoader.LoadSecretsByNamespace("main"))
+	assert.Equal(t, map[string]string{"dbPassword": "second"}, secretLoader.LoadSecretsByService("main", "twitter-stats"))
+	assert.Nil(t, secretLoader.LoadSecretsByUserName("bob"), "No secrets should be returned for unknown user")
+}
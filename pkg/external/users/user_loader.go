@@ -4,6 +4,10 @@ import (
 	"github.com/Aptomi/aptomi/pkg/lang"
 )
 
+// ReservedNamePrefix is a prefix of user names reserved for Aptomi itself (e.g. author of policy changes made by
+// Aptomi), users with such names are never loaded from the user directory
+const ReservedNamePrefix = "system:"
+
 // UserLoader is an interface which allows aptomi to load user data from different sources (e.g. file, LDAP, AD, etc)
 type UserLoader interface {
 	// LoadUsersAll should load all users
@@ -0,0 +1,187 @@
+package users
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	log "github.com/Sirupsen/logrus"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// UserLoaderCached wraps another user loader and caches all users loaded from it. Once data gets older than TTL, it
+// gets refreshed in background, while the cached data keeps being served. If refresh fails, the last successfully
+// loaded data will be served until the next successful refresh. So the callers (e.g. policy resolution) never block
+// on a slow or unavailable user directory, except for the very first load
+type UserLoaderCached struct {
+	loader UserLoader
+	ttl    time.Duration
+
+	// loadMutex ensures that only one load is happening at a time
+	loadMutex sync.Mutex
+
+	// dataMutex protects cached users, stats and refreshing flag
+	dataMutex  sync.RWMutex
+	users      *lang.GlobalUsers
+	stats      UserLoaderStats
+	refreshing bool
+}
+
+// UserLoaderStats represents statistics of loading users into the cache
+type UserLoaderStats struct {
+	// Users is the number of cached users
+	Users int
+
+	// Loads is the total number of attempts to load users
+	Loads int
+
+	// Failures is the total number of failed attempts to load users
+	Failures int
+
+	// LastLoaded is the time of the last successful load
+	LastLoaded time.Time
+
+	// LastAttempt is the time of the last load attempt, successful or not
+	LastAttempt time.Time
+
+	// LastLoadDuration is the duration of the last load attempt
+	LastLoadDuration time.Duration
+
+	// LastError is the error of the last load attempt, if it failed
+	LastError string
+}
+
+// NewUserLoaderCached returns new UserLoaderCached, which caches users retrieved from a given loader
+func NewUserLoaderCached(loader UserLoader, ttl time.Duration) *UserLoaderCached {
+	return &UserLoaderCached{
+		loader: loader,
+		ttl:    ttl,
+	}
+}
+
+// LoadUsersAll returns all cached users. If users were never loaded, they will be loaded synchronously. If cached
+// users are older than TTL, refresh will be triggered in background. Refresh is attempted not more often than once per
+// TTL, so a failing user directory doesn't get hit on every call
+func (loader *UserLoaderCached) LoadUsersAll() *lang.GlobalUsers {
+	loader.dataMutex.RLock()
+	users, attempted, refreshing := loader.users, loader.stats.LastAttempt, loader.refreshing
+	loader.dataMutex.RUnlock()
+
+	if users == nil {
+		err := loader.Refresh()
+		loader.dataMutex.RLock()
+		users = loader.users
+		loader.dataMutex.RUnlock()
+		if users == nil {
+			// there is no last known good data to serve, so we have to fail
+			panic(err)
+		}
+		return users
+	}
+
+	if !refreshing && time.Since(attempted) >= loader.ttl {
+		loader.refreshInBackground()
+	}
+
+	return users
+}
+
+// LoadUserByName returns a single user by name from the cache
+func (loader *UserLoaderCached) LoadUserByName(name string) *lang.User {
+	return loader.LoadUsersAll().Users[strings.ToLower(name)]
+}
+
+// Authenticate authenticates a user by username/password. Only cached users can be authenticated, while password
+// verification is delegated to the underlying loader
+func (loader *UserLoaderCached) Authenticate(name, password string) (*lang.User, error) {
+	if loader.LoadUserByName(name) == nil {
+		return nil, fmt.Errorf("user '%s' does not exist", name)
+	}
+	return loader.loader.Authenticate(name, password)
+}
+
+// Summary returns summary as string
+func (loader *UserLoaderCached) Summary() string {
+	return strconv.Itoa(len(loader.LoadUsersAll().Users)) + " (cached)"
+}
+
+// Stats returns statistics of loading users into the cache
+func (loader *UserLoaderCached) Stats() UserLoaderStats {
+	loader.dataMutex.RLock()
+	defer loader.dataMutex.RUnlock()
+	return loader.stats
+}
+
+// Refresh synchronously reloads users from the underlying loader. If loading fails, the error is returned and
+// previously loaded users are kept in the cache
+func (loader *UserLoaderCached) Refresh() error {
+	loader.loadMutex.Lock()
+	defer loader.loadMutex.Unlock()
+
+	start := time.Now()
+	users, err := loader.load()
+	duration := time.Since(start)
+
+	loader.dataMutex.Lock()
+	defer loader.dataMutex.Unlock()
+
+	loader.stats.Loads++
+	loader.stats.LastAttempt = start
+	loader.stats.LastLoadDuration = duration
+	if err != nil {
+		loader.stats.Failures++
+		loader.stats.LastError = err.Error()
+		return err
+	}
+
+	loader.users = users
+	loader.stats.Users = len(users.Users)
+	loader.stats.LastLoaded = start
+	loader.stats.LastError = ""
+
+	return nil
+}
+
+// load loads users from the underlying loader, skipping users with reserved names. Loaders panic when users can't be
+// loaded, so panic is converted into error here
+func (loader *UserLoaderCached) load() (users *lang.GlobalUsers, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("error while loading users: %s", r)
+		}
+	}()
+
+	result := &lang.GlobalUsers{Users: make(map[string]*lang.User)}
+	for name, user := range loader.loader.LoadUsersAll().Users {
+		if strings.HasPrefix(strings.ToLower(user.Name), ReservedNamePrefix) {
+			log.Warnf("Skipping user '%s', names with '%s' prefix are reserved", user.Name, ReservedNamePrefix)
+			continue
+		}
+		result.Users[name] = user
+	}
+	return result, nil
+}
+
+func (loader *UserLoaderCached) refreshInBackground() {
+	loader.dataMutex.Lock()
+	if loader.refreshing {
+		loader.dataMutex.Unlock()
+		return
+	}
+	loader.refreshing = true
+	loader.dataMutex.Unlock()
+
+	go func() {
+		defer func() {
+			loader.dataMutex.Lock()
+			loader.refreshing = false
+			loader.dataMutex.Unlock()
+		}()
+
+		err := loader.Refresh()
+		if err != nil {
+			log.Warnf("Serving cached users, because they can't be refreshed: %s", err)
+		}
+	}()
+}
@@ -0,0 +1,116 @@
+package users
+
+import (
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func TestUserLoaderCached(t *testing.T) {
+	mock := NewUserLoaderMock()
+	mock.AddUser(&lang.User{Name: "Alice"})
+	loader := NewUserLoaderCached(mock, time.Hour)
+
+	// users should be loaded on the first call
+	assert.NotNil(t, loader.LoadUserByName("alice"), "User should be loaded")
+	assert.Equal(t, 1, loader.Stats().Loads, "Users should be loaded once")
+	assert.Equal(t, 1, loader.Stats().Users, "Stats should contain number of loaded users")
+
+	// cached users should be served without calling the underlying loader
+	mock.AddUser(&lang.User{Name: "Bob"})
+	assert.NotNil(t, loader.LoadUserByName("alice"), "Cached users should be served")
+	assert.Equal(t, 1, loader.Stats().Loads, "Users should not be reloaded before TTL expires")
+
+	// explicit refresh should reload users
+	assert.NoError(t, loader.Refresh(), "Refresh should be successful")
+	assert.Equal(t, 2, len(loader.LoadUsersAll().Users), "Refreshed users should be served")
+
+	// last known good users should be served when refresh fails
+	mock.SetPanic(true)
+	assert.Error(t, loader.Refresh(), "Refresh should fail")
+	assert.Equal(t, 2, len(loader.LoadUsersAll().Users), "Last known good users should be served")
+	stats := loader.Stats()
+	assert.Equal(t, 3, stats.Loads, "All loads should be counted")
+	assert.Equal(t, 1, stats.Failures, "Failed load should be counted")
+	assert.NotEmpty(t, stats.LastError, "Error of the failed load should be recorded")
+}
+
+func TestUserLoaderCachedAuthenticate(t *testing.T) {
+	mock := NewUserLoaderMock()
+	mock.AddUser(&lang.User{Name: "Alice"})
+	loader := NewUserLoaderCached(mock, time.Hour)
+
+	_, err := loader.Authenticate("alice", "alice")
+	assert.NoError(t, err, "Cached user should be authenticated")
+
+	// mock authenticates any user, so the error means that only cached users are passed to the underlying loader
+	_, err = loader.Authenticate("bob", "bob")
+	assert.Error(t, err, "User, which isn't cached, shouldn't be authenticated")
+	assert.Equal(t, 1, loader.Stats().Loads, "Authentication shouldn't reload users")
+}
+
+func TestUserLoaderCachedReservedNames(t *testing.T) {
+	mock := NewUserLoaderMock()
+	mock.AddUser(&lang.User{Name: "Alice"})
+	mock.AddUser(&lang.User{Name: ReservedNamePrefix + "expiration"})
+	loader := NewUserLoaderCached(mock, time.Hour)
+
+	assert.NotNil(t, loader.LoadUserByName("alice"), "User should be loaded")
+	assert.Nil(t, loader.LoadUserByName(ReservedNamePrefix+"expiration"), "User with reserved name shouldn't be loaded")
+	assert.Equal(t, 1, loader.Stats().Users, "User with reserved name shouldn't be counted")
+}
+
+func TestUserLoaderCachedBackgroundRefresh(t *testing.T) {
+	mock := NewUserLoaderMock()
+	mock.AddUser(&lang.User{Name: "Alice"})
+	loader := NewUserLoaderCached(mock, 0)
+	assert.Equal(t, 1, len(loader.LoadUsersAll().Users), "Users should be loaded")
+
+	// expired users should be served, while they are refreshed in background
+	mock.SetPanic(true)
+	assert.Equal(t, 1, len(loader.LoadUsersAll().Users), "Cached users should be served, when they are expired")
+	for i := 0; i < 100 && loader.Stats().Failures <= 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 1, loader.Stats().Failures, "Background refresh should fail")
+	assert.Equal(t, 1, len(loader.LoadUsersAll().Users), "Last known good users should be served")
+}
+
+func TestUserLoaderCachedInitialFailure(t *testing.T) {
+	mock := NewUserLoaderMock()
+	mock.SetPanic(true)
+	loader := NewUserLoaderCached(mock, time.Hour)
+	assert.Panics(t, func() { loader.LoadUsersAll() }, "Loader should panic when there are no users to serve")
+}
+
+func TestUserLoaderCachedFailedRefreshBackoff(t *testing.T) {
+	ttl := 500 * time.Millisecond
+	mock := NewUserLoaderMock()
+	mock.AddUser(&lang.User{Name: "Alice"})
+	loader := NewUserLoaderCached(mock, ttl)
+	assert.Equal(t, 1, len(loader.LoadUsersAll().Users), "Users should be loaded")
+
+	// expired users trigger background refresh, which fails
+	mock.SetPanic(true)
+	time.Sleep(ttl)
+	loader.LoadUsersAll()
+	for i := 0; i < 100 && loader.Stats().Failures <= 0; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 1, loader.Stats().Failures, "Background refresh should fail")
+
+	// failed refresh shouldn't be retried on every call, but only once TTL passes since the last attempt
+	for i := 0; i < 10; i++ {
+		loader.LoadUsersAll()
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.Equal(t, 2, loader.Stats().Loads, "Failed refresh shouldn't be retried before TTL expires")
+
+	time.Sleep(ttl)
+	loader.LoadUsersAll()
+	for i := 0; i < 100 && loader.Stats().Failures <= 1; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 3, loader.Stats().Loads, "Failed refresh should be retried after TTL expires")
+}
@@ -5,18 +5,15 @@ import (
 	"fmt"
 	"github.com/Aptomi/aptomi/pkg/config"
 	"github.com/Aptomi/aptomi/pkg/lang"
-	"github.com/patrickmn/go-cache"
 	"gopkg.in/ldap.v2"
 	"strconv"
 	"strings"
-	"sync"
-	"time"
 )
 
-// UserLoaderFromLDAP allows aptomi to load users from LDAP
+// UserLoaderFromLDAP allows aptomi to load users from LDAP. It doesn't cache users, so every call results in LDAP
+// query. It's supposed to be wrapped into UserLoaderCached
 type UserLoaderFromLDAP struct {
 	cfg                  config.LDAP
-	cache                *cache.Cache
 	domainAdminOverrides map[string]bool
 }
 
@@ -24,24 +21,12 @@ type UserLoaderFromLDAP struct {
 func NewUserLoaderFromLDAP(cfg config.LDAP, domainAdminOverrides map[string]bool) UserLoader {
 	return &UserLoaderFromLDAP{
 		cfg:                  cfg,
-		cache:                cache.New(time.Minute, time.Minute),
 		domainAdminOverrides: domainAdminOverrides,
 	}
 }
 
 // LoadUsersAll loads all users
 func (loader *UserLoaderFromLDAP) LoadUsersAll() *lang.GlobalUsers {
-	// this can be called concurrently by the engine, so it needs to be thread safe
-	cachedUsers, _ := loader.cache.Get("ldapUsers")
-	if cachedUsers != nil {
-		return cachedUsers.(*lang.GlobalUsers)
-	}
-
-	// synchronize and retrieve users
-	mutex := sync.Mutex{}
-	mutex.Lock()
-	defer func() { mutex.Unlock() }()
-
 	result := &lang.GlobalUsers{Users: make(map[string]*lang.User)}
 	ldapUsers, err := loader.ldapSearch()
 	if err != nil {
@@ -54,7 +39,6 @@ func (loader *UserLoaderFromLDAP) LoadUsersAll() *lang.GlobalUsers {
 			u.DomainAdmin = true
 		}
 	}
-	loader.cache.Set("ldapUsers", result, cache.DefaultExpiration)
 	return result
 }
 
@@ -96,9 +80,14 @@ func (loader *UserLoaderFromLDAP) ldapSearch() ([]*lang.User, error) {
 		return nil, err
 	}
 
+	// nested groups are usually shared by many users, so parent groups are retrieved only once per load
+	parentGroups := make(map[string][]*ldapGroup)
 	result := []*lang.User{}
 	for _, entry := range searchResult.Entries {
-		user := loader.userFromLDAPEntry(entry)
+		user, errUser := loader.userFromLDAPEntry(l, entry, parentGroups)
+		if errUser != nil {
+			return nil, errUser
+		}
 		result = append(result, user)
 	}
 
@@ -140,19 +129,25 @@ func (loader *UserLoaderFromLDAP) ldapAuthenticate(name, password string) (*lang
 		return nil, fmt.Errorf("too many LDAP entries returned for user '%s'", name)
 	}
 
-	// Bind as the user to verify their password
+	// User groups are resolved before binding as the user, as the user itself may not be allowed to search groups
 	entry := searchResult.Entries[0]
-	dn := entry.DN
-	err = l.Bind(dn, password)
+	user, err := loader.userFromLDAPEntry(l, entry, make(map[string][]*ldapGroup))
+	if err != nil {
+		return nil, err
+	}
+
+	// Bind as the user to verify their password
+	err = l.Bind(entry.DN, password)
 	if err != nil {
 		return nil, fmt.Errorf("LDAP bind failed for user '%s': %s", name, err)
 	}
 
-	user := loader.userFromLDAPEntry(entry)
 	return user, nil
 }
 
-func (loader *UserLoaderFromLDAP) userFromLDAPEntry(entry *ldap.Entry) *lang.User {
+// userFromLDAPEntry builds user from a given LDAP entry. Parent groups, which were already retrieved from LDAP, are
+// taken from a given map of group DN -> parent groups, which gets filled with the newly retrieved ones
+func (loader *UserLoaderFromLDAP) userFromLDAPEntry(l *ldap.Conn, entry *ldap.Entry, parentGroups map[string][]*ldapGroup) (*lang.User, error) {
 	name := entry.GetAttributeValue(loader.cfg.LabelToAttributes["name"])
 	user := &lang.User{
 		Name:   name,
@@ -166,7 +161,19 @@ func (loader *UserLoaderFromLDAP) userFromLDAPEntry(entry *ldap.Entry) *lang.Use
 			}
 		}
 	}
-	return user
+
+	// map group membership to labels
+	if loader.cfg.Groups != nil {
+		groups, err := loader.ldapUserGroups(l, entry, parentGroups)
+		if err != nil {
+			return nil, err
+		}
+		for label, value := range ldapGroupLabels(loader.cfg.Groups, groups) {
+			user.Labels[label] = value
+		}
+	}
+
+	return user, nil
 }
 
 func ldapValue(value string) string {
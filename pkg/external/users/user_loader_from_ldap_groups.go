@@ -0,0 +1,155 @@
+package users
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"gopkg.in/ldap.v2"
+	"sort"
+	"strings"
+)
+
+// ldapGroup represents LDAP group, which user belongs to
+type ldapGroup struct {
+	dn   string
+	name string
+}
+
+// ldapUserGroups retrieves names of all groups a given LDAP entry belongs to. If nested groups are enabled, groups of
+// the groups are retrieved as well. Parent groups are memoised in a given map of group DN -> parent groups
+func (loader *UserLoaderFromLDAP) ldapUserGroups(l *ldap.Conn, entry *ldap.Entry, parentGroups map[string][]*ldapGroup) ([]string, error) {
+	cfg := loader.cfg.Groups
+
+	names := make(map[string]bool)
+	visited := make(map[string]bool)
+	groups, err := loader.ldapParentGroups(l, entry)
+	for len(groups) > 0 && err == nil {
+		group := groups[0]
+		groups = groups[1:]
+		if visited[strings.ToLower(group.dn)] {
+			continue
+		}
+		visited[strings.ToLower(group.dn)] = true
+		names[group.name] = true
+
+		if cfg.Nested {
+			parents, ok := parentGroups[strings.ToLower(group.dn)]
+			if !ok {
+				parents, err = loader.ldapParentGroupsByDN(l, group.dn)
+				if err == nil {
+					parentGroups[strings.ToLower(group.dn)] = parents
+				}
+			}
+			groups = append(groups, parents...)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	result := []string{}
+	for name := range names {
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// ldapParentGroups returns groups, which a given LDAP entry directly belongs to
+func (loader *UserLoaderFromLDAP) ldapParentGroups(l *ldap.Conn, entry *ldap.Entry) ([]*ldapGroup, error) {
+	cfg := loader.cfg.Groups
+	if len(cfg.MemberOfAttribute) > 0 {
+		return ldapGroupsFromDNs(entry.GetAttributeValues(cfg.MemberOfAttribute))
+	}
+	return loader.ldapGroupSearch(l, entry.DN)
+}
+
+// ldapParentGroupsByDN returns groups, which an LDAP entry with a given DN directly belongs to
+func (loader *UserLoaderFromLDAP) ldapParentGroupsByDN(l *ldap.Conn, dn string) ([]*ldapGroup, error) {
+	cfg := loader.cfg.Groups
+	if len(cfg.MemberOfAttribute) == 0 {
+		return loader.ldapGroupSearch(l, dn)
+	}
+
+	searchRequest := ldap.NewSearchRequest(
+		dn,
+		ldap.ScopeBaseObject, ldap.NeverDerefAliases, 0, 0, false,
+		"(objectClass=*)",
+		[]string{cfg.MemberOfAttribute},
+		nil,
+	)
+	searchResult, err := l.Search(searchRequest)
+	if err != nil {
+		return nil, fmt.Errorf("error while retrieving LDAP group '%s': %s", dn, err)
+	}
+	if len(searchResult.Entries) <= 0 {
+		return nil, nil
+	}
+	return ldapGroupsFromDNs(searchResult.Entries[0].GetAttributeValues(cfg.MemberOfAttribute))
+}
+
+// ldapGroupSearch runs group query and returns groups, which have a given member
+func (loader *UserLoaderFromLDAP) ldapGroupSearch(l *ldap.Conn, memberDN string) ([]*ldapGroup, error) {
+	cfg := loader.cfg.Groups
+	if len(cfg.Filter) == 0 {
+		return nil, fmt.Errorf("either group membership attribute or group filter should be specified in LDAP config")
+	}
+	baseDN := cfg.BaseDN
+	if len(baseDN) == 0 {
+		baseDN = loader.cfg.BaseDN
+	}
+	nameAttribute := cfg.NameAttribute
+	if len(nameAttribute) == 0 {
+		nameAttribute = "cn"
+	}
+
+	searchRequest := ldap.NewSearchRequest(
+		baseDN,
+		ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false,
+		fmt.Sprintf(cfg.Filter, ldap.EscapeFilter(memberDN)),
+		[]string{nameAttribute},
+		nil,
+	)
+	searchResult, err := l.Search(searchRequest)
+	if err != nil {
+		return nil, fmt.Errorf("error while retrieving LDAP groups for '%s': %s", memberDN, err)
+	}
+
+	result := []*ldapGroup{}
+	for _, entry := range searchResult.Entries {
+		name := entry.GetAttributeValue(nameAttribute)
+		if len(name) > 0 {
+			result = append(result, &ldapGroup{dn: entry.DN, name: name})
+		}
+	}
+	return result, nil
+}
+
+// ldapGroupsFromDNs converts a list of group DNs into groups, taking group names from the first RDN
+func ldapGroupsFromDNs(dns []string) ([]*ldapGroup, error) {
+	result := []*ldapGroup{}
+	for _, dn := range dns {
+		parsed, err := ldap.ParseDN(dn)
+		if err != nil {
+			return nil, fmt.Errorf("error while parsing LDAP group DN '%s': %s", dn, err)
+		}
+		if len(parsed.RDNs) <= 0 || len(parsed.RDNs[0].Attributes) <= 0 {
+			return nil, fmt.Errorf("LDAP group DN '%s' is empty", dn)
+		}
+		result = append(result, &ldapGroup{dn: dn, name: parsed.RDNs[0].Attributes[0].Value})
+	}
+	return result, nil
+}
+
+// ldapGroupLabels returns user labels for a given list of user groups
+func ldapGroupLabels(cfg *config.LDAPGroups, groups []string) map[string]string {
+	result := make(map[string]string)
+	if len(cfg.Label) > 0 && len(groups) > 0 {
+		result[cfg.Label] = strings.Join(groups, ",")
+	}
+	if len(cfg.LabelPrefix) > 0 {
+		for _, group := range groups {
+			result[cfg.LabelPrefix+group] = "true"
+		}
+	}
+	return result
+}
@@ -25,6 +25,12 @@ var integrationTestsLDAP = config.LDAP{
 		"short-description": "role",
 		"deactivated":       "deactivated",
 	},
+	Groups: &config.LDAPGroups{
+		Filter:      "(&(objectClass=groupOfNames)(member=%s))",
+		Nested:      true,
+		Label:       "groups",
+		LabelPrefix: "group_",
+	},
 }
 
 func TestUserLoaderFromLDAP(t *testing.T) {
@@ -97,3 +103,25 @@ func compareUsers(t *testing.T, uDir *lang.User, uLDAP *lang.User) {
 		}
 	}
 }
+
+func TestLDAPGroupLabels(t *testing.T) {
+	groups, err := ldapGroupsFromDNs([]string{"cn=developers,ou=groups,o=aptomiOrg", "cn=mobile-dev,ou=groups,o=aptomiOrg"})
+	if !assert.NoError(t, err, "Group DNs should be parsed") {
+		return
+	}
+	names := []string{}
+	for _, group := range groups {
+		names = append(names, group.name)
+	}
+	assert.Equal(t, []string{"developers", "mobile-dev"}, names, "Group names should be taken from the first RDN")
+
+	cfg := &config.LDAPGroups{Label: "groups", LabelPrefix: "group_"}
+	assert.Equal(t, map[string]string{
+		"groups":           "developers,mobile-dev",
+		"group_developers": "true",
+		"group_mobile-dev": "true",
+	}, ldapGroupLabels(cfg, names), "Labels should be generated for user groups")
+
+	assert.Empty(t, ldapGroupLabels(cfg, []string{}), "No labels should be generated for user without groups")
+	assert.Equal(t, map[string]string{"groups": "developers"}, ldapGroupLabels(&config.LDAPGroups{Label: "groups"}, []string{"developers"}), "Only list label should be generated without prefix")
+}
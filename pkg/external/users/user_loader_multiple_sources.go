@@ -5,11 +5,18 @@ import (
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"strconv"
 	"strings"
+	"sync"
 )
 
-// UserLoaderMultipleSources allows to combine different user sources into a single loader
+// UserLoaderMultipleSources allows to combine different user sources into a single loader. If user exists in multiple
+// sources, the one from the first source is used
 type UserLoaderMultipleSources struct {
 	loaders []UserLoader
+
+	// sources is a map of lower-cased user name -> source it was loaded from by the last LoadUsersAll call, so users
+	// could be authenticated without loading them from all sources one more time
+	sourcesMutex sync.RWMutex
+	sources      map[string]UserLoader
 }
 
 // NewUserLoaderMultipleSources returns new UserLoaderMultipleSources
@@ -17,14 +24,29 @@ func NewUserLoaderMultipleSources(loaders []UserLoader) *UserLoaderMultipleSourc
 	return &UserLoaderMultipleSources{loaders: loaders}
 }
 
+// Loaders returns the list of combined user loaders
+func (loader *UserLoaderMultipleSources) Loaders() []UserLoader {
+	return loader.loaders
+}
+
 // LoadUsersAll loads all users
 func (loader *UserLoaderMultipleSources) LoadUsersAll() *lang.GlobalUsers {
 	result := &lang.GlobalUsers{Users: make(map[string]*lang.User)}
-	for _, loader := range loader.loaders {
-		for name, user := range loader.LoadUsersAll().Users {
-			result.Users[strings.ToLower(name)] = user
+	sources := make(map[string]UserLoader)
+	for _, source := range loader.loaders {
+		for name, user := range source.LoadUsersAll().Users {
+			name = strings.ToLower(name)
+			if _, exist := result.Users[name]; !exist {
+				result.Users[name] = user
+				sources[name] = source
+			}
 		}
 	}
+
+	loader.sourcesMutex.Lock()
+	loader.sources = sources
+	loader.sourcesMutex.Unlock()
+
 	return result
 }
 
@@ -39,8 +61,16 @@ func (loader *UserLoaderMultipleSources) LoadUserByName(name string) *lang.User
 	return nil
 }
 
-// Authenticate authenticate a user by username/password by trying all available user data sources.
+// Authenticate authenticate a user by username/password using the source user was loaded from. If users weren't loaded
+// yet, the user is looked up in all available user data sources.
 func (loader *UserLoaderMultipleSources) Authenticate(name, password string) (*lang.User, error) {
+	loader.sourcesMutex.RLock()
+	source, found := loader.sources[strings.ToLower(name)]
+	loader.sourcesMutex.RUnlock()
+	if found {
+		return source.Authenticate(name, password)
+	}
+
 	for _, l := range loader.loaders {
 		user := l.LoadUserByName(name)
 		if user != nil {
@@ -58,3 +58,30 @@ func TestUserLoaderFromMultipleSources(t *testing.T) {
 		assert.Empty(t, user, "User should not be returned as a result of failed authentication")
 	}
 }
+
+// countingUserLoader counts calls to LoadUserByName of the wrapped loader
+type countingUserLoader struct {
+	UserLoader
+	loadByName int
+}
+
+func (loader *countingUserLoader) LoadUserByName(name string) *lang.User {
+	loader.loadByName++
+	return loader.UserLoader.LoadUserByName(name)
+}
+
+func TestUserLoaderFromMultipleSourcesAuthenticateLoaded(t *testing.T) {
+	u1 := &countingUserLoader{UserLoader: makeUserLoader(0, 10)}
+	unitTests := &countingUserLoader{UserLoader: NewUserLoaderFromFile("../../testdata/unittests/users.yaml", make(map[string]bool))}
+	uMulti := NewUserLoaderMultipleSources([]UserLoader{u1, unitTests})
+	uMulti.LoadUsersAll()
+
+	user, err := uMulti.Authenticate("Alice", "alice")
+	assert.NoError(t, err, "Authentication should be successful")
+	assert.NotEmpty(t, user, "User should be returned as a result of authentication")
+
+	_, err = uMulti.Authenticate("Alice", "wrong")
+	assert.Error(t, err, "Authentication should not be successful")
+
+	assert.Equal(t, 0, u1.loadByName+unitTests.loadByName, "Loaded users should be authenticated without loading them again")
+}
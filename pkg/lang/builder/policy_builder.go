@@ -167,6 +167,22 @@ func (builder *PolicyBuilder) AddRule(criteria *lang.Criteria, actions *lang.Rul
 	return result
 }
 
+// AddQuota creates a new quota in the system namespace and adds it to the policy
+func (builder *PolicyBuilder) AddQuota(scope string, maxDependencies int, maxInstances int) *lang.Quota {
+	result := &lang.Quota{
+		TypeKind: lang.QuotaObject.GetTypeKind(),
+		Metadata: lang.Metadata{
+			Namespace: runtime.SystemNS,
+			Name:      util.RandomID(builder.random, idLength),
+		},
+		Scope:           scope,
+		MaxDependencies: maxDependencies,
+		MaxInstances:    maxInstances,
+	}
+	builder.addObject(builder.domainAdminView, result)
+	return result
+}
+
 // AddCluster creates a new cluster and adds it to the policy
 func (builder *PolicyBuilder) AddCluster() *lang.Cluster {
 	result := &lang.Cluster{
@@ -1,7 +1,9 @@
 package lang
 
 import (
+	"fmt"
 	"github.com/Aptomi/aptomi/pkg/runtime"
+	"time"
 )
 
 // DependencyObject is an informational data structure with Kind and Constructor for Dependency
@@ -30,6 +32,115 @@ type Dependency struct {
 
 	// Labels which are provided by the user.
 	Labels map[string]string `yaml:"labels,omitempty" validate:"omitempty,labels"`
+
+	// TTL is an optional time-to-live for the dependency (e.g. '8h', '30m'). When it's set, Aptomi will calculate
+	// ExpiresAt at the time dependency gets added to the policy.
+	TTL string `yaml:"ttl,omitempty" validate:"omitempty,duration"`
+
+	// ExpiresAt is an optional point in time, after which the dependency will be automatically deleted from the
+	// policy by Aptomi.
+	ExpiresAt time.Time `yaml:"expires-at,omitempty"`
+
+	// AdmittedAt is a point in time, when the dependency was added to the policy for the first time. It's set by
+	// Aptomi and it defines the order, in which dependencies take quota slots, so new dependencies never evict the
+	// ones which already exist.
+	AdmittedAt time.Time `yaml:"admitted-at,omitempty"`
+}
+
+// GetTTL returns dependency time-to-live as duration. If TTL is not set, zero will be returned
+func (dependency *Dependency) GetTTL() time.Duration {
+	if len(dependency.TTL) <= 0 {
+		return 0
+	}
+	ttl, err := time.ParseDuration(dependency.TTL)
+	if err != nil {
+		return 0
+	}
+	return ttl
+}
+
+// IsExpired returns true if dependency has expiration time set and it's already expired at a given moment
+func (dependency *Dependency) IsExpired(now time.Time) bool {
+	return !dependency.ExpiresAt.IsZero() && !now.Before(dependency.ExpiresAt)
+}
+
+// GetExpiresIn returns remaining lifetime of the dependency at a given moment. If dependency has no expiration
+// time set, zero and false will be returned
+func (dependency *Dependency) GetExpiresIn(now time.Time) (time.Duration, bool) {
+	if dependency.ExpiresAt.IsZero() {
+		return 0, false
+	}
+	if dependency.IsExpired(now) {
+		return 0, true
+	}
+	return dependency.ExpiresAt.Sub(now), true
+}
+
+// GetLifetime returns remaining lifetime of the dependency at a given moment, which is limited by its expiration time
+// if it's set or by its TTL otherwise. If dependency lifetime isn't limited, zero and false will be returned
+func (dependency *Dependency) GetLifetime(now time.Time) (time.Duration, bool) {
+	if expiresIn, ok := dependency.GetExpiresIn(now); ok {
+		return expiresIn, true
+	}
+	if ttl := dependency.GetTTL(); ttl > 0 {
+		return ttl, true
+	}
+	return 0, false
+}
+
+// InitExpiration calculates TTL and expiration time for a dependency which is being added to the policy. If the
+// same dependency with the same TTL/expiration time already exists in the policy, its expiration time will be
+// preserved. Otherwise, expiration time will be calculated relative to a given moment. Only one of TTL and expiration
+// time could be set, unless they are the same as for the existing dependency (e.g. when it's exported and applied back)
+func (dependency *Dependency) InitExpiration(existing *Dependency, now time.Time) error {
+	hasTTL := len(dependency.TTL) > 0
+	hasExpiresAt := !dependency.ExpiresAt.IsZero()
+
+	if hasTTL && hasExpiresAt {
+		if existing != nil && existing.TTL == dependency.TTL && existing.ExpiresAt.Equal(dependency.ExpiresAt) {
+			return nil
+		}
+		return fmt.Errorf("only one of ttl and expires-at could be set for dependency %s", runtime.KeyForStorable(dependency))
+	}
+
+	if hasTTL && !hasExpiresAt {
+		if existing != nil && existing.TTL == dependency.TTL && !existing.ExpiresAt.IsZero() {
+			dependency.ExpiresAt = existing.ExpiresAt
+			return nil
+		}
+		ttl, err := time.ParseDuration(dependency.TTL)
+		if err != nil {
+			return fmt.Errorf("invalid TTL '%s' for dependency %s: %s", dependency.TTL, runtime.KeyForStorable(dependency), err)
+		}
+		if ttl <= 0 {
+			return fmt.Errorf("TTL must be positive for dependency %s, but found '%s'", runtime.KeyForStorable(dependency), dependency.TTL)
+		}
+		dependency.ExpiresAt = now.Add(ttl)
+	}
+
+	if !hasTTL && hasExpiresAt {
+		if existing != nil && existing.ExpiresAt.Equal(dependency.ExpiresAt) && len(existing.TTL) > 0 {
+			dependency.TTL = existing.TTL
+			return nil
+		}
+		if dependency.IsExpired(now) {
+			return fmt.Errorf("dependency %s has already expired at %s", runtime.KeyForStorable(dependency), dependency.ExpiresAt)
+		}
+		dependency.TTL = dependency.ExpiresAt.Sub(now).Round(time.Second).String()
+	}
+
+	return nil
+}
+
+// InitAdmission sets admission time for a dependency which is being added to the policy. Admission time of the same
+// dependency which already exists in the policy is preserved, otherwise it's set to a given moment. Admission time
+// provided by user is always ignored, so it can't be used to take quota slots from other dependencies
+func (dependency *Dependency) InitAdmission(existing *Dependency, now time.Time) {
+	if existing != nil {
+		dependency.AdmittedAt = existing.AdmittedAt
+	} else {
+		dependency.AdmittedAt = now
+	}
 }
 
 // GlobalDependencies represents the list of global dependencies (see the definition above)
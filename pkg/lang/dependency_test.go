@@ -3,6 +3,7 @@ package lang
 import (
 	"github.com/stretchr/testify/assert"
 	"testing"
+	"time"
 )
 
 func TestAddDependency(t *testing.T) {
@@ -25,3 +26,67 @@ func TestAddDependency(t *testing.T) {
 	assert.Equal(t, 1, len(dependencies.DependenciesByContract["newcontract"]), "Dependency on 'newcontract' should be added")
 	assert.Equal(t, "dep_id_new", dependencies.DependenciesByContract["newcontract"][0].Name, "Dependency on 'newcontract' should be added")
 }
+
+func TestDependencyExpiration(t *testing.T) {
+	now := time.Date(2018, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// dependency without TTL never expires
+	dependency := &Dependency{TypeKind: DependencyObject.GetTypeKind(), Metadata: Metadata{Namespace: "main", Name: "dep"}}
+	assert.NoError(t, dependency.InitExpiration(nil, now), "Dependency without TTL should be initialized")
+	assert.True(t, dependency.ExpiresAt.IsZero(), "Dependency without TTL should not have expiration time")
+	assert.False(t, dependency.IsExpired(now.Add(1000*time.Hour)), "Dependency without TTL should never expire")
+	_, ok := dependency.GetExpiresIn(now)
+	assert.False(t, ok, "Dependency without TTL should not have remaining lifetime")
+
+	// dependency with TTL gets expiration time calculated
+	dependency = &Dependency{TypeKind: DependencyObject.GetTypeKind(), Metadata: Metadata{Namespace: "main", Name: "dep"}, TTL: "2h"}
+	assert.NoError(t, dependency.InitExpiration(nil, now), "Dependency with TTL should be initialized")
+	assert.Equal(t, now.Add(2*time.Hour), dependency.ExpiresAt, "Dependency expiration time should be calculated from TTL")
+	assert.Equal(t, 2*time.Hour, dependency.GetTTL(), "Dependency TTL should be parsed correctly")
+	assert.False(t, dependency.IsExpired(now.Add(time.Hour)), "Dependency should not be expired before its expiration time")
+	assert.True(t, dependency.IsExpired(now.Add(2*time.Hour)), "Dependency should be expired at its expiration time")
+	expiresIn, ok := dependency.GetExpiresIn(now.Add(30 * time.Minute))
+	assert.True(t, ok, "Dependency with TTL should have remaining lifetime")
+	assert.Equal(t, 90*time.Minute, expiresIn, "Dependency remaining lifetime should be calculated correctly")
+
+	// re-applying the same dependency later should preserve its expiration time
+	updated := &Dependency{TypeKind: DependencyObject.GetTypeKind(), Metadata: Metadata{Namespace: "main", Name: "dep"}, TTL: "2h"}
+	assert.NoError(t, updated.InitExpiration(dependency, now.Add(time.Hour)), "Updated dependency should be initialized")
+	assert.Equal(t, dependency.ExpiresAt, updated.ExpiresAt, "Expiration time should be preserved for the same TTL")
+
+	// changing TTL should recalculate expiration time
+	updated = &Dependency{TypeKind: DependencyObject.GetTypeKind(), Metadata: Metadata{Namespace: "main", Name: "dep"}, TTL: "3h"}
+	assert.NoError(t, updated.InitExpiration(dependency, now.Add(time.Hour)), "Updated dependency should be initialized")
+	assert.Equal(t, now.Add(4*time.Hour), updated.ExpiresAt, "Expiration time should be recalculated when TTL changes")
+
+	// dependency with expiration time gets TTL calculated
+	dependency = &Dependency{TypeKind: DependencyObject.GetTypeKind(), Metadata: Metadata{Namespace: "main", Name: "dep"}, ExpiresAt: now.Add(90 * time.Minute)}
+	assert.NoError(t, dependency.InitExpiration(nil, now), "Dependency with expiration time should be initialized")
+	assert.Equal(t, 90*time.Minute, dependency.GetTTL(), "Dependency TTL should be calculated from expiration time")
+
+	// dependency with both TTL and expiration time should not be accepted, unless it's the same as existing one
+	dependency = &Dependency{TypeKind: DependencyObject.GetTypeKind(), Metadata: Metadata{Namespace: "main", Name: "dep"}, TTL: "1h", ExpiresAt: now.Add(1000 * time.Hour)}
+	assert.Error(t, dependency.InitExpiration(nil, now), "Dependency with both TTL and expiration time should not be accepted")
+	existing := &Dependency{TypeKind: DependencyObject.GetTypeKind(), Metadata: Metadata{Namespace: "main", Name: "dep"}, TTL: "1h"}
+	assert.NoError(t, existing.InitExpiration(nil, now), "Dependency with TTL should be initialized")
+	dependency = &Dependency{TypeKind: DependencyObject.GetTypeKind(), Metadata: Metadata{Namespace: "main", Name: "dep"}, TTL: existing.TTL, ExpiresAt: existing.ExpiresAt}
+	assert.NoError(t, dependency.InitExpiration(existing, now), "Dependency which is the same as existing one should be accepted")
+
+	// dependency which has already expired should not be accepted
+	dependency = &Dependency{TypeKind: DependencyObject.GetTypeKind(), Metadata: Metadata{Namespace: "main", Name: "dep"}, ExpiresAt: now.Add(-time.Minute)}
+	assert.Error(t, dependency.InitExpiration(nil, now), "Dependency which already expired should not be accepted")
+}
+
+func TestDependencyAdmission(t *testing.T) {
+	now := time.Date(2018, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	// admission time provided by user is ignored for a new dependency
+	dependency := &Dependency{TypeKind: DependencyObject.GetTypeKind(), Metadata: Metadata{Namespace: "main", Name: "dep"}, AdmittedAt: now.Add(-1000 * time.Hour)}
+	dependency.InitAdmission(nil, now)
+	assert.Equal(t, now, dependency.AdmittedAt, "New dependency should be admitted now")
+
+	// admission time of the existing dependency is preserved
+	updated := &Dependency{TypeKind: DependencyObject.GetTypeKind(), Metadata: Metadata{Namespace: "main", Name: "dep"}}
+	updated.InitAdmission(dependency, now.Add(time.Hour))
+	assert.Equal(t, now, updated.AdmittedAt, "Admission time should be preserved for existing dependency")
+}
@@ -72,3 +72,8 @@ func (expression *Expression) EvaluateAsBool(params *Parameters) (bool, error) {
 
 	return value, nil
 }
+
+// Vars returns the list of variables referenced in the expression
+func (expression *Expression) Vars() []string {
+	return expression.expressionCompiled.Vars()
+}
@@ -0,0 +1,365 @@
+package lang
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/lang/expression"
+	"github.com/Aptomi/aptomi/pkg/lang/template"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"sort"
+	"strings"
+)
+
+const (
+	// LintError is a severity for findings, which will result in errors during policy resolution
+	LintError = "error"
+
+	// LintWarning is a severity for findings, which most likely indicate a mistake in the policy
+	LintWarning = "warning"
+
+	// LintInfo is a severity for findings, which are worth looking at, but are not necessarily a mistake
+	LintInfo = "info"
+)
+
+// LintFinding is a single issue found by PolicyLinter
+type LintFinding struct {
+	// Severity is one of 'error', 'warning', 'info'
+	Severity string
+
+	// Object is a key of the policy object, where the issue was found
+	Object string
+
+	// Field is a location of the issue within the object (can be empty, if it's related to the whole object)
+	Field string `yaml:",omitempty"`
+
+	// Message is a human-readable description of the issue
+	Message string
+}
+
+// PolicyLinter performs static analysis of the whole policy. While policy validation only checks that every object
+// is well-formed and references are valid, policy linter looks for things which are valid, but most likely are
+// mistakes (e.g. contracts without consumers, contexts which can never be matched, unused clusters, etc)
+type PolicyLinter struct {
+	policy *Policy
+	users  *GlobalUsers
+
+	// labels which are set anywhere in the policy (or by users)
+	labelsSet map[string]bool
+
+	// values of 'cluster' label which are set anywhere in the policy (or by users)
+	clustersSet map[string]bool
+
+	findings []*LintFinding
+}
+
+// NewPolicyLinter creates a new PolicyLinter. Users are optional and, if provided, their labels will be taken into
+// account when checking for labels which are never set. If users aren't provided, these checks are skipped, as most
+// of the labels used in rules and allocation keys are usually coming from users
+func NewPolicyLinter(policy *Policy, users *GlobalUsers) *PolicyLinter {
+	return &PolicyLinter{
+		policy:      policy,
+		users:       users,
+		labelsSet:   make(map[string]bool),
+		clustersSet: make(map[string]bool),
+	}
+}
+
+// Lint runs all checks against the policy and returns the list of findings, sorted by object and field
+func (linter *PolicyLinter) Lint() []*LintFinding {
+	linter.findings = []*LintFinding{}
+	linter.collectLabels()
+
+	linter.checkContractsWithoutConsumers()
+	linter.checkUnreachableContexts()
+	linter.checkUnreferencedServices()
+	linter.checkRuleLabels(RuleObject.Kind)
+	linter.checkRuleLabels(ACLRuleObject.Kind)
+	linter.checkAllocationKeys()
+	linter.checkUnusedClusters()
+
+	sort.Slice(linter.findings, func(i, j int) bool {
+		if linter.findings[i].Object != linter.findings[j].Object {
+			return linter.findings[i].Object < linter.findings[j].Object
+		}
+		return linter.findings[i].Field < linter.findings[j].Field
+	})
+	return linter.findings
+}
+
+func (linter *PolicyLinter) report(severity string, obj Base, field string, format string, args ...interface{}) {
+	linter.findings = append(linter.findings, &LintFinding{
+		Severity: severity,
+		Object:   runtime.KeyForStorable(obj),
+		Field:    field,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// collects all labels (and values of 'cluster' label) which are set anywhere
+func (linter *PolicyLinter) collectLabels() {
+	addLabels := func(labels map[string]string) {
+		for name, value := range labels {
+			linter.labelsSet[name] = true
+			if name == LabelCluster {
+				linter.clustersSet[value] = true
+			}
+		}
+	}
+
+	for _, obj := range linter.policy.GetObjectsByKind(DependencyObject.Kind) {
+		addLabels(obj.(*Dependency).Labels)
+	}
+	for _, obj := range linter.policy.GetObjectsByKind(ContractObject.Kind) {
+		contract := obj.(*Contract)
+		addLabels(contract.ChangeLabels["set"])
+		for _, context := range contract.Contexts {
+			addLabels(context.ChangeLabels["set"])
+		}
+	}
+	for _, kind := range []string{RuleObject.Kind, ACLRuleObject.Kind} {
+		for _, obj := range linter.policy.GetObjectsByKind(kind) {
+			rule := obj.(*Rule)
+			if rule.Actions != nil {
+				addLabels(rule.Actions.ChangeLabels["set"])
+			}
+		}
+	}
+	if linter.users != nil {
+		for _, user := range linter.users.Users {
+			addLabels(user.Labels)
+		}
+	}
+}
+
+// returns true if label is known to be never set, which could be determined only when users are provided
+func (linter *PolicyLinter) isNeverSet(label string) bool {
+	return linter.users != nil && !linter.labelsSet[label]
+}
+
+// returns full 'namespace/name' reference, given a locator in form of '[namespace/]name' and current namespace
+func fullRef(locator string, currentNs string) string {
+	if strings.Contains(locator, "/") {
+		return locator
+	}
+	return currentNs + "/" + locator
+}
+
+// checks for contracts which are not consumed by any dependency or any service
+func (linter *PolicyLinter) checkContractsWithoutConsumers() {
+	consumed := make(map[string]bool)
+	for _, obj := range linter.policy.GetObjectsByKind(DependencyObject.Kind) {
+		dependency := obj.(*Dependency)
+		consumed[fullRef(dependency.Contract, dependency.Namespace)] = true
+	}
+	for _, obj := range linter.policy.GetObjectsByKind(ServiceObject.Kind) {
+		service := obj.(*Service)
+		for _, component := range service.Components {
+			if len(component.Contract) > 0 {
+				consumed[fullRef(component.Contract, service.Namespace)] = true
+			}
+		}
+	}
+
+	for _, obj := range linter.policy.GetObjectsByKind(ContractObject.Kind) {
+		contract := obj.(*Contract)
+		if !consumed[contract.Namespace+"/"+contract.Name] {
+			linter.report(LintWarning, contract, "", "contract is not consumed by any dependency or service")
+		}
+	}
+}
+
+// checks for contexts which can never be matched, because one of the earlier contexts always matches instead
+func (linter *PolicyLinter) checkUnreachableContexts() {
+	for _, obj := range linter.policy.GetObjectsByKind(ContractObject.Kind) {
+		contract := obj.(*Contract)
+		for idx, context := range contract.Contexts {
+			for _, earlier := range contract.Contexts[:idx] {
+				if criteriaSubsumes(earlier.Criteria, context.Criteria) {
+					linter.report(LintWarning, contract, fmt.Sprintf("Contexts[%s]", context.Name), "context can never be matched, because earlier context '%s' always matches when it does", earlier.Name)
+					break
+				}
+			}
+		}
+	}
+}
+
+// criteriaSubsumes returns true if 'earlier' criteria always evaluates to true when 'later' criteria evaluates to true.
+// It's a conservative syntactic check, so it may return false even if 'earlier' actually subsumes 'later'
+func criteriaSubsumes(earlier *Criteria, later *Criteria) bool {
+	if earlier == nil {
+		return true
+	}
+	if later == nil {
+		later = &Criteria{}
+	}
+
+	laterAll := stringSet(later.RequireAll)
+	laterAny := stringSet(later.RequireAny)
+	laterNone := stringSet(later.RequireNone)
+
+	// all 'require-all' expressions must be required by later criteria as well
+	for _, expr := range earlier.RequireAll {
+		expr = strings.TrimSpace(expr)
+		if expr != "true" && !laterAll[expr] {
+			return false
+		}
+	}
+
+	// at least one 'require-any' expression must be true whenever later criteria is true
+	if len(earlier.RequireAny) > 0 {
+		earlierAny := stringSet(earlier.RequireAny)
+		anyHolds := earlierAny["true"]
+		for expr := range earlierAny {
+			anyHolds = anyHolds || laterAll[expr]
+		}
+		if !anyHolds && len(laterAny) > 0 {
+			anyHolds = true
+			for expr := range laterAny {
+				anyHolds = anyHolds && earlierAny[expr]
+			}
+		}
+		if !anyHolds {
+			return false
+		}
+	}
+
+	// all 'require-none' expressions must be prohibited by later criteria as well
+	for _, expr := range earlier.RequireNone {
+		expr = strings.TrimSpace(expr)
+		if expr != "false" && !laterNone[expr] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func stringSet(list []string) map[string]bool {
+	result := make(map[string]bool)
+	for _, s := range list {
+		result[strings.TrimSpace(s)] = true
+	}
+	return result
+}
+
+// checks for services which are not referenced by any contract
+func (linter *PolicyLinter) checkUnreferencedServices() {
+	referenced := make(map[string]bool)
+	for _, obj := range linter.policy.GetObjectsByKind(ContractObject.Kind) {
+		contract := obj.(*Contract)
+		for _, context := range contract.Contexts {
+			if context.Allocation != nil {
+				referenced[fullRef(context.Allocation.Service, contract.Namespace)] = true
+			}
+		}
+	}
+
+	for _, obj := range linter.policy.GetObjectsByKind(ServiceObject.Kind) {
+		service := obj.(*Service)
+		if !referenced[service.Namespace+"/"+service.Name] {
+			linter.report(LintWarning, service, "", "service is not referenced by any contract")
+		}
+	}
+}
+
+// checks for rules which refer to labels, which are never set anywhere
+func (linter *PolicyLinter) checkRuleLabels(kind string) {
+	for _, obj := range linter.policy.GetObjectsByKind(kind) {
+		rule := obj.(*Rule)
+		if rule.Criteria == nil {
+			continue
+		}
+
+		clauses := map[string][]string{
+			"RequireAll":  rule.Criteria.RequireAll,
+			"RequireAny":  rule.Criteria.RequireAny,
+			"RequireNone": rule.Criteria.RequireNone,
+		}
+		for clause, expressions := range clauses {
+			for _, exprStr := range expressions {
+				expr, err := expression.NewExpression(exprStr)
+				if err != nil {
+					// invalid expressions are reported by policy validation
+					continue
+				}
+				for _, name := range expr.Vars() {
+					// 'service' is not a label, it's an object exposed to rules
+					if name == "service" || !linter.isNeverSet(name) {
+						continue
+					}
+					linter.report(LintWarning, rule, fmt.Sprintf("Criteria.%s[%s]", clause, exprStr), "label '%s' is never set in the policy", name)
+				}
+			}
+		}
+	}
+}
+
+// checks for allocation keys which refer to unknown variables
+func (linter *PolicyLinter) checkAllocationKeys() {
+	userFields := map[string]bool{"Name": true, "Labels": true, "Secrets": true}
+	for _, obj := range linter.policy.GetObjectsByKind(ContractObject.Kind) {
+		contract := obj.(*Contract)
+		for _, context := range contract.Contexts {
+			if context.Allocation == nil {
+				continue
+			}
+			for _, key := range context.Allocation.Keys {
+				tmpl, err := template.NewTemplate(key)
+				if err != nil {
+					// invalid templates are reported by policy validation
+					continue
+				}
+				field := fmt.Sprintf("Contexts[%s].Allocation.Keys[%s]", context.Name, key)
+				for _, ident := range tmpl.Fields() {
+					ref := "." + strings.Join(ident, ".")
+					switch {
+					case ident[0] == "Labels":
+						if len(ident) > 1 && linter.isNeverSet(ident[1]) {
+							linter.report(LintWarning, contract, field, "allocation key refers to label '%s', which is never set in the policy", ident[1])
+						}
+					case ident[0] == "User":
+						if len(ident) > 1 && !userFields[ident[1]] {
+							linter.report(LintError, contract, field, "allocation key refers to unknown variable '%s'", ref)
+						}
+					default:
+						linter.report(LintError, contract, field, "allocation key refers to unknown variable '%s'", ref)
+					}
+				}
+			}
+		}
+	}
+}
+
+// checks for clusters which are never used, i.e. 'cluster' label is never set to their names
+func (linter *PolicyLinter) checkUnusedClusters() {
+	for _, obj := range linter.policy.GetObjectsByKind(ClusterObject.Kind) {
+		cluster := obj.(*Cluster)
+		if !linter.clustersSet[cluster.Name] {
+			linter.report(LintInfo, cluster, "", "cluster is never used, '%s' label is never set to '%s'", LabelCluster, cluster.Name)
+		}
+	}
+}
+
+// GetDefaultColumns returns default set of columns to be displayed
+func (finding *LintFinding) GetDefaultColumns() []string {
+	return []string{"Severity", "Object", "Field", "Message"}
+}
+
+// AsColumns returns LintFinding representation as columns
+func (finding *LintFinding) AsColumns() map[string]string {
+	return map[string]string{
+		"Severity": finding.Severity,
+		"Object":   finding.Object,
+		"Field":    finding.Field,
+		"Message":  finding.Message,
+	}
+}
+
+// HasLintErrors returns true if there is at least one finding with 'error' severity in the list
+func HasLintErrors(findings []*LintFinding) bool {
+	for _, finding := range findings {
+		if finding.Severity == LintError {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,126 @@
+package lang
+
+import (
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestPolicyLinter(t *testing.T) {
+	policy := NewPolicy()
+
+	// services
+	addObject(policy, makeService("service1", 0))
+	addObject(policy, makeService("service2", 0))
+
+	// contract with one unreachable context and allocation keys referring to unknown variables
+	addObject(policy, &Contract{
+		TypeKind: ContractObject.GetTypeKind(),
+		Metadata: Metadata{Namespace: "main", Name: "contract1"},
+		Contexts: []*Context{
+			{
+				Name:     "context1",
+				Criteria: &Criteria{RequireAll: []string{"label1 == 'value1'"}},
+				Allocation: &Allocation{
+					Service: "service1",
+					Keys:    []string{"{{.User.Name}}", "{{.Labels.label1}}"},
+				},
+			},
+			{
+				Name:     "context2",
+				Criteria: &Criteria{RequireAll: []string{"label1 == 'value1'", "label2 == 'value2'"}},
+				Allocation: &Allocation{
+					Service: "service1",
+					Keys:    []string{"{{.Unknown}}", "{{.User.Unknown}}", "{{.Labels.label3}}"},
+				},
+			},
+			{
+				Name:     "context3",
+				Criteria: &Criteria{RequireAll: []string{"label2 == 'value2'"}},
+				Allocation: &Allocation{
+					Service: "service1",
+				},
+			},
+		},
+	})
+
+	// contract without consumers
+	addObject(policy, &Contract{
+		TypeKind: ContractObject.GetTypeKind(),
+		Metadata: Metadata{Namespace: "main", Name: "contract2"},
+		Contexts: []*Context{
+			{
+				Name:       "context",
+				Allocation: &Allocation{Service: "main/service1"},
+			},
+		},
+	})
+
+	// dependency
+	dependency := makeDependency("contract1")
+	dependency.Labels = map[string]string{"label1": "value1", LabelCluster: "cluster1"}
+	addObject(policy, dependency)
+
+	// rule referring to a label which is never set
+	rule := makeRule(10, "", 0, "label2")
+	rule.Criteria = &Criteria{RequireAll: []string{"label1 == 'value1' && label4 == 'value4'"}}
+	addObject(policy, rule)
+
+	// clusters
+	for _, name := range []string{"cluster1", "cluster2"} {
+		cluster := makeCluster("kubernetes", runtime.SystemNS)
+		cluster.Name = name
+		addObject(policy, cluster)
+	}
+
+	// labels which are never set can't be reported without users, as users could have any labels
+	findings := NewPolicyLinter(policy, nil).Lint()
+	for _, finding := range findings {
+		assert.NotContains(t, finding.Message, "label '", "Labels which are never set shouldn't be reported without users")
+	}
+
+	findings = NewPolicyLinter(policy, &GlobalUsers{Users: map[string]*User{"user": {Name: "user", Labels: map[string]string{"label5": "value5"}}}}).Lint()
+
+	type expected struct {
+		severity string
+		object   string
+		field    string
+	}
+	expectedFindings := []expected{
+		{LintInfo, "system/cluster/cluster2", ""},
+		{LintWarning, "main/contract/contract1", "Contexts[context2]"},
+		{LintError, "main/contract/contract1", "Contexts[context2].Allocation.Keys[{{.Unknown}}]"},
+		{LintError, "main/contract/contract1", "Contexts[context2].Allocation.Keys[{{.User.Unknown}}]"},
+		{LintWarning, "main/contract/contract1", "Contexts[context2].Allocation.Keys[{{.Labels.label3}}]"},
+		{LintWarning, "main/contract/contract2", ""},
+		{LintWarning, "main/rule/rule", "Criteria.RequireAll[label1 == 'value1' && label4 == 'value4']"},
+		{LintWarning, "main/service/service2", ""},
+	}
+
+	if !assert.Equal(t, len(expectedFindings), len(findings), "Number of lint findings should be correct") {
+		return
+	}
+	for _, e := range expectedFindings {
+		found := false
+		for _, finding := range findings {
+			if finding.Object == e.object && finding.Field == e.field {
+				assert.Equal(t, e.severity, finding.Severity, "Severity of lint finding for '%s' '%s' should be correct", e.object, e.field)
+				found = true
+			}
+		}
+		assert.True(t, found, "Lint finding for '%s' '%s' should be present", e.object, e.field)
+	}
+}
+
+func TestCriteriaSubsumes(t *testing.T) {
+	assert.True(t, criteriaSubsumes(nil, &Criteria{RequireAll: []string{"a == 'b'"}}))
+	assert.True(t, criteriaSubsumes(&Criteria{RequireAll: []string{"a == 'b'"}}, &Criteria{RequireAll: []string{"a == 'b'", "c == 'd'"}}))
+	assert.True(t, criteriaSubsumes(&Criteria{RequireAny: []string{"a == 'b'", "c == 'd'"}}, &Criteria{RequireAny: []string{"c == 'd'"}}))
+	assert.True(t, criteriaSubsumes(&Criteria{RequireAny: []string{"a == 'b'", "c == 'd'"}}, &Criteria{RequireAll: []string{"a == 'b'"}}))
+	assert.True(t, criteriaSubsumes(&Criteria{RequireNone: []string{"a == 'b'"}}, &Criteria{RequireNone: []string{"a == 'b'", "c == 'd'"}}))
+
+	assert.False(t, criteriaSubsumes(&Criteria{RequireAll: []string{"a == 'b'"}}, nil))
+	assert.False(t, criteriaSubsumes(&Criteria{RequireAll: []string{"a == 'b'", "c == 'd'"}}, &Criteria{RequireAll: []string{"a == 'b'"}}))
+	assert.False(t, criteriaSubsumes(&Criteria{RequireAny: []string{"a == 'b'"}}, &Criteria{RequireAny: []string{"a == 'b'", "c == 'd'"}}))
+	assert.False(t, criteriaSubsumes(&Criteria{RequireNone: []string{"a == 'b'"}}, &Criteria{}))
+}
@@ -11,6 +11,8 @@ var (
 		ClusterObject,
 		RuleObject,
 		ACLRuleObject,
+		ACLRoleObject,
+		QuotaObject,
 	}
 
 	policyObjectsMap = make(map[runtime.Kind]bool)
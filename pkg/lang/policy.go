@@ -40,9 +40,9 @@ func (policy *Policy) View(user *User) *PolicyView {
 	policy.once.Do(func() {
 		systemNamespace := policy.Namespace[runtime.SystemNS]
 		if systemNamespace != nil {
-			policy.aclResolver = NewACLResolver(systemNamespace.ACLRules)
+			policy.aclResolver = NewACLResolver(systemNamespace.ACLRules, systemNamespace.ACLRoles)
 		} else {
-			policy.aclResolver = NewACLResolver(NewGlobalRules())
+			policy.aclResolver = NewACLResolver(NewGlobalRules(), nil)
 		}
 	})
 	return NewPolicyView(policy, user)
@@ -15,6 +15,8 @@ type PolicyNamespace struct {
 	Rules        *GlobalRules         `validate:"required"`
 	ACLRules     *GlobalRules         `validate:"required"`
 	Dependencies *GlobalDependencies  `validate:"required"`
+	Quotas       map[string]*Quota    `validate:"dive"`
+	ACLRoles     map[string]*ACLRole  `validate:"dive"`
 }
 
 // NewPolicyNamespace creates a new PolicyNamespace
@@ -27,6 +29,8 @@ func NewPolicyNamespace(name string) *PolicyNamespace {
 		Rules:        NewGlobalRules(),
 		ACLRules:     NewGlobalRules(),
 		Dependencies: NewGlobalDependencies(),
+		Quotas:       make(map[string]*Quota),
+		ACLRoles:     make(map[string]*ACLRole),
 	}
 }
 
@@ -44,6 +48,10 @@ func (policyNamespace *PolicyNamespace) addObject(obj Base) error {
 		policyNamespace.ACLRules.addRule(obj.(*Rule))
 	case DependencyObject.Kind:
 		policyNamespace.Dependencies.addDependency(obj.(*Dependency))
+	case QuotaObject.Kind:
+		policyNamespace.Quotas[obj.GetName()] = obj.(*Quota)
+	case ACLRoleObject.Kind:
+		policyNamespace.ACLRoles[obj.GetName()] = obj.(*ACLRole)
 	default:
 		return fmt.Errorf("not supported by PolicyNamespace.addObject(): unknown kind %s", kind)
 	}
@@ -73,6 +81,16 @@ func (policyNamespace *PolicyNamespace) removeObject(obj Base) bool {
 		return policyNamespace.ACLRules.removeRule(obj.(*Rule))
 	case DependencyObject.Kind:
 		return policyNamespace.Dependencies.removeDependency(obj.(*Dependency))
+	case QuotaObject.Kind:
+		if _, exist := policyNamespace.Quotas[obj.GetName()]; exist {
+			delete(policyNamespace.Quotas, obj.GetName())
+			return true
+		}
+	case ACLRoleObject.Kind:
+		if _, exist := policyNamespace.ACLRoles[obj.GetName()]; exist {
+			delete(policyNamespace.ACLRoles, obj.GetName())
+			return true
+		}
 	}
 
 	return false
@@ -107,6 +125,14 @@ func (policyNamespace *PolicyNamespace) getObjectsByKind(kind string) []Base {
 				result = append(result, dependency)
 			}
 		}
+	case QuotaObject.Kind:
+		for _, quota := range policyNamespace.Quotas {
+			result = append(result, quota)
+		}
+	case ACLRoleObject.Kind:
+		for _, role := range policyNamespace.ACLRoles {
+			result = append(result, role)
+		}
 	default:
 		panic(fmt.Sprintf("not supported by PolicyNamespace.getObjectsByKind(): unknown kind %s", kind))
 	}
@@ -141,6 +167,14 @@ func (policyNamespace *PolicyNamespace) getObject(kind string, name string) (run
 		if result, ok = policyNamespace.Dependencies.DependencyMap[name]; !ok {
 			return nil, nil
 		}
+	case QuotaObject.Kind:
+		if result, ok = policyNamespace.Quotas[name]; !ok {
+			return nil, nil
+		}
+	case ACLRoleObject.Kind:
+		if result, ok = policyNamespace.ACLRoles[name]; !ok {
+			return nil, nil
+		}
 	default:
 		return nil, fmt.Errorf("not supported by PolicyNamespace.getObject(): unknown kind %s, %s", kind, name)
 	}
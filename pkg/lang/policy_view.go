@@ -79,3 +79,16 @@ func (view *PolicyView) CanConsume(service *Service) (bool, error) {
 	}
 	return true, nil
 }
+
+// CanPerform returns if user has permissions to perform a given operation, which is not tied to policy objects
+// (e.g. reset actual state or view revisions). If user has no permissions, then ACL error will be returned
+func (view *PolicyView) CanPerform(operation string) (bool, error) {
+	allowed, err := view.Policy.aclResolver.IsUserOperationAllowed(view.User, operation)
+	if err != nil {
+		return false, err
+	}
+	if !allowed {
+		return false, fmt.Errorf("user '%s' doesn't have ACL permissions to perform operation '%s'", view.User.Name, operation)
+	}
+	return true, nil
+}
@@ -0,0 +1,64 @@
+package lang
+
+import (
+	"github.com/Aptomi/aptomi/pkg/lang/expression"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+)
+
+// QuotaObject is an informational data structure with Kind and Constructor for Quota
+var QuotaObject = &runtime.Info{
+	Kind:        "quota",
+	Storable:    true,
+	Versioned:   true,
+	Deletable:   true,
+	Constructor: func() runtime.Object { return &Quota{} },
+}
+
+const (
+	// QuotaScopeUser counts usage per user, who declared dependencies
+	QuotaScopeUser = "user"
+
+	// QuotaScopeLabel counts usage per group of users, which have the same value of a given user label
+	QuotaScopeLabel = "label"
+
+	// QuotaScopeNamespace counts usage per namespace, in which dependencies are declared
+	QuotaScopeNamespace = "namespace"
+
+	// QuotaScopeCluster counts usage per cluster, in which service instances are placed
+	QuotaScopeCluster = "cluster"
+)
+
+// Quota limits the number of dependencies and service instances, which can be allocated within a certain scope
+// (per user, per group of users with the same label, per namespace, or per cluster).
+//
+// Quotas defined in 'system' namespace apply to all dependencies, while quotas defined in any other namespace only
+// apply to dependencies declared in that namespace. Quotas are evaluated during policy resolution, and dependencies
+// exceeding a quota will not be resolved.
+type Quota struct {
+	runtime.TypeKind `yaml:",inline"`
+	Metadata         `validate:"required"`
+
+	// Criteria - if it gets evaluated to true during policy resolution, then quota will be applied to a dependency.
+	// It's an optional field, so if it's nil then quota applies to all dependencies
+	Criteria *Criteria `validate:"omitempty"`
+
+	// Scope defines how usage is counted (one of 'user', 'label', 'namespace', 'cluster')
+	Scope string `validate:"quotaScope"`
+
+	// Label is a name of the user label, which defines a group of users (only relevant for 'label' scope)
+	Label string `yaml:"label,omitempty" validate:"omitempty,identifier"`
+
+	// MaxDependencies is the maximum number of dependencies within the scope (zero means no limit)
+	MaxDependencies int `yaml:"max-dependencies,omitempty" validate:"min=0"`
+
+	// MaxInstances is the maximum number of service instances within the scope (zero means no limit)
+	MaxInstances int `yaml:"max-instances,omitempty" validate:"min=0"`
+}
+
+// Matches returns true if a quota applies to a dependency with given parameters
+func (quota *Quota) Matches(params *expression.Parameters, cache *expression.Cache) (bool, error) {
+	if quota.Criteria == nil {
+		return true, nil
+	}
+	return quota.Criteria.allows(params, cache)
+}
@@ -49,6 +49,10 @@ type RuleActions struct {
 	// Ingress defines whether ingress traffic should be rejected
 	Ingress IngressAction `yaml:"ingress,omitempty" validate:"omitempty,allowReject"`
 
+	// MaxTTL defines the maximum time-to-live allowed for dependency (e.g. '24h'). If it's set, a dependency will
+	// be rejected unless it has TTL set and that TTL doesn't exceed the maximum
+	MaxTTL string `yaml:"max-ttl,omitempty" validate:"omitempty,duration"`
+
 	// AddRole field is only relevant for ACL rules (have to keep it in this class due to the lack of generics).
 	// Key in the map is role ID, while value is a set of comma-separated namespaces to which this role applies
 	AddRole map[string]string `yaml:"add-role,omitempty" validate:"omitempty,addRoleNS"`
@@ -2,6 +2,7 @@ package lang
 
 import (
 	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/util"
 )
 
 // ACLRule defines which users have which roles in Aptomi. They should be configured by Aptomi domain admins in the
@@ -21,32 +22,89 @@ var ACLRuleObject = &runtime.Info{
 // Allows to define a role which spans across all namespaces (e.g. "domain admin")
 const namespaceAll = "*"
 
+// ACLRoleObject is an informational data structure with Kind and Constructor for ACLRole
+var ACLRoleObject = &runtime.Info{
+	Kind:        "aclrole",
+	Storable:    true,
+	Versioned:   true,
+	Deletable:   true,
+	Constructor: func() runtime.Object { return &ACLRole{} },
+}
+
 // ACLRole is a struct for defining user roles and their privileges.
 // Aptomi has 4 built-in user roles: domain admin, namespace admin, service consumer, and nobody.
 // Domain admin has full access rights to all namespaces. It can manage global objects in 'system' namespace (clusters,
-// rules, and ACL rules).
+// rules, ACL rules, and ACL roles).
 // Namespace admin has full access right to a given set of namespaces, but it cannot global objects in 'system' namespace (clusters,
-// rules, and ACL rules).
+// rules, ACL rules, and ACL roles).
 // Service consumer can only consume services within a given set of namespaces. Service consumption is treated as capability
 // to instantiate services in a given namespace.
 // Nobody cannot do anything except viewing the policy.
+// Besides policy objects, roles also define which operations users can perform (e.g. reset actual state, trigger
+// enforcement, view revisions, event logs, diagrams and endpoints of other users).
+// Custom roles can be defined in the policy as 'aclrole' objects in 'system' namespace, and then assigned to users
+// via ACL rules in the same way as built-in roles.
 type ACLRole struct {
-	ID         string
-	Name       string
-	Privileges *Privileges
+	runtime.TypeKind `yaml:",inline"`
+	Metadata         `validate:"required"`
+
+	// Privileges defines which objects users with this role are allowed to view and manage
+	Privileges *Privileges `validate:"required"`
 }
 
 // Privileges defines a set of privileges for a particular role in Aptomi
 type Privileges struct {
 	// AllNamespaces, when set to true, indicated that user privileges apply to all namespaces. Otherwise it applies
 	// to a set of given namespaces
-	AllNamespaces bool
+	AllNamespaces bool `yaml:"all-namespaces,omitempty"`
 
 	// NamespaceObjects specifies whether or not this role can view/manage a certain object kind within a non-system namespace
-	NamespaceObjects map[string]*Privilege
+	NamespaceObjects map[string]*Privilege `yaml:"namespace-objects,omitempty" validate:"omitempty,privilegeKinds"`
 
 	// GlobalObjects specifies whether or not this role can view/manage a certain object kind within a system namespace
-	GlobalObjects map[string]*Privilege
+	GlobalObjects map[string]*Privilege `yaml:"global-objects,omitempty" validate:"omitempty,privilegeKinds"`
+
+	// Operations specifies which operations, not tied to policy objects, this role is allowed to perform. Operations
+	// are not namespace-scoped, so they are allowed if the role is assigned to a user in at least one namespace
+	Operations []string `yaml:"operations,omitempty" validate:"omitempty,dive,operation"`
+}
+
+const (
+	// OperationResetActualState allows to reset actual state, forcing Aptomi to re-enforce the whole policy
+	OperationResetActualState = "reset-actual-state"
+
+	// OperationTriggerEnforcement allows to trigger policy enforcement without waiting for the next enforcement cycle
+	OperationTriggerEnforcement = "trigger-enforcement"
+
+	// OperationViewRevisions allows to view revisions, including their progress
+	OperationViewRevisions = "view-revisions"
+
+	// OperationViewEventLogs allows to view event logs of policy resolution (e.g. messages of what-if simulation)
+	OperationViewEventLogs = "view-event-logs"
+
+	// OperationViewDiagrams allows to view policy and instance diagrams
+	OperationViewDiagrams = "view-diagrams"
+
+	// OperationViewAllEndpoints allows to view endpoints of dependencies declared by other users
+	OperationViewAllEndpoints = "view-all-endpoints"
+
+	// OperationRefreshUsers allows to force reloading of users from the user directory
+	OperationRefreshUsers = "refresh-users"
+
+	// OperationBackup allows to download backup of all data stored by Aptomi
+	OperationBackup = "backup"
+)
+
+// Operations is the list of all operations, which are not tied to policy objects, but still require privileges
+var Operations = []string{
+	OperationResetActualState,
+	OperationTriggerEnforcement,
+	OperationViewRevisions,
+	OperationViewEventLogs,
+	OperationViewDiagrams,
+	OperationViewAllEndpoints,
+	OperationRefreshUsers,
+	OperationBackup,
 }
 
 // Returns privileges for a given object
@@ -63,13 +121,18 @@ func (privileges *Privileges) getObjectPrivileges(obj Base) *Privilege {
 	return result
 }
 
+// Returns true if a given operation is allowed
+func (privileges *Privileges) allowsOperation(operation string) bool {
+	return util.ContainsString(privileges.Operations, operation)
+}
+
 // Privilege is a unit of privilege for any single given object
 type Privilege struct {
 	// View indicates whether or not a user can view an object (R)
-	View bool
+	View bool `yaml:"view,omitempty"`
 
 	// Manage indicates whether or not a user can manage an object, i.e. perform operations (CUD)
-	Manage bool
+	Manage bool `yaml:"manage,omitempty"`
 }
 
 // Full access privilege
@@ -88,8 +151,11 @@ var noAccess = &Privilege{}
 
 // Domain admin role
 var domainAdmin = &ACLRole{
-	ID:   "domain-admin",
-	Name: "Domain Admin",
+	TypeKind: ACLRoleObject.GetTypeKind(),
+	Metadata: Metadata{
+		Namespace: runtime.SystemNS,
+		Name:      "domain-admin",
+	},
 	Privileges: &Privileges{
 		AllNamespaces: true,
 		NamespaceObjects: map[string]*Privilege{
@@ -97,73 +163,114 @@ var domainAdmin = &ACLRole{
 			ContractObject.Kind:   fullAccess,
 			DependencyObject.Kind: fullAccess,
 			RuleObject.Kind:       fullAccess,
+			QuotaObject.Kind:      fullAccess,
 		},
 		GlobalObjects: map[string]*Privilege{
 			ClusterObject.Kind: fullAccess,
 			RuleObject.Kind:    fullAccess,
 			ACLRuleObject.Kind: fullAccess,
+			ACLRoleObject.Kind: fullAccess,
+			QuotaObject.Kind:   fullAccess,
+		},
+		Operations: []string{
+			OperationResetActualState,
+			OperationTriggerEnforcement,
+			OperationViewRevisions,
+			OperationViewEventLogs,
+			OperationViewDiagrams,
+			OperationViewAllEndpoints,
+			OperationRefreshUsers,
+			OperationBackup,
 		},
 	},
 }
 
 // Namespace admin role
 var namespaceAdmin = &ACLRole{
-	ID:   "namespace-admin",
-	Name: "Namespace Admin",
+	TypeKind: ACLRoleObject.GetTypeKind(),
+	Metadata: Metadata{
+		Namespace: runtime.SystemNS,
+		Name:      "namespace-admin",
+	},
 	Privileges: &Privileges{
 		NamespaceObjects: map[string]*Privilege{
 			ServiceObject.Kind:    fullAccess,
 			ContractObject.Kind:   fullAccess,
 			DependencyObject.Kind: fullAccess,
 			RuleObject.Kind:       fullAccess,
+			QuotaObject.Kind:      viewAccess,
 		},
 		GlobalObjects: map[string]*Privilege{
 			ClusterObject.Kind: viewAccess,
 			RuleObject.Kind:    viewAccess,
 			ACLRuleObject.Kind: viewAccess,
+			ACLRoleObject.Kind: viewAccess,
+			QuotaObject.Kind:   viewAccess,
+		},
+		Operations: []string{
+			OperationViewRevisions,
+			OperationViewEventLogs,
+			OperationViewDiagrams,
+			OperationViewAllEndpoints,
 		},
 	},
 }
 
 // Service consumer role
 var serviceConsumer = &ACLRole{
-	ID:   "service-consumer",
-	Name: "Service Consumer",
+	TypeKind: ACLRoleObject.GetTypeKind(),
+	Metadata: Metadata{
+		Namespace: runtime.SystemNS,
+		Name:      "service-consumer",
+	},
 	Privileges: &Privileges{
 		NamespaceObjects: map[string]*Privilege{
 			ServiceObject.Kind:    viewAccess,
 			ContractObject.Kind:   viewAccess,
 			DependencyObject.Kind: fullAccess,
 			RuleObject.Kind:       viewAccess,
+			QuotaObject.Kind:      viewAccess,
 		},
 		GlobalObjects: map[string]*Privilege{
 			ClusterObject.Kind: viewAccess,
 			RuleObject.Kind:    viewAccess,
 			ACLRuleObject.Kind: viewAccess,
+			ACLRoleObject.Kind: viewAccess,
+			QuotaObject.Kind:   viewAccess,
+		},
+		Operations: []string{
+			OperationViewRevisions,
+			OperationViewEventLogs,
 		},
 	},
 }
 
 // Nobody role
 var nobody = &ACLRole{
-	ID:   "nobody",
-	Name: "Nobody",
+	TypeKind: ACLRoleObject.GetTypeKind(),
+	Metadata: Metadata{
+		Namespace: runtime.SystemNS,
+		Name:      "nobody",
+	},
 	Privileges: &Privileges{
 		NamespaceObjects: map[string]*Privilege{
 			ServiceObject.Kind:    viewAccess,
 			ContractObject.Kind:   viewAccess,
 			DependencyObject.Kind: viewAccess,
 			RuleObject.Kind:       viewAccess,
+			QuotaObject.Kind:      viewAccess,
 		},
 		GlobalObjects: map[string]*Privilege{
 			ClusterObject.Kind: viewAccess,
 			RuleObject.Kind:    viewAccess,
 			ACLRuleObject.Kind: viewAccess,
+			ACLRoleObject.Kind: viewAccess,
+			QuotaObject.Kind:   viewAccess,
 		},
 	},
 }
 
-// ACLRolesOrderedList represents the ordered list of ACL roles (from most "powerful" to least "powerful")
+// ACLRolesOrderedList represents the ordered list of built-in ACL roles (from most "powerful" to least "powerful")
 var ACLRolesOrderedList = []*ACLRole{
 	domainAdmin,
 	namespaceAdmin,
@@ -171,10 +278,10 @@ var ACLRolesOrderedList = []*ACLRole{
 	nobody,
 }
 
-// ACLRolesMap represents the map of ACL roles (Role ID -> Role)
+// ACLRolesMap represents the map of built-in ACL roles (Role ID -> Role)
 var ACLRolesMap = map[string]*ACLRole{
-	domainAdmin.ID:     domainAdmin,
-	namespaceAdmin.ID:  namespaceAdmin,
-	serviceConsumer.ID: serviceConsumer,
-	nobody.ID:          nobody,
+	domainAdmin.Name:     domainAdmin,
+	namespaceAdmin.Name:  namespaceAdmin,
+	serviceConsumer.Name: serviceConsumer,
+	nobody.Name:          nobody,
 }
@@ -10,14 +10,24 @@ import (
 // objects they access
 type ACLResolver struct {
 	rules        []*ACLRule
+	roles        map[string]*ACLRole
 	cache        *expression.Cache
 	roleMapCache sync.Map
 }
 
-// NewACLResolver creates a new ACLResolver
-func NewACLResolver(globalRules *GlobalRules) *ACLResolver {
+// NewACLResolver creates a new ACLResolver, given a set of ACL rules and a set of custom ACL roles defined in the
+// policy (in addition to built-in roles). Custom roles cannot override built-in roles
+func NewACLResolver(globalRules *GlobalRules, customRoles map[string]*ACLRole) *ACLResolver {
+	roles := make(map[string]*ACLRole)
+	for id, role := range customRoles {
+		roles[id] = role
+	}
+	for id, role := range ACLRolesMap {
+		roles[id] = role
+	}
 	return &ACLResolver{
 		rules:        globalRules.GetRulesSortedByWeight(),
+		roles:        roles,
 		cache:        expression.NewCache(),
 		roleMapCache: sync.Map{},
 	}
@@ -30,15 +40,38 @@ func (resolver *ACLResolver) GetUserPrivileges(user *User, obj Base) (*Privilege
 		return nil, err
 	}
 
-	// figure out which role's privileges apply
-	for _, role := range ACLRolesOrderedList {
-		namespaceSpan := roleMap[role.ID]
+	// every user has privileges of 'nobody' role. on top of that, combine privileges of all roles which apply
+	basePrivilege := nobody.Privileges.getObjectPrivileges(obj)
+	result := &Privilege{View: basePrivilege.View, Manage: basePrivilege.Manage}
+	for roleID, namespaceSpan := range roleMap {
 		if namespaceSpan[namespaceAll] || namespaceSpan[obj.GetNamespace()] {
-			return role.Privileges.getObjectPrivileges(obj), nil
+			privilege := resolver.roles[roleID].Privileges.getObjectPrivileges(obj)
+			result.View = result.View || privilege.View
+			result.Manage = result.Manage || privilege.Manage
+		}
+	}
+
+	return result, nil
+}
+
+// IsUserOperationAllowed determines whether a given user is allowed to perform a given operation, which is not tied
+// to policy objects. Operation is allowed if at least one of user roles allows it, regardless of namespaces
+func (resolver *ACLResolver) IsUserOperationAllowed(user *User, operation string) (bool, error) {
+	roleMap, err := resolver.GetUserRoleMap(user)
+	if err != nil {
+		return false, err
+	}
+
+	if nobody.Privileges.allowsOperation(operation) {
+		return true, nil
+	}
+	for roleID, namespaceSpan := range roleMap {
+		if len(namespaceSpan) > 0 && resolver.roles[roleID].Privileges.allowsOperation(operation) {
+			return true, nil
 		}
 	}
 
-	return nobody.Privileges.getObjectPrivileges(obj), nil
+	return false, nil
 }
 
 // GetUserRoleMap returns the map role ID -> to which namespaces this role applies, for a given user.
@@ -55,8 +88,8 @@ func (resolver *ACLResolver) GetUserRoleMap(user *User) (map[string]map[string]b
 	result := NewRuleActionResult(NewLabelSet(make(map[string]string)))
 	if user.DomainAdmin {
 		// this user is explicitly specified as domain admin
-		result.RoleMap[domainAdmin.ID] = make(map[string]bool)
-		result.RoleMap[domainAdmin.ID][namespaceAll] = true
+		result.RoleMap[domainAdmin.Name] = make(map[string]bool)
+		result.RoleMap[domainAdmin.Name][namespaceAll] = true
 	} else {
 		// we need to run this user through ACL list
 		params := expression.NewParams(user.Labels, nil)
@@ -71,6 +104,18 @@ func (resolver *ACLResolver) GetUserRoleMap(user *User) (map[string]map[string]b
 		}
 	}
 
+	// skip non-existing roles, and mark roles which cover all namespaces
+	for roleID, nsMap := range result.RoleMap {
+		role := resolver.roles[roleID]
+		if role == nil {
+			delete(result.RoleMap, roleID)
+			continue
+		}
+		if role.Privileges.AllNamespaces {
+			nsMap[namespaceAll] = true
+		}
+	}
+
 	resolver.roleMapCache.Store(user.Name, result.RoleMap)
 	return result.RoleMap, nil
 }
@@ -32,15 +32,19 @@ func (privileges testCaseObjPrivileges) print(t *testing.T, testCase aclTestCase
 }
 
 func runACLTests(testCases []aclTestCase, rules []*ACLRule, t *testing.T) {
+	runACLTestsWithRoles(testCases, rules, nil, t)
+}
+
+func runACLTestsWithRoles(testCases []aclTestCase, rules []*ACLRule, roles map[string]*ACLRole, t *testing.T) {
 	globalRules := NewGlobalRules()
 	globalRules.addRule(rules...)
-	resolver := NewACLResolver(globalRules)
+	resolver := NewACLResolver(globalRules, roles)
 	for _, tc := range testCases {
 		roleMap, err := resolver.GetUserRoleMap(tc.user)
 		if !assert.NoError(t, err, "User role map should be retrieved successfully") {
 			continue
 		}
-		if !assert.Equal(t, tc.expected, roleMap[tc.role.ID][tc.namespace], "User role map should be correct") {
+		if !assert.Equal(t, tc.expected, roleMap[tc.role.Name][tc.namespace], "User role map should be correct") {
 			tc.print(t)
 		}
 
@@ -68,7 +72,7 @@ func TestAclResolver(t *testing.T) {
 			Weight:   100,
 			Criteria: &Criteria{RequireAll: []string{"is_domain_admin"}},
 			Actions: &RuleActions{
-				AddRole: map[string]string{domainAdmin.ID: namespaceAll},
+				AddRole: map[string]string{domainAdmin.Name: namespaceAll},
 			},
 		},
 		// namespace admins for 'main' namespace
@@ -81,7 +85,7 @@ func TestAclResolver(t *testing.T) {
 			Weight:   200,
 			Criteria: &Criteria{RequireAll: []string{"is_namespace_admin"}},
 			Actions: &RuleActions{
-				AddRole: map[string]string{namespaceAdmin.ID: "main"},
+				AddRole: map[string]string{namespaceAdmin.Name: "main"},
 			},
 		},
 		// service consumers for 'main2' namespace
@@ -94,7 +98,7 @@ func TestAclResolver(t *testing.T) {
 			Weight:   300,
 			Criteria: &Criteria{RequireAll: []string{"is_consumer"}},
 			Actions: &RuleActions{
-				AddRole: map[string]string{serviceConsumer.ID: "main1, main2 ,main3,main4"},
+				AddRole: map[string]string{serviceConsumer.Name: "main1, main2 ,main3,main4"},
 			},
 		},
 		// bogus rule
@@ -175,3 +179,169 @@ func TestAclResolverAdminUser(t *testing.T) {
 	}
 	runACLTests(testCases, rules, t)
 }
+
+func TestAclResolverCustomRoles(t *testing.T) {
+	clusterOperator := &ACLRole{
+		TypeKind: ACLRoleObject.GetTypeKind(),
+		Metadata: Metadata{
+			Namespace: runtime.SystemNS,
+			Name:      "cluster-operator",
+		},
+		Privileges: &Privileges{
+			AllNamespaces: true,
+			GlobalObjects: map[string]*Privilege{
+				ClusterObject.Kind: fullAccess,
+			},
+			NamespaceObjects: map[string]*Privilege{
+				ServiceObject.Kind: viewAccess,
+			},
+		},
+	}
+	roles := map[string]*ACLRole{clusterOperator.Name: clusterOperator}
+
+	var rules = []*ACLRule{
+		{
+			TypeKind: ACLRuleObject.GetTypeKind(),
+			Metadata: Metadata{
+				Namespace: runtime.SystemNS,
+				Name:      "is_operator",
+			},
+			Weight:   100,
+			Criteria: &Criteria{RequireAll: []string{"is_operator"}},
+			Actions: &RuleActions{
+				AddRole: map[string]string{clusterOperator.Name: runtime.SystemNS},
+			},
+		},
+		{
+			TypeKind: ACLRuleObject.GetTypeKind(),
+			Metadata: Metadata{
+				Namespace: runtime.SystemNS,
+				Name:      "is_consumer",
+			},
+			Weight:   200,
+			Criteria: &Criteria{RequireAll: []string{"is_consumer"}},
+			Actions: &RuleActions{
+				AddRole: map[string]string{serviceConsumer.Name: "main"},
+			},
+		},
+	}
+
+	testCases := []aclTestCase{
+		{
+			user:      &User{Name: "1", Labels: map[string]string{"is_operator": "true"}},
+			role:      clusterOperator,
+			namespace: namespaceAll,
+			expected:  true,
+			objectPrivileges: []testCaseObjPrivileges{
+				{obj: &Cluster{TypeKind: ClusterObject.GetTypeKind(), Metadata: Metadata{Namespace: runtime.SystemNS}}, expected: fullAccess},
+				{obj: &Rule{TypeKind: RuleObject.GetTypeKind(), Metadata: Metadata{Namespace: runtime.SystemNS}}, expected: viewAccess},
+				{obj: &Service{TypeKind: ServiceObject.GetTypeKind(), Metadata: Metadata{Namespace: "main"}}, expected: viewAccess},
+				{obj: &Dependency{TypeKind: DependencyObject.GetTypeKind(), Metadata: Metadata{Namespace: "main"}}, expected: viewAccess},
+			},
+		},
+		{
+			// privileges from multiple roles are combined
+			user:      &User{Name: "2", Labels: map[string]string{"is_operator": "true", "is_consumer": "true"}},
+			role:      serviceConsumer,
+			namespace: "main",
+			expected:  true,
+			objectPrivileges: []testCaseObjPrivileges{
+				{obj: &Cluster{TypeKind: ClusterObject.GetTypeKind(), Metadata: Metadata{Namespace: runtime.SystemNS}}, expected: fullAccess},
+				{obj: &Rule{TypeKind: RuleObject.GetTypeKind(), Metadata: Metadata{Namespace: runtime.SystemNS}}, expected: viewAccess},
+				{obj: &Dependency{TypeKind: DependencyObject.GetTypeKind(), Metadata: Metadata{Namespace: "main"}}, expected: fullAccess},
+			},
+		},
+	}
+
+	runACLTestsWithRoles(testCases, rules, roles, t)
+}
+
+func TestAclResolverOperations(t *testing.T) {
+	auditor := &ACLRole{
+		TypeKind: ACLRoleObject.GetTypeKind(),
+		Metadata: Metadata{
+			Namespace: runtime.SystemNS,
+			Name:      "auditor",
+		},
+		Privileges: &Privileges{
+			Operations: []string{OperationViewRevisions, OperationViewDiagrams},
+		},
+	}
+	roles := map[string]*ACLRole{auditor.Name: auditor}
+
+	var rules = []*ACLRule{}
+	for _, role := range []*ACLRole{namespaceAdmin, serviceConsumer, auditor} {
+		rules = append(rules, &ACLRule{
+			TypeKind: ACLRuleObject.GetTypeKind(),
+			Metadata: Metadata{
+				Namespace: runtime.SystemNS,
+				Name:      role.Name,
+			},
+			Weight:   len(rules),
+			Criteria: &Criteria{RequireAll: []string{"role == '" + role.Name + "'"}},
+			Actions: &RuleActions{
+				AddRole: map[string]string{role.Name: "main"},
+			},
+		})
+	}
+
+	globalRules := NewGlobalRules()
+	globalRules.addRule(rules...)
+	resolver := NewACLResolver(globalRules, roles)
+
+	testCases := []struct {
+		user     *User
+		expected map[string]bool
+	}{
+		{
+			user: &User{Name: "domain-admin", DomainAdmin: true},
+			expected: map[string]bool{
+				OperationResetActualState:   true,
+				OperationTriggerEnforcement: true,
+				OperationViewRevisions:      true,
+				OperationViewEventLogs:      true,
+				OperationViewDiagrams:       true,
+				OperationViewAllEndpoints:   true,
+				OperationRefreshUsers:       true,
+				OperationBackup:             true,
+			},
+		},
+		{
+			user: &User{Name: "namespace-admin", Labels: map[string]string{"role": namespaceAdmin.Name}},
+			expected: map[string]bool{
+				OperationViewRevisions:    true,
+				OperationViewEventLogs:    true,
+				OperationViewDiagrams:     true,
+				OperationViewAllEndpoints: true,
+			},
+		},
+		{
+			user: &User{Name: "service-consumer", Labels: map[string]string{"role": serviceConsumer.Name}},
+			expected: map[string]bool{
+				OperationViewRevisions: true,
+				OperationViewEventLogs: true,
+			},
+		},
+		{
+			user: &User{Name: "auditor", Labels: map[string]string{"role": auditor.Name}},
+			expected: map[string]bool{
+				OperationViewRevisions: true,
+				OperationViewDiagrams:  true,
+			},
+		},
+		{
+			user:     &User{Name: "nobody", Labels: map[string]string{"role": "unknown"}},
+			expected: map[string]bool{},
+		},
+	}
+
+	for _, tc := range testCases {
+		for _, operation := range Operations {
+			allowed, err := resolver.IsUserOperationAllowed(tc.user, operation)
+			if !assert.NoError(t, err, "User operation privileges should be retrieved successfully") {
+				continue
+			}
+			assert.Equal(t, tc.expected[operation], allowed, "User '%s' privileges for operation '%s' should be correct", tc.user.Name, operation)
+		}
+	}
+}
@@ -2,6 +2,7 @@ package lang
 
 import (
 	"strings"
+	"time"
 )
 
 // Reject is a special constant that is used in rule actions for rejecting dependencies, ingress traffic, etc
@@ -18,6 +19,9 @@ type RuleActionResult struct {
 	RejectDependency bool
 	RejectIngress    bool
 
+	// MaxTTL is the maximum time-to-live for a dependency (the lowest one across all matched rules), zero if not set
+	MaxTTL time.Duration
+
 	ChangedLabelsOnLastApply bool
 	Labels                   *LabelSet
 
@@ -37,18 +41,19 @@ func (rule *Rule) ApplyActions(result *RuleActionResult) {
 	result.RejectDependency = string(rule.Actions.Dependency) == Reject
 	result.RejectIngress = string(rule.Actions.Ingress) == Reject
 
+	if len(rule.Actions.MaxTTL) > 0 {
+		maxTTL, err := time.ParseDuration(rule.Actions.MaxTTL)
+		if err == nil && (result.MaxTTL <= 0 || maxTTL < result.MaxTTL) {
+			result.MaxTTL = maxTTL
+		}
+	}
+
 	result.ChangedLabelsOnLastApply = false
 	if rule.Actions.ChangeLabels != nil {
 		result.ChangedLabelsOnLastApply = result.Labels.ApplyTransform(rule.Actions.ChangeLabels)
 	}
 
 	for roleID, namespaceList := range rule.Actions.AddRole {
-		role := ACLRolesMap[roleID]
-		if role == nil {
-			// skip non-existing roles
-			continue
-		}
-
 		nsMap := result.RoleMap[roleID]
 		if nsMap == nil {
 			nsMap = make(map[string]bool)
@@ -60,10 +65,5 @@ func (rule *Rule) ApplyActions(result *RuleActionResult) {
 		for _, namespace := range namespaces {
 			nsMap[strings.TrimSpace(namespace)] = true
 		}
-
-		// if role covers all namespaces, mark it as well
-		if role.Privileges.AllNamespaces {
-			nsMap[namespaceAll] = true
-		}
 	}
 }
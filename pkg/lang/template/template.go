@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"strings"
 	t "text/template"
+	"text/template/parse"
 )
 
 // Template struct contains text template string as well as its compiled version
@@ -98,3 +99,50 @@ func (template *Template) Evaluate(params *Parameters) (string, error) {
 
 	return result, nil
 }
+
+// Fields returns the list of fields referenced in the template relative to its root (e.g. '.User.Name' will be
+// returned as ["User", "Name"]). Fields referenced inside 'range' and 'with' blocks are skipped, as dot gets
+// redefined there
+func (template *Template) Fields() [][]string {
+	result := [][]string{}
+	if template.templateCompiled.Tree != nil {
+		collectFields(template.templateCompiled.Tree.Root, &result)
+	}
+	return result
+}
+
+// collectFields walks template parse tree and collects referenced fields
+func collectFields(node parse.Node, result *[][]string) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			collectFields(child, result)
+		}
+	case *parse.ActionNode:
+		collectFields(n.Pipe, result)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			collectFields(cmd, result)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			collectFields(arg, result)
+		}
+	case *parse.FieldNode:
+		*result = append(*result, n.Ident)
+	case *parse.IfNode:
+		collectFields(n.Pipe, result)
+		collectFields(n.List, result)
+		collectFields(n.ElseList, result)
+	case *parse.RangeNode:
+		collectFields(n.Pipe, result)
+	case *parse.WithNode:
+		collectFields(n.Pipe, result)
+	}
+}
@@ -93,3 +93,9 @@ func TestTemplateEvaluation(t *testing.T) {
 	}
 
 }
+
+func TestTemplateFields(t *testing.T) {
+	tmpl, err := NewTemplate("{{.User.Name}}-{{if .Labels.label1}}{{.Labels.label1}}{{end}}-{{default .Labels.label2 \"x\"}}")
+	assert.NoError(t, err, "Template should be compiled successfully")
+	assert.Equal(t, [][]string{{"User", "Name"}, {"Labels", "label1"}, {"Labels", "label1"}, {"Labels", "label2"}}, tmpl.Fields(), "Template fields should be correct")
+}
@@ -14,6 +14,7 @@ import (
 	"reflect"
 	"regexp"
 	"strings"
+	"time"
 )
 
 // Constants
@@ -23,6 +24,7 @@ var (
 	codeTypes       = []string{"helm", "raw"}
 	labelOpsKeys    = []string{"set", "remove"}
 	allowReject     = []string{"allow", "reject"}
+	quotaScopes     = []string{QuotaScopeUser, QuotaScopeLabel, QuotaScopeNamespace, QuotaScopeCluster}
 )
 
 // Custom type for context key, so we don't have to use 'string' directly
@@ -70,10 +72,16 @@ func NewPolicyValidator(policy *Policy) *PolicyValidator {
 	_ = result.RegisterValidation("labelOperations", validateLabelOperations)
 	_ = result.RegisterValidation("allowReject", validateAllowRejectAction)
 	_ = result.RegisterValidation("addRoleNS", validateACLRoleActionMap)
+	_ = result.RegisterValidation("duration", validateDuration)
+	_ = result.RegisterValidation("quotaScope", validateQuotaScope)
+	_ = result.RegisterValidation("privilegeKinds", validatePrivilegeKinds)
+	_ = result.RegisterValidation("operation", validateOperation)
 
 	// validators with context containing policy
-	result.RegisterStructValidation(validateRule, Rule{})
 	result.RegisterStructValidation(validateCluster, Cluster{})
+	result.RegisterStructValidation(validateQuota, Quota{})
+	result.RegisterStructValidation(validateACLRole, ACLRole{})
+	result.RegisterStructValidationCtx(validateRule, Rule{})
 	result.RegisterStructValidationCtx(validateService, Service{})
 	result.RegisterStructValidationCtx(validateDependency, Dependency{})
 	result.RegisterStructValidationCtx(validateContract, Contract{})
@@ -134,7 +142,23 @@ func NewPolicyValidator(policy *Policy) *PolicyValidator {
 		},
 		{
 			tag:         "addRoleNS",
-			translation: fmt.Sprintf("{0} must be a valid role assignment map (key must be in %s, namespace list must be comma-separated identifiers/wildcards)", util.GetSortedStringKeys(ACLRolesMap)),
+			translation: fmt.Sprintf("{0} must be a valid role assignment map (key must be a role name, namespace list must be comma-separated identifiers/wildcards)"),
+		},
+		{
+			tag:         "privilegeKinds",
+			translation: fmt.Sprintf("{0} must be a valid privilege map (key must be a policy object kind)"),
+		},
+		{
+			tag:         "operation",
+			translation: fmt.Sprintf("{0} must be in %s, but found '{1}'", Operations),
+		},
+		{
+			tag:         "duration",
+			translation: fmt.Sprintf("{0} must be a valid positive duration (e.g. '30m', '8h'), but found '{1}'"),
+		},
+		{
+			tag:         "quotaScope",
+			translation: fmt.Sprintf("{0} must be in %s, but found '{1}'", quotaScopes),
 		},
 		// dynamic/custom
 		{
@@ -161,6 +185,18 @@ func NewPolicyValidator(policy *Policy) *PolicyValidator {
 			tag:         "aclRuleActions",
 			translation: fmt.Sprintf("{0} is a required field for ACL rule. Must specify role assignment map"),
 		},
+		{
+			tag:         "quotaLimits",
+			translation: fmt.Sprintf("{0} must have at least one limit defined (max-dependencies or max-instances)"),
+		},
+		{
+			tag:         "quotaLabel",
+			translation: fmt.Sprintf("{0} is a required field for quota with '%s' scope", QuotaScopeLabel),
+		},
+		{
+			tag:         "builtinRole",
+			translation: fmt.Sprintf("{0} must not be one of built-in roles %s, but found '{1}'", util.GetSortedStringKeys(ACLRolesMap)),
+		},
 		{
 			tag:         "systemNS",
 			translation: fmt.Sprintf("{0} must be '%s', but found '{1}'", runtime.SystemNS),
@@ -224,6 +260,17 @@ func validateAllowRejectAction(fl validator.FieldLevel) bool {
 	return util.ContainsString(allowReject, fl.Field().String())
 }
 
+// checks if a given string is a valid positive duration
+func validateDuration(fl validator.FieldLevel) bool {
+	duration, err := time.ParseDuration(fl.Field().String())
+	return err == nil && duration > 0
+}
+
+// checks if a given string is a valid quota scope
+func validateQuotaScope(fl validator.FieldLevel) bool {
+	return util.ContainsString(quotaScopes, fl.Field().String())
+}
+
 // checks if a given string is a valid cluster type
 func validateClusterType(fl validator.FieldLevel) bool {
 	return util.ContainsString(clusterTypes, fl.Field().String())
@@ -278,8 +325,7 @@ func validateLabelOperations(fl validator.FieldLevel) bool {
 func validateACLRoleActionMap(fl validator.FieldLevel) bool {
 	addRoleMap := fl.Field().Interface().(map[string]string)
 	for roleID, namespaceList := range addRoleMap {
-		role := ACLRolesMap[roleID]
-		if role == nil {
+		if !isIdentifier(roleID) {
 			return false
 		}
 
@@ -294,6 +340,22 @@ func validateACLRoleActionMap(fl validator.FieldLevel) bool {
 	return true
 }
 
+// checks if a given map of privileges has valid policy object kinds as keys
+func validatePrivilegeKinds(fl validator.FieldLevel) bool {
+	privileges := fl.Field().Interface().(map[string]*Privilege)
+	for kind := range privileges {
+		if !policyObjectsMap[kind] {
+			return false
+		}
+	}
+	return true
+}
+
+// checks if a given string is a known operation
+func validateOperation(fl validator.FieldLevel) bool {
+	return util.ContainsString(Operations, fl.Field().String())
+}
+
 // checks if a given map[string]string is a valid map of labels
 func validateLabels(fl validator.FieldLevel) bool {
 	names := fl.Field().MapKeys()
@@ -394,8 +456,9 @@ func validateContract(ctx context.Context, sl validator.StructLevel) {
 }
 
 // checks if rule is valid
-func validateRule(sl validator.StructLevel) {
+func validateRule(ctx context.Context, sl validator.StructLevel) {
 	rule := sl.Current().Addr().Interface().(*Rule)
+	policy := ctx.Value(policyKey).(*Policy)
 
 	// regular rule should have at least one of the actions set
 	if rule.GetKind() == RuleObject.Kind {
@@ -403,6 +466,7 @@ func validateRule(sl validator.StructLevel) {
 		hasActions = hasActions || (rule.Actions != nil && len(rule.Actions.ChangeLabels) > 0)
 		hasActions = hasActions || (rule.Actions != nil && len(rule.Actions.Dependency) > 0)
 		hasActions = hasActions || (rule.Actions != nil && len(rule.Actions.Ingress) > 0)
+		hasActions = hasActions || (rule.Actions != nil && len(rule.Actions.MaxTTL) > 0)
 		if !hasActions {
 			sl.ReportError(rule.Actions, "Actions", "", "ruleActions", "")
 		}
@@ -417,6 +481,18 @@ func validateRule(sl validator.StructLevel) {
 			sl.ReportError(rule.Actions, "Actions.AddRole", "", "aclRuleActions", "")
 			return
 		}
+
+		// every role should be either a built-in role, or should point to an existing role
+		for roleID := range rule.Actions.AddRole {
+			if _, builtin := ACLRolesMap[roleID]; builtin {
+				continue
+			}
+			obj, err := policy.GetObject(ACLRoleObject.Kind, roleID, runtime.SystemNS)
+			if obj == nil || err != nil {
+				sl.ReportError(rule.Actions, fmt.Sprintf("Actions.AddRole[%s]", roleID), "", "exists", "")
+				return
+			}
+		}
 		return
 	}
 }
@@ -429,6 +505,34 @@ func validateCluster(sl validator.StructLevel) {
 	}
 }
 
+// checks if ACL role is valid
+func validateACLRole(sl validator.StructLevel) {
+	role := sl.Current().Addr().Interface().(*ACLRole)
+	if role.Namespace != runtime.SystemNS {
+		sl.ReportError(role.Namespace, "Namespace", "", "systemNS", "")
+	}
+
+	// custom role should not redefine any of built-in roles
+	if _, builtin := ACLRolesMap[role.Name]; builtin {
+		sl.ReportError(role.Name, "Name", "", "builtinRole", "")
+	}
+}
+
+// checks if quota is valid
+func validateQuota(sl validator.StructLevel) {
+	quota := sl.Current().Addr().Interface().(*Quota)
+
+	// quota should have at least one limit set
+	if quota.MaxDependencies <= 0 && quota.MaxInstances <= 0 {
+		sl.ReportError(quota, "MaxDependencies|MaxInstances", "", "quotaLimits", "")
+	}
+
+	// quota with label scope should have label name set
+	if quota.Scope == QuotaScopeLabel && len(quota.Label) <= 0 {
+		sl.ReportError(quota.Label, "Label", "", "quotaLabel", "")
+	}
+}
+
 func isIdentifier(id string) bool {
 	ok, err := regexp.MatchString(identifierRegex, id)
 	return ok && err == nil
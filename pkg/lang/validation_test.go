@@ -142,6 +142,34 @@ func TestPolicyValidationACLRule(t *testing.T) {
 	})
 }
 
+func TestPolicyValidationACLRole(t *testing.T) {
+	// Roles (Names & Privileges)
+	runValidationTests(t, ResSuccess, true, []Base{
+		makeACLRole("operator", runtime.SystemNS, ClusterObject.Kind),
+	})
+	runValidationTests(t, ResFailure, true, []Base{
+		makeACLRole("operator", "main", ClusterObject.Kind),                 // not in system namespace
+		makeACLRole(domainAdmin.Name, runtime.SystemNS, ClusterObject.Kind), // overrides built-in role
+		makeACLRole("operator", runtime.SystemNS, "unknown"),                // unknown object kind
+	})
+
+	// Roles (Operations)
+	role := makeACLRole("operator", runtime.SystemNS, ClusterObject.Kind)
+	role.Privileges.Operations = []string{OperationResetActualState, OperationViewRevisions}
+	runValidationTests(t, ResSuccess, true, []Base{role})
+	role = makeACLRole("operator", runtime.SystemNS, ClusterObject.Kind)
+	role.Privileges.Operations = []string{"unknown"}
+	runValidationTests(t, ResFailure, true, []Base{role})
+
+	// ACL rules can refer to custom roles defined in the policy
+	rule := makeACLRule(0)
+	rule.Actions.AddRole["operator"] = namespaceAll
+	runValidationTests(t, ResSuccess, false, []Base{
+		makeACLRole("operator", runtime.SystemNS, ClusterObject.Kind),
+		rule,
+	})
+}
+
 func TestPolicyValidationCluster(t *testing.T) {
 	// Clusters (Identifiers & Config)
 	runValidationTests(t, ResSuccess, true, []Base{
@@ -153,6 +181,23 @@ func TestPolicyValidationCluster(t *testing.T) {
 	})
 }
 
+func TestPolicyValidationQuota(t *testing.T) {
+	// Quotas (Scope & Limits)
+	runValidationTests(t, ResSuccess, true, []Base{
+		makeQuota(QuotaScopeUser, "", 5, 0),
+		makeQuota(QuotaScopeLabel, "team", 0, 3),
+		makeQuota(QuotaScopeNamespace, "", 10, 10),
+		makeQuota(QuotaScopeCluster, "", 0, 1),
+	})
+	runValidationTests(t, ResFailure, true, []Base{
+		makeQuota("unknown", "", 5, 0),         // invalid scope
+		makeQuota(QuotaScopeLabel, "", 5, 0),   // label scope without label
+		makeQuota(QuotaScopeUser, "", 0, 0),    // no limits specified
+		makeQuota(QuotaScopeUser, "", -1, 5),   // negative limit
+		makeQuota(QuotaScopeLabel, "#$", 5, 0), // invalid label name
+	})
+}
+
 func runValidationTests(t *testing.T, result int, every bool, objects []Base) {
 	t.Helper()
 
@@ -245,7 +290,7 @@ func makeACLRule(actionNum int) *Rule {
 	}
 	switch actionNum {
 	case 0:
-		rule.Actions = &RuleActions{AddRole: map[string]string{domainAdmin.ID: namespaceAll, serviceConsumer.ID: "main1, main2 ,main3,main4"}}
+		rule.Actions = &RuleActions{AddRole: map[string]string{domainAdmin.Name: namespaceAll, serviceConsumer.Name: "main1, main2 ,main3,main4"}}
 	case Empty:
 		rule.Actions = &RuleActions{}
 	case Nil:
@@ -258,6 +303,21 @@ func makeACLRule(actionNum int) *Rule {
 	return rule
 }
 
+func makeACLRole(name string, ns string, kind string) *ACLRole {
+	return &ACLRole{
+		TypeKind: ACLRoleObject.GetTypeKind(),
+		Metadata: Metadata{
+			Namespace: ns,
+			Name:      name,
+		},
+		Privileges: &Privileges{
+			GlobalObjects: map[string]*Privilege{
+				kind: fullAccess,
+			},
+		},
+	}
+}
+
 func makeContract(name string, labelOpsNum int, pointToService string) *Contract {
 	contract := &Contract{
 		TypeKind: ContractObject.GetTypeKind(),
@@ -313,6 +373,20 @@ func makeCluster(clusterType, ns string) *Cluster {
 	}
 }
 
+func makeQuota(scope string, label string, maxDependencies int, maxInstances int) *Quota {
+	return &Quota{
+		TypeKind: QuotaObject.GetTypeKind(),
+		Metadata: Metadata{
+			Namespace: runtime.SystemNS,
+			Name:      "quota",
+		},
+		Scope:           scope,
+		Label:           label,
+		MaxDependencies: maxDependencies,
+		MaxInstances:    maxInstances,
+	}
+}
+
 func makeService(name string, labelNum int) *Service {
 	service := &Service{
 		TypeKind: ServiceObject.GetTypeKind(),
@@ -0,0 +1,180 @@
+package encrypted
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"encoding/base64"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"strings"
+)
+
+// prefix marks encrypted data, which allows to transparently decode both encrypted and plain data (e.g. stored before
+// encryption was enabled)
+const prefix = "aptomi-encrypted:v1:"
+
+// envelopeSeparator separates key id, encrypted data key and encrypted value in the envelope
+const envelopeSeparator = ":"
+
+type encryptedCodec struct {
+	codec runtime.Codec
+	ring  *KeyRing
+}
+
+// NewCodec returns runtime codec, which uses provided codec for encoding objects and then encrypts them using envelope
+// encryption. Every value is encrypted with AES-256-GCM using new random data key, which is encrypted with the current
+// key from the key ring and stored together with the value:
+//
+//	aptomi-encrypted:v1:<key id>:<base64 encrypted data key>:<base64 encrypted value>
+//
+// Decoding detects encrypted values and decrypts them using the key they were encrypted with, while plain values are
+// decoded as is. If key ring is nil, values are stored unencrypted and attempt to decode encrypted value fails with
+// a clear error
+func NewCodec(codec runtime.Codec, ring *KeyRing) runtime.Codec {
+	return &encryptedCodec{codec: codec, ring: ring}
+}
+
+// encryptedCodec implements runtime.Codec
+var _ runtime.Codec = &encryptedCodec{}
+
+func (cod *encryptedCodec) EncodeOne(obj runtime.Object) ([]byte, error) {
+	data, err := cod.codec.EncodeOne(obj)
+	if err != nil {
+		return nil, err
+	}
+	return cod.encrypt(data)
+}
+
+func (cod *encryptedCodec) EncodeMany(objs []runtime.Object) ([]byte, error) {
+	data, err := cod.codec.EncodeMany(objs)
+	if err != nil {
+		return nil, err
+	}
+	return cod.encrypt(data)
+}
+
+func (cod *encryptedCodec) DecodeOne(data []byte) (runtime.Object, error) {
+	data, err := cod.decrypt(data)
+	if err != nil {
+		return nil, err
+	}
+	return cod.codec.DecodeOne(data)
+}
+
+func (cod *encryptedCodec) DecodeOneOrMany(data []byte) ([]runtime.Object, error) {
+	data, err := cod.decrypt(data)
+	if err != nil {
+		return nil, err
+	}
+	return cod.codec.DecodeOneOrMany(data)
+}
+
+// IsEncrypted returns true if data was encrypted by the encrypted codec
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(prefix))
+}
+
+// KeyID returns ID of the key data was encrypted with or empty string if data isn't encrypted
+func KeyID(data []byte) string {
+	if !IsEncrypted(data) {
+		return ""
+	}
+	parts := strings.SplitN(string(data[len(prefix):]), envelopeSeparator, 2)
+	return parts[0]
+}
+
+func (cod *encryptedCodec) encrypt(data []byte) ([]byte, error) {
+	if cod.ring == nil {
+		return data, nil
+	}
+
+	dataKey, err := randomBytes(keySize)
+	if err != nil {
+		return nil, err
+	}
+	encryptedDataKey, err := seal(cod.ring.keys[cod.ring.current], dataKey)
+	if err != nil {
+		return nil, err
+	}
+	encryptedData, err := seal(dataKey, data)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(prefix + strings.Join([]string{
+		cod.ring.current,
+		base64.StdEncoding.EncodeToString(encryptedDataKey),
+		base64.StdEncoding.EncodeToString(encryptedData),
+	}, envelopeSeparator)), nil
+}
+
+func (cod *encryptedCodec) decrypt(data []byte) ([]byte, error) {
+	if !IsEncrypted(data) {
+		return data, nil
+	}
+
+	parts := strings.Split(string(data[len(prefix):]), envelopeSeparator)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("encrypted data is malformed")
+	}
+	if cod.ring == nil {
+		return nil, fmt.Errorf("data is encrypted with key '%s', but encryption key file isn't configured", parts[0])
+	}
+	key, ok := cod.ring.keys[parts[0]]
+	if !ok {
+		return nil, fmt.Errorf("data is encrypted with key '%s', which isn't found in the key file", parts[0])
+	}
+	encryptedDataKey, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("encrypted data key is malformed: %s", err)
+	}
+	encryptedData, err := base64.StdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("encrypted data is malformed: %s", err)
+	}
+
+	dataKey, err := open(key, encryptedDataKey)
+	if err != nil {
+		return nil, fmt.Errorf("error while decrypting data key with key '%s': %s", parts[0], err)
+	}
+	result, err := open(dataKey, encryptedData)
+	if err != nil {
+		return nil, fmt.Errorf("error while decrypting data: %s", err)
+	}
+
+	return result, nil
+}
+
+// seal encrypts data using AES-GCM with random nonce, which is prepended to the result
+func seal(key []byte, data []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := randomBytes(aead.NonceSize())
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, data, nil), nil
+}
+
+// open decrypts data encrypted by seal
+func open(key []byte, data []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < aead.NonceSize() {
+		return nil, fmt.Errorf("encrypted data is too short")
+	}
+	return aead.Open(nil, data[:aead.NonceSize()], data[aead.NonceSize():], nil)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
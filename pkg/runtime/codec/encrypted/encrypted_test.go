@@ -0,0 +1,173 @@
+package encrypted
+
+import (
+	"encoding/base64"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/codec/yaml"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func makeService() *lang.Service {
+	return &lang.Service{
+		TypeKind:   lang.ServiceObject.GetTypeKind(),
+		Metadata:   lang.Metadata{Namespace: "main", Name: "svc"},
+		Labels:     map[string]string{"password": "secret-value"},
+		Components: []*lang.ServiceComponent{},
+	}
+}
+
+func newKeyFile(t *testing.T) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "aptomi-encryption-test")
+	if err != nil {
+		t.Fatalf("can't create temp dir: %s", err)
+	}
+	return filepath.Join(dir, "keys.yaml"), func() {
+		os.RemoveAll(dir) // nolint: errcheck
+	}
+}
+
+func newCodec(t *testing.T, keyFile string) runtime.Codec {
+	t.Helper()
+	ring, err := LoadKeyRing(keyFile)
+	if err != nil {
+		t.Fatalf("can't load key ring: %s", err)
+	}
+	return NewCodec(yaml.NewCodec(runtime.NewRegistry().Append(lang.ServiceObject)), ring)
+}
+
+func TestEncryptedCodec(t *testing.T) {
+	keyFile, cleanup := newKeyFile(t)
+	defer cleanup()
+	if !assert.NoError(t, AddKey(keyFile, "k1"), "Key should be generated") {
+		return
+	}
+	codec := newCodec(t, keyFile)
+
+	data, err := codec.EncodeOne(makeService())
+	if !assert.NoError(t, err, "Object should be encoded") {
+		return
+	}
+	assert.True(t, IsEncrypted(data), "Encoded object should be encrypted")
+	assert.Equal(t, "k1", KeyID(data), "Object should be encrypted with the current key")
+	assert.False(t, strings.Contains(string(data), "secret-value"), "Encrypted object shouldn't contain plain values")
+
+	other, err := codec.EncodeOne(makeService())
+	if assert.NoError(t, err, "Object should be encoded") {
+		assert.NotEqual(t, data, other, "Every encryption should use new data key and nonce")
+	}
+
+	obj, err := codec.DecodeOne(data)
+	if assert.NoError(t, err, "Object should be decoded") {
+		assert.Equal(t, makeService(), obj, "Decoded object should match the original one")
+	}
+
+	// plain data stored before encryption was enabled should be decoded as is
+	plain, err := yaml.NewCodec(runtime.NewRegistry().Append(lang.ServiceObject)).EncodeOne(makeService())
+	if assert.NoError(t, err, "Object should be encoded") {
+		obj, err = codec.DecodeOne(plain)
+		assert.NoError(t, err, "Plain object should be decoded")
+		assert.Equal(t, makeService(), obj, "Decoded plain object should match the original one")
+	}
+
+	// tampered data should be rejected
+	tampered := []byte(string(data[:len(data)-4]) + "AAA=")
+	_, err = codec.DecodeOne(tampered)
+	assert.Error(t, err, "Tampered object shouldn't be decoded")
+}
+
+func TestKeyRotation(t *testing.T) {
+	keyFile, cleanup := newKeyFile(t)
+	defer cleanup()
+	if !assert.NoError(t, AddKey(keyFile, "k1"), "Key should be generated") {
+		return
+	}
+	data, err := newCodec(t, keyFile).EncodeOne(makeService())
+	if !assert.NoError(t, err, "Object should be encoded") {
+		return
+	}
+
+	assert.Error(t, AddKey(keyFile, "k1"), "Existing key shouldn't be overwritten")
+	if !assert.NoError(t, AddKey(keyFile, "k2"), "New key should be generated") {
+		return
+	}
+	codec := newCodec(t, keyFile)
+
+	// object encrypted with the old key should be still decoded, while new objects are encrypted with the new key
+	obj, err := codec.DecodeOne(data)
+	if assert.NoError(t, err, "Object encrypted with old key should be decoded") {
+		assert.Equal(t, makeService(), obj, "Decoded object should match the original one")
+	}
+	reencrypted, err := codec.EncodeOne(obj)
+	if assert.NoError(t, err, "Object should be encoded") {
+		assert.Equal(t, "k2", KeyID(reencrypted), "Object should be encrypted with the new current key")
+	}
+
+	// once old key is removed, objects encrypted with it couldn't be decoded anymore
+	keys, err := ReadKeyFile(keyFile)
+	if !assert.NoError(t, err, "Key file should be read") {
+		return
+	}
+	delete(keys.Keys, "k1")
+	if !assert.NoError(t, WriteKeyFile(keyFile, keys), "Key file should be written") {
+		return
+	}
+	codec = newCodec(t, keyFile)
+	_, err = codec.DecodeOne(data)
+	assert.Error(t, err, "Object encrypted with removed key shouldn't be decoded")
+	_, err = codec.DecodeOne(reencrypted)
+	assert.NoError(t, err, "Object encrypted with the current key should be decoded")
+}
+
+func TestInvalidKeyFile(t *testing.T) {
+	_, err := NewKeyRing(&KeyFile{Current: "k1", Keys: map[string]string{"k1": "c2hvcnQ="}})
+	assert.Error(t, err, "Key of the wrong size shouldn't be accepted")
+
+	_, err = NewKeyRing(&KeyFile{Current: "k2", Keys: map[string]string{}})
+	assert.Error(t, err, "Key file without current key shouldn't be accepted")
+
+	validKey := base64.StdEncoding.EncodeToString(make([]byte, keySize))
+	for _, id := range []string{"", "k:1"} {
+		_, err = NewKeyRing(&KeyFile{Current: id, Keys: map[string]string{id: validKey}})
+		assert.Error(t, err, "Key with ID '%s' shouldn't be accepted", id)
+	}
+}
+
+func TestAddKeyInvalidID(t *testing.T) {
+	keyFile, cleanup := newKeyFile(t)
+	defer cleanup()
+
+	for _, id := range []string{"", "k:1"} {
+		assert.Error(t, AddKey(keyFile, id), "Key with ID '%s' shouldn't be added", id)
+	}
+	_, err := os.Stat(keyFile)
+	assert.True(t, os.IsNotExist(err), "Key file shouldn't be created for invalid key ID")
+}
+
+func TestCodecWithoutKeys(t *testing.T) {
+	keyFile, cleanup := newKeyFile(t)
+	defer cleanup()
+	if !assert.NoError(t, AddKey(keyFile, "k1"), "Key should be generated") {
+		return
+	}
+	data, err := newCodec(t, keyFile).EncodeOne(makeService())
+	if !assert.NoError(t, err, "Object should be encoded") {
+		return
+	}
+
+	codec := NewCodec(yaml.NewCodec(runtime.NewRegistry().Append(lang.ServiceObject)), nil)
+	plain, err := codec.EncodeOne(makeService())
+	if assert.NoError(t, err, "Object should be encoded") {
+		assert.False(t, IsEncrypted(plain), "Object shouldn't be encrypted without keys")
+	}
+	_, err = codec.DecodeOne(data)
+	if assert.Error(t, err, "Encrypted object shouldn't be decoded without keys") {
+		assert.Contains(t, err.Error(), "encryption key file isn't configured", "Error should explain that keys aren't configured")
+	}
+}
@@ -0,0 +1,165 @@
+package encrypted
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"gopkg.in/yaml.v2"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+)
+
+// keySize is a size of the AES-256 key in bytes, used for both master and data keys
+const keySize = 32
+
+// KeyFile represents file with encryption keys. All keys are base64-encoded 256-bit AES keys. Current key is used to
+// encrypt new objects, while all keys could be used to decrypt objects. Keys are rotated by adding a new key, making it
+// current and re-encrypting all objects, after which old keys could be removed
+type KeyFile struct {
+	// Current is an ID of the key used for encrypting objects
+	Current string
+
+	// Keys is a map from key ID to the base64-encoded key
+	Keys map[string]string
+}
+
+// KeyRing represents decoded encryption keys from the key file
+type KeyRing struct {
+	current string
+	keys    map[string][]byte
+}
+
+// LoadKeyRing reads key file and returns key ring with all keys from it
+func LoadKeyRing(path string) (*KeyRing, error) {
+	keyFile, err := ReadKeyFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewKeyRing(keyFile)
+}
+
+// NewKeyRing validates and decodes keys from the key file
+func NewKeyRing(keyFile *KeyFile) (*KeyRing, error) {
+	ring := &KeyRing{current: keyFile.Current, keys: make(map[string][]byte)}
+	for id, encoded := range keyFile.Keys {
+		err := validateKeyID(id)
+		if err != nil {
+			return nil, err
+		}
+		key, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %s isn't base64-encoded: %s", id, err)
+		}
+		if len(key) != keySize {
+			return nil, fmt.Errorf("key %s should be %d bytes long, but it's %d bytes long", id, keySize, len(key))
+		}
+		ring.keys[id] = key
+	}
+	if _, ok := ring.keys[ring.current]; !ok {
+		return nil, fmt.Errorf("current key '%s' not found in the key file", ring.current)
+	}
+
+	return ring, nil
+}
+
+// CurrentKeyID returns ID of the key used for encrypting objects
+func (ring *KeyRing) CurrentKeyID() string {
+	return ring.current
+}
+
+// KeyIDs returns sorted IDs of all keys in the key ring
+func (ring *KeyRing) KeyIDs() []string {
+	result := make([]string, 0, len(ring.keys))
+	for id := range ring.keys {
+		result = append(result, id)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// ReadKeyFile reads key file from the specified path
+func ReadKeyFile(path string) (*KeyFile, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error while reading key file '%s': %s", path, err)
+	}
+
+	keyFile := &KeyFile{}
+	err = yaml.Unmarshal(data, keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("error while decoding key file '%s': %s", path, err)
+	}
+
+	return keyFile, nil
+}
+
+// WriteKeyFile writes key file to the specified path, making it readable only by the owner
+func WriteKeyFile(path string, keyFile *KeyFile) error {
+	data, err := yaml.Marshal(keyFile)
+	if err != nil {
+		return fmt.Errorf("error while encoding key file: %s", err)
+	}
+
+	err = ioutil.WriteFile(path, data, 0600)
+	if err != nil {
+		return fmt.Errorf("error while writing key file '%s': %s", path, err)
+	}
+
+	return os.Chmod(path, 0600)
+}
+
+// AddKey generates new key with the specified ID and makes it current. Key file is created if it doesn't exist yet,
+// while all existing keys are kept to decrypt objects until they are re-encrypted with the new key
+func AddKey(path string, id string) error {
+	err := validateKeyID(id)
+	if err != nil {
+		return err
+	}
+
+	keyFile := &KeyFile{Keys: make(map[string]string)}
+	if _, err := os.Stat(path); err == nil {
+		keyFile, err = ReadKeyFile(path)
+		if err != nil {
+			return err
+		}
+		if keyFile.Keys == nil {
+			keyFile.Keys = make(map[string]string)
+		}
+	}
+	if _, exists := keyFile.Keys[id]; exists {
+		return fmt.Errorf("key '%s' already exists in the key file '%s'", id, path)
+	}
+
+	key, err := randomBytes(keySize)
+	if err != nil {
+		return err
+	}
+	keyFile.Keys[id] = base64.StdEncoding.EncodeToString(key)
+	keyFile.Current = id
+
+	return WriteKeyFile(path, keyFile)
+}
+
+// validateKeyID checks that key ID could be stored in the envelope of encrypted object, where it's separated from
+// the encrypted data with ':'
+func validateKeyID(id string) error {
+	if len(id) == 0 {
+		return fmt.Errorf("key ID shouldn't be empty")
+	}
+	if strings.Contains(id, envelopeSeparator) {
+		return fmt.Errorf("key ID '%s' shouldn't contain '%s'", id, envelopeSeparator)
+	}
+	return nil
+}
+
+func randomBytes(size int) ([]byte, error) {
+	result := make([]byte, size)
+	_, err := rand.Read(result)
+	if err != nil {
+		return nil, fmt.Errorf("error while generating random bytes: %s", err)
+	}
+	return result, nil
+}
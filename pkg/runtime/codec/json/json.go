@@ -0,0 +1,92 @@
+package json
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/codec/yaml"
+	utilyaml "github.com/ghodss/yaml"
+)
+
+type jsonCodec struct {
+	yaml runtime.Codec
+}
+
+// NewCodec returns instance of the JSON runtime codec for provided object registry. Objects are described using YAML
+// struct tags only, so JSON codec converts data to and from YAML and uses YAML codec for actual encoding and decoding,
+// which ensures that field names are the same in both formats and objects are decoded based on their kind
+func NewCodec(registry *runtime.Registry) runtime.Codec {
+	return &jsonCodec{
+		yaml: yaml.NewCodec(registry),
+	}
+}
+
+// NewVersionedCodec returns instance of the JSON runtime codec, which converts objects to the specified API version
+// before encoding them same as YAML codec created by yaml.NewVersionedCodec
+func NewVersionedCodec(registry *runtime.Registry, apiVersion string) runtime.Codec {
+	return &jsonCodec{
+		yaml: yaml.NewVersionedCodec(registry, apiVersion),
+	}
+}
+
+// jsonCodec implements runtime.Codec
+var _ runtime.Codec = &jsonCodec{}
+
+func (cod *jsonCodec) EncodeOne(obj runtime.Object) ([]byte, error) {
+	data, err := cod.yaml.EncodeOne(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return toJSON(data)
+}
+
+func (cod *jsonCodec) EncodeMany(objs []runtime.Object) ([]byte, error) {
+	data, err := cod.yaml.EncodeMany(objs)
+	if err != nil {
+		return nil, err
+	}
+
+	return toJSON(data)
+}
+
+func (cod *jsonCodec) DecodeOne(data []byte) (runtime.Object, error) {
+	data, err := fromJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return cod.yaml.DecodeOne(data)
+}
+
+func (cod *jsonCodec) DecodeOneOrMany(data []byte) ([]runtime.Object, error) {
+	data, err := fromJSON(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return cod.yaml.DecodeOneOrMany(data)
+}
+
+func toJSON(data []byte) ([]byte, error) {
+	data, err := utilyaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, fmt.Errorf("error while converting YAML to JSON: %s", err)
+	}
+
+	return data, nil
+}
+
+func fromJSON(data []byte) ([]byte, error) {
+	// YAML is a superset of JSON, so data should be checked explicitly to not accept YAML as JSON
+	if !json.Valid(data) {
+		return nil, fmt.Errorf("data isn't a valid JSON")
+	}
+
+	data, err := utilyaml.JSONToYAML(data)
+	if err != nil {
+		return nil, fmt.Errorf("error while converting JSON to YAML: %s", err)
+	}
+
+	return data, nil
+}
@@ -0,0 +1,61 @@
+package json
+
+import (
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+func newCodec() runtime.Codec {
+	return NewCodec(runtime.NewRegistry().Append(lang.ServiceObject, lang.ClusterObject))
+}
+
+func TestJSONCodec(t *testing.T) {
+	codec := newCodec()
+	service := &lang.Service{
+		TypeKind:   lang.ServiceObject.GetTypeKind(),
+		Metadata:   lang.Metadata{Namespace: "main", Name: "svc"},
+		Labels:     map[string]string{"team": "platform"},
+		Components: []*lang.ServiceComponent{},
+	}
+
+	data, err := codec.EncodeOne(service)
+	if !assert.NoError(t, err, "Object should be encoded") {
+		return
+	}
+	assert.True(t, strings.HasPrefix(string(data), "{"), "Object should be encoded as JSON object")
+	assert.Contains(t, string(data), `"kind":"service"`, "Field names should be taken from YAML tags")
+
+	obj, err := codec.DecodeOne(data)
+	if assert.NoError(t, err, "Object should be decoded") {
+		assert.Equal(t, service, obj, "Decoded object should be equal to the encoded one")
+	}
+
+	_, err = codec.DecodeOne([]byte("kind: service\nmetadata:\n  name: svc\n"))
+	assert.Error(t, err, "YAML shouldn't be accepted by JSON codec")
+}
+
+func TestJSONCodecPolymorphic(t *testing.T) {
+	codec := newCodec()
+	data := `[
+		{"kind": "service", "metadata": {"namespace": "main", "name": "svc"}},
+		{"kind": "cluster", "metadata": {"namespace": "system", "name": "cluster"}, "type": "kubernetes"}
+	]`
+
+	objs, err := codec.DecodeOneOrMany([]byte(data))
+	if !assert.NoError(t, err, "Objects should be decoded") || !assert.Len(t, objs, 2, "All objects should be decoded") {
+		return
+	}
+	assert.IsType(t, &lang.Service{}, objs[0], "Object type should be chosen by its kind")
+	assert.IsType(t, &lang.Cluster{}, objs[1], "Object type should be chosen by its kind")
+
+	encoded, err := codec.EncodeMany(objs)
+	if assert.NoError(t, err, "Objects should be encoded") {
+		assert.True(t, strings.HasPrefix(string(encoded), "["), "Objects should be encoded as JSON array")
+	}
+
+	_, err = codec.DecodeOne([]byte(data))
+	assert.Error(t, err, "Single object should be required")
+}
@@ -3,28 +3,28 @@ package yaml
 import (
 	"fmt"
 	"github.com/Aptomi/aptomi/pkg/runtime"
-	utilyaml "github.com/ghodss/yaml"
 	"gopkg.in/yaml.v2"
 )
 
 type yamlCodec struct {
-	registry *runtime.Registry
-	json     bool
+	registry   *runtime.Registry
+	apiVersion string
 }
 
-// NewCodec returns instance of the YAML runtime codec for provided object registry
+// NewCodec returns instance of the YAML runtime codec for provided object registry. Decoded objects are always
+// converted to the hub version of their kind, while objects are encoded as is
 func NewCodec(registry *runtime.Registry) runtime.Codec {
 	return &yamlCodec{
 		registry: registry,
-		json:     false,
 	}
 }
 
-// NewJSONCodec returns instance of the JSON runtime codec for provided object registry
-func NewJSONCodec(registry *runtime.Registry) runtime.Codec {
+// NewVersionedCodec returns instance of the YAML runtime codec, which converts objects to the specified API version
+// before encoding them (if their kinds have such version). Decoding is the same as for the codec created by NewCodec
+func NewVersionedCodec(registry *runtime.Registry, apiVersion string) runtime.Codec {
 	return &yamlCodec{
-		registry: registry,
-		json:     true,
+		registry:   registry,
+		apiVersion: apiVersion,
 	}
 }
 
@@ -32,13 +32,38 @@ func NewJSONCodec(registry *runtime.Registry) runtime.Codec {
 var _ runtime.Codec = &yamlCodec{}
 
 func (cod *yamlCodec) EncodeOne(obj runtime.Object) ([]byte, error) {
+	obj, err := cod.fromHub(obj)
+	if err != nil {
+		return nil, err
+	}
+
 	return cod.encode(obj)
 }
 
 func (cod *yamlCodec) EncodeMany(objs []runtime.Object) ([]byte, error) {
+	if len(cod.apiVersion) > 0 {
+		converted := make([]runtime.Object, len(objs))
+		for idx, obj := range objs {
+			var err error
+			converted[idx], err = cod.fromHub(obj)
+			if err != nil {
+				return nil, err
+			}
+		}
+		objs = converted
+	}
+
 	return cod.encode(objs)
 }
 
+func (cod *yamlCodec) fromHub(obj runtime.Object) (runtime.Object, error) {
+	if len(cod.apiVersion) == 0 || obj == nil {
+		return obj, nil
+	}
+
+	return cod.registry.FromHub(obj, cod.apiVersion)
+}
+
 func (cod *yamlCodec) DecodeOne(data []byte) (runtime.Object, error) {
 	objects, err := cod.decodeOneOrMany(data, true)
 	if err != nil {
@@ -53,19 +78,7 @@ func (cod *yamlCodec) DecodeOneOrMany(data []byte) ([]runtime.Object, error) {
 }
 
 func (cod *yamlCodec) encode(obj interface{}) ([]byte, error) {
-	data, err := yaml.Marshal(obj)
-	if err != nil {
-		return nil, err
-	}
-
-	if cod.json {
-		data, err = utilyaml.YAMLToJSON(data)
-	}
-	if err != nil {
-		return nil, err
-	}
-
-	return data, nil
+	return yaml.Marshal(obj)
 }
 
 func (cod *yamlCodec) decodeOneOrMany(data []byte, strictOne bool) ([]runtime.Object, error) {
@@ -127,16 +140,23 @@ func (cod *yamlCodec) decodeRaw(single map[interface{}]interface{}, data []byte)
 		return nil, fmt.Errorf("empty kind")
 	}
 
-	info := cod.registry.Get(kind)
-	if info == nil {
-		return nil, fmt.Errorf("unknown kind: %s", kind)
+	apiVersion := ""
+	if apiVersionField, exist := single["apiversion"]; exist {
+		apiVersion, ok = apiVersionField.(string)
+		if !ok {
+			return nil, fmt.Errorf("apiversion field in metadata isn't a string: %v", single)
+		}
+	}
+
+	obj, err := cod.registry.NewVersion(kind, apiVersion)
+	if err != nil {
+		return nil, err
 	}
 
-	obj := info.New()
-	err := yaml.Unmarshal(data, obj)
+	err = yaml.Unmarshal(data, obj)
 	if err != nil {
 		return nil, err
 	}
 
-	return obj, nil
+	return cod.registry.ToHub(obj)
 }
@@ -0,0 +1,102 @@
+package yaml
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"strings"
+	"testing"
+)
+
+// personV1 is an old version of the test kind with full name in a single field
+type personV1 struct {
+	runtime.TypeKind `yaml:",inline"`
+	Name             string
+}
+
+// personV2 is a hub version of the test kind
+type personV2 struct {
+	runtime.TypeKind `yaml:",inline"`
+	FirstName        string
+	LastName         string
+}
+
+var personObject = &runtime.Info{
+	Kind:        "person",
+	APIVersion:  "v2",
+	Constructor: func() runtime.Object { return &personV2{} },
+	Conversions: []*runtime.Conversion{{
+		APIVersion:  "v1",
+		Constructor: func() runtime.Object { return &personV1{} },
+		ToHub: func(obj runtime.Object) (runtime.Object, error) {
+			parts := strings.SplitN(obj.(*personV1).Name, " ", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("name should contain first and last names")
+			}
+			return &personV2{TypeKind: obj.(*personV1).TypeKind, FirstName: parts[0], LastName: parts[1]}, nil
+		},
+		FromHub: func(obj runtime.Object) (runtime.Object, error) {
+			person := obj.(*personV2)
+			return &personV1{TypeKind: person.TypeKind, Name: person.FirstName + " " + person.LastName}, nil
+		},
+	}},
+}
+
+func TestCodecConvertsToHubOnDecode(t *testing.T) {
+	codec := NewCodec(runtime.NewRegistry().Append(personObject))
+
+	objs, err := codec.DecodeOneOrMany([]byte(`
+- kind: person
+  apiversion: v1
+  name: John Smith
+- kind: person
+  apiversion: v2
+  firstname: Jane
+  lastname: Doe
+`))
+	if !assert.NoError(t, err, "Objects of all versions should be decoded") || !assert.Len(t, objs, 2, "All objects should be decoded") {
+		return
+	}
+	assert.Equal(t, &personV2{TypeKind: personObject.GetTypeKind(), FirstName: "John", LastName: "Smith"}, objs[0], "Old version should be converted to hub")
+	assert.Equal(t, &personV2{TypeKind: personObject.GetTypeKind(), FirstName: "Jane", LastName: "Doe"}, objs[1], "Hub version should be decoded as is")
+
+	_, err = codec.DecodeOne([]byte("kind: person\napiversion: v1\nname: John\n"))
+	assert.Error(t, err, "Conversion error should be returned")
+
+	_, err = codec.DecodeOne([]byte("kind: person\napiversion: v3\n"))
+	assert.Error(t, err, "Unsupported API version shouldn't be decoded")
+
+	// objects without API version are treated as having the default one
+	_, err = codec.DecodeOne([]byte("kind: person\nname: John Smith\n"))
+	assert.NoError(t, err, "Object without API version should be decoded as v1")
+}
+
+func TestVersionedCodecConvertsOnEncode(t *testing.T) {
+	registry := runtime.NewRegistry().Append(personObject)
+	person := &personV2{TypeKind: personObject.GetTypeKind(), FirstName: "John", LastName: "Smith"}
+
+	data, err := NewVersionedCodec(registry, "v1").EncodeOne(person)
+	if assert.NoError(t, err, "Object should be encoded") {
+		assert.Equal(t, "kind: person\napiversion: v1\nname: John Smith\n", string(data), "Object should be converted to the requested version")
+	}
+
+	data, err = NewVersionedCodec(registry, "v3").EncodeMany([]runtime.Object{person})
+	if assert.NoError(t, err, "Objects should be encoded") {
+		assert.Contains(t, string(data), "apiversion: v2", "Hub version should be used if requested version isn't supported")
+	}
+
+	data, err = NewCodec(registry).EncodeOne(person)
+	if assert.NoError(t, err, "Object should be encoded") {
+		assert.Contains(t, string(data), "firstname: John", "Object should be encoded as is by default")
+	}
+}
+
+func TestRegistryValidatesConversions(t *testing.T) {
+	duplicated := *personObject
+	duplicated.Conversions = []*runtime.Conversion{personObject.Conversions[0], personObject.Conversions[0]}
+	assert.Panics(t, func() { runtime.NewRegistry().Append(&duplicated) }, "Duplicated API versions should panic")
+
+	incomplete := *personObject
+	incomplete.Conversions = []*runtime.Conversion{{APIVersion: "v1"}}
+	assert.Panics(t, func() { runtime.NewRegistry().Append(&incomplete) }, "Conversion without functions should panic")
+}
@@ -7,6 +7,22 @@ type Info struct {
 	Versioned   bool
 	Deletable   bool
 	Constructor Constructor
+
+	// APIVersion is a hub version of the kind, i.e. version of objects created by Constructor, which are used in the
+	// code and stored in database. DefaultAPIVersion is used if it's empty
+	APIVersion string
+
+	// Conversions describe other API versions of the kind, objects are always converted through the hub version
+	Conversions []*Conversion
+}
+
+// Conversion describes API version of the kind other than its hub version and how its objects are converted to and
+// from the hub version
+type Conversion struct {
+	APIVersion  string
+	Constructor Constructor
+	ToHub       func(obj Object) (Object, error)
+	FromHub     func(obj Object) (Object, error)
 }
 
 // Constructor is a function to get instance of the specific object
@@ -19,5 +35,24 @@ func (info *Info) New() Object {
 
 // GetTypeKind returns TypeKind instance for the object described by info
 func (info *Info) GetTypeKind() TypeKind {
-	return TypeKind{info.Kind}
+	return TypeKind{Kind: info.Kind, APIVersion: info.APIVersion}
+}
+
+// GetAPIVersion returns hub version of the kind
+func (info *Info) GetAPIVersion() string {
+	if len(info.APIVersion) == 0 {
+		return DefaultAPIVersion
+	}
+	return info.APIVersion
+}
+
+// GetConversion returns conversion for the specified API version or nil if kind doesn't have such version or it's
+// the hub version
+func (info *Info) GetConversion(apiVersion string) *Conversion {
+	for _, conversion := range info.Conversions {
+		if conversion.APIVersion == apiVersion {
+			return conversion
+		}
+	}
+	return nil
 }
@@ -3,13 +3,48 @@ package runtime
 // Kind represents runtime object Kind
 type Kind = string
 
+// DefaultAPIVersion is an API version of objects, which don't have it specified. All kinds had this version before
+// API versioning was introduced, so objects stored or sent by clients before that are treated as having it
+const DefaultAPIVersion = "v1"
+
 // TypeKind represents type definition of the runtime object, should be embedded into all runtime objects with `yaml:",inline"`
 // for proper yaml codec encoding and decoding
 type TypeKind struct {
 	Kind Kind
+
+	// APIVersion is a version of the kind schema object is described in, DefaultAPIVersion is used if it's empty
+	APIVersion string `yaml:"apiversion,omitempty"`
 }
 
 // GetKind returns Kind
 func (tk *TypeKind) GetKind() Kind {
 	return tk.Kind
 }
+
+// GetAPIVersion returns API version of the object, it's DefaultAPIVersion if it isn't specified
+func (tk *TypeKind) GetAPIVersion() string {
+	if len(tk.APIVersion) == 0 {
+		return DefaultAPIVersion
+	}
+	return tk.APIVersion
+}
+
+// SetAPIVersion sets API version of the object
+func (tk *TypeKind) SetAPIVersion(apiVersion string) {
+	tk.APIVersion = apiVersion
+}
+
+// APIVersioned represents runtime object, which has API version. All objects embedding TypeKind implement it
+type APIVersioned interface {
+	Object
+	GetAPIVersion() string
+	SetAPIVersion(apiVersion string)
+}
+
+// APIVersionOf returns API version of the object, it's DefaultAPIVersion for objects without API version
+func APIVersionOf(obj Object) string {
+	if versioned, ok := obj.(APIVersioned); ok {
+		return versioned.GetAPIVersion()
+	}
+	return DefaultAPIVersion
+}
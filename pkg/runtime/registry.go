@@ -36,6 +36,80 @@ func (reg *Registry) Get(kind Kind) *Info {
 	return info
 }
 
+// NewVersion creates a new instance of the object with specified kind and API version, which should be either hub
+// version of the kind or one of the versions it has conversions for. Empty API version means DefaultAPIVersion
+func (reg *Registry) NewVersion(kind Kind, apiVersion string) (Object, error) {
+	info := reg.Get(kind)
+	if len(apiVersion) == 0 {
+		apiVersion = DefaultAPIVersion
+	}
+	if apiVersion == info.GetAPIVersion() {
+		return info.New(), nil
+	}
+
+	conversion := info.GetConversion(apiVersion)
+	if conversion == nil {
+		return nil, fmt.Errorf("unsupported API version %s of kind %s", apiVersion, kind)
+	}
+
+	return conversion.Constructor(), nil
+}
+
+// ToHub converts object to the hub version of its kind, objects of unregistered kinds are returned as is. API version
+// of the objects of the hub version is set exactly as in the kind info, so the same object is always encoded the same
+// way regardless of whether API version was specified explicitly
+func (reg *Registry) ToHub(obj Object) (Object, error) {
+	info, exist := reg.Kinds[obj.GetKind()]
+	if !exist {
+		return obj, nil
+	}
+	if APIVersionOf(obj) == info.GetAPIVersion() {
+		if versioned, ok := obj.(APIVersioned); ok {
+			versioned.SetAPIVersion(info.APIVersion)
+		}
+		return obj, nil
+	}
+
+	conversion := info.GetConversion(APIVersionOf(obj))
+	if conversion == nil {
+		return nil, fmt.Errorf("unsupported API version %s of kind %s", APIVersionOf(obj), obj.GetKind())
+	}
+
+	result, err := conversion.ToHub(obj)
+	if err != nil {
+		return nil, fmt.Errorf("error while converting %s from API version %s to %s: %s", obj.GetKind(), conversion.APIVersion, info.GetAPIVersion(), err)
+	}
+	if versioned, ok := result.(APIVersioned); ok {
+		versioned.SetAPIVersion(info.APIVersion)
+	}
+
+	return result, nil
+}
+
+// FromHub converts object of the hub version to the specified API version. Object is returned as is if it's already
+// of that version or its kind doesn't have such version, so clients get hub version of kinds that don't have it
+func (reg *Registry) FromHub(obj Object, apiVersion string) (Object, error) {
+	info, exist := reg.Kinds[obj.GetKind()]
+	if !exist || APIVersionOf(obj) == apiVersion {
+		return obj, nil
+	}
+
+	conversion := info.GetConversion(apiVersion)
+	if conversion == nil {
+		return obj, nil
+	}
+
+	result, err := conversion.FromHub(obj)
+	if err != nil {
+		return nil, fmt.Errorf("error while converting %s from API version %s to %s: %s", obj.GetKind(), info.GetAPIVersion(), apiVersion, err)
+	}
+	if versioned, ok := result.(APIVersioned); ok {
+		versioned.SetAPIVersion(apiVersion)
+	}
+
+	return result, nil
+}
+
 func (reg *Registry) validateInfo(info *Info) {
 	kind := info.Kind
 	if len(kind) == 0 {
@@ -57,4 +131,19 @@ func (reg *Registry) validateInfo(info *Info) {
 	} /* else if !info.Versioned && ok {
 		log.Debugf("Kind '%s' registered as non-Versioned but implements corresponding interface", kind)
 	} */
+
+	versions := map[string]bool{info.GetAPIVersion(): true}
+	for _, conversion := range info.Conversions {
+		if len(conversion.APIVersion) == 0 {
+			panic(fmt.Sprintf("Kind '%s' has conversion with empty API version", kind))
+		}
+		if versions[conversion.APIVersion] {
+			panic(fmt.Sprintf("Kind '%s' has duplicated API version: %s", kind, conversion.APIVersion))
+		}
+		versions[conversion.APIVersion] = true
+
+		if conversion.Constructor == nil || conversion.ToHub == nil || conversion.FromHub == nil {
+			panic(fmt.Sprintf("Kind '%s' conversion for API version %s should have constructor and conversion functions", kind, conversion.APIVersion))
+		}
+	}
 }
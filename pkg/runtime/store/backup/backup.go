@@ -0,0 +1,246 @@
+package backup
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/codec/encrypted"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"github.com/Aptomi/aptomi/pkg/version"
+	yamlv2 "gopkg.in/yaml.v2"
+	"io"
+	"io/ioutil"
+	"time"
+)
+
+const (
+	// Format is a format name of the backup archive written into its manifest
+	Format = "aptomi-backup"
+
+	// Version is a version of the backup archive format. Archives with newer format version can't be restored
+	Version = 1
+
+	manifestFile = "manifest.yaml"
+	objectsFile  = "objects.yaml"
+)
+
+// Manifest describes backup archive. Archive is a gzipped tar with the manifest and all objects (including all their
+// generations) in YAML, which allows to restore it into any type of the object store
+type Manifest struct {
+	Format        string
+	Version       int
+	CreatedAt     time.Time
+	AptomiVersion string
+
+	// Objects is a number of objects (counting every generation separately) in the archive
+	Objects int
+
+	// Checksum is a SHA-256 checksum of the objects file in the archive
+	Checksum string
+
+	// Encrypted is true if objects file is encrypted with the DB encryption key, such archive could only be restored
+	// into DB configured with the key file containing that key
+	Encrypted bool
+}
+
+// Write makes backup of all objects stored in the provided store and writes archive into the writer. All objects are
+// retrieved from the store in one call, so archive represents consistent state of the store. Objects are encoded with
+// the store codec, so archive is encrypted if encryption is configured for the store
+func Write(w io.Writer, s store.Generic) (*Manifest, error) {
+	objs, err := s.List("")
+	if err != nil {
+		return nil, fmt.Errorf("error while listing objects: %s", err)
+	}
+
+	runtimeObjs := make([]runtime.Object, 0, len(objs))
+	for _, obj := range objs {
+		runtimeObjs = append(runtimeObjs, obj)
+	}
+	data, err := s.Codec().EncodeMany(runtimeObjs)
+	if err != nil {
+		return nil, fmt.Errorf("error while encoding objects: %s", err)
+	}
+
+	manifest := &Manifest{
+		Format:        Format,
+		Version:       Version,
+		CreatedAt:     time.Now(),
+		AptomiVersion: version.GetBuildInfo().GitVersion,
+		Objects:       len(objs),
+		Checksum:      checksum(data),
+		Encrypted:     encrypted.IsEncrypted(data),
+	}
+	manifestData, err := yamlv2.Marshal(manifest)
+	if err != nil {
+		return nil, fmt.Errorf("error while encoding manifest: %s", err)
+	}
+
+	gzipWriter := gzip.NewWriter(w)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for _, file := range []struct {
+		name string
+		data []byte
+	}{{manifestFile, manifestData}, {objectsFile, data}} {
+		err = tarWriter.WriteHeader(&tar.Header{Name: file.name, Mode: 0600, Size: int64(len(file.data)), ModTime: manifest.CreatedAt})
+		if err != nil {
+			return nil, fmt.Errorf("error while writing archive: %s", err)
+		}
+		_, err = tarWriter.Write(file.data)
+		if err != nil {
+			return nil, fmt.Errorf("error while writing archive: %s", err)
+		}
+	}
+	err = tarWriter.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error while writing archive: %s", err)
+	}
+	err = gzipWriter.Close()
+	if err != nil {
+		return nil, fmt.Errorf("error while writing archive: %s", err)
+	}
+
+	return manifest, nil
+}
+
+// Verify reads backup archive and verifies its integrity without decoding objects, so it doesn't require encryption
+// keys for encrypted archive
+func Verify(r io.Reader) (*Manifest, error) {
+	manifest, _, err := readArchive(r)
+	return manifest, err
+}
+
+// Read reads backup archive and verifies its integrity, returning its manifest and all objects from it. Objects are
+// decoded with the provided codec, which should have the key archive is encrypted with, if it's encrypted
+func Read(r io.Reader, codec runtime.Codec) (*Manifest, []runtime.Storable, error) {
+	manifest, data, err := readArchive(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	objs, err := codec.DecodeOneOrMany(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while decoding objects: %s", err)
+	}
+	if len(objs) != manifest.Objects {
+		return nil, nil, fmt.Errorf("archive is corrupted, it contains %d objects, but %d expected", len(objs), manifest.Objects)
+	}
+
+	registry := newRegistry()
+	result := make([]runtime.Storable, 0, len(objs))
+	seen := make(map[string]bool)
+	for _, obj := range objs {
+		storable, ok := obj.(runtime.Storable)
+		if !ok {
+			return nil, nil, fmt.Errorf("archive contains non-storable object: %s", obj.GetKind())
+		}
+		id := runtime.KeyForStorable(storable) + "@" + store.GenerationOf(registry, storable).String()
+		if seen[id] {
+			return nil, nil, fmt.Errorf("archive contains duplicate object: %s", id)
+		}
+		seen[id] = true
+		result = append(result, storable)
+	}
+
+	return manifest, result, nil
+}
+
+// readArchive reads backup archive and verifies its manifest and checksum, returning the manifest and objects file
+func readArchive(r io.Reader) (*Manifest, []byte, error) {
+	gzipReader, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while reading archive: %s", err)
+	}
+	tarReader := tar.NewReader(gzipReader)
+
+	files := make(map[string][]byte)
+	for {
+		header, errNext := tarReader.Next()
+		if errNext == io.EOF {
+			break
+		}
+		if errNext != nil {
+			return nil, nil, fmt.Errorf("error while reading archive: %s", errNext)
+		}
+		files[header.Name], err = ioutil.ReadAll(tarReader)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error while reading %s from archive: %s", header.Name, err)
+		}
+	}
+
+	manifestData, ok := files[manifestFile]
+	if !ok {
+		return nil, nil, fmt.Errorf("archive doesn't contain %s", manifestFile)
+	}
+	manifest := &Manifest{}
+	err = yamlv2.Unmarshal(manifestData, manifest)
+	if err != nil {
+		return nil, nil, fmt.Errorf("error while decoding manifest: %s", err)
+	}
+	if manifest.Format != Format {
+		return nil, nil, fmt.Errorf("unknown archive format: %s", manifest.Format)
+	}
+	if manifest.Version > Version {
+		return nil, nil, fmt.Errorf("archive format version %d isn't supported, latest supported version is %d", manifest.Version, Version)
+	}
+
+	data, ok := files[objectsFile]
+	if !ok {
+		return nil, nil, fmt.Errorf("archive doesn't contain %s", objectsFile)
+	}
+	if sum := checksum(data); sum != manifest.Checksum {
+		return nil, nil, fmt.Errorf("archive is corrupted, checksum of objects is %s, but %s expected", sum, manifest.Checksum)
+	}
+
+	return manifest, data, nil
+}
+
+// Restore reads backup archive, verifies its integrity and writes all objects from it into the provided store, which
+// should be empty. Objects are written atomically, so either all of them or none are restored. Encrypted archive is
+// decrypted with the store codec, so store should be configured with the key file containing the archive key
+func Restore(r io.Reader, s store.Generic) (*Manifest, error) {
+	manifest, objs, err := Read(r, s.Codec())
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.List("")
+	if err != nil {
+		return nil, fmt.Errorf("error while listing objects: %s", err)
+	}
+	if len(existing) > 0 {
+		return nil, fmt.Errorf("backup could be restored only into empty store, but it contains %d objects", len(existing))
+	}
+
+	// all objects are written in a single transaction, so store is left empty if restore fails in the middle
+	tx, err := s.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("error while starting transaction: %s", err)
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	for _, obj := range objs {
+		err = tx.Put(obj)
+		if err != nil {
+			return nil, fmt.Errorf("error while restoring %s: %s", runtime.KeyForStorable(obj), err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("error while committing restored objects: %s", err)
+	}
+
+	return manifest, nil
+}
+
+func newRegistry() *runtime.Registry {
+	return runtime.NewRegistry().Append(store.Objects...)
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
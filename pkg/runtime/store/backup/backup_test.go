@@ -0,0 +1,173 @@
+package backup_test
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/codec/encrypted"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/backup"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/core"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/generic"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openStore(t *testing.T, cfg config.DB) store.Generic {
+	t.Helper()
+	s, err := generic.NewStore(runtime.NewRegistry().Append(store.Objects...), cfg)
+	if err == nil {
+		err = s.Open(cfg)
+	}
+	if err != nil {
+		t.Fatalf("can't open store: %s", err)
+	}
+	return s
+}
+
+// makeBackup creates a store with two policy generations, one of them with deleted service, and a revoked token
+func makeBackup(t *testing.T, cfg config.DB) []byte {
+	t.Helper()
+	s := core.NewStore(openStore(t, cfg))
+	assert.NoError(t, s.InitPolicy(), "Policy should be initialized")
+
+	service := &lang.Service{
+		TypeKind: lang.ServiceObject.GetTypeKind(),
+		Metadata: lang.Metadata{Namespace: "main", Name: "svc"},
+	}
+	_, _, err := s.UpdatePolicy([]lang.Base{service}, "test")
+	assert.NoError(t, err, "Policy should be updated")
+	_, _, err = s.DeleteFromPolicy([]lang.Base{service}, "test")
+	assert.NoError(t, err, "Object should be deleted from policy")
+	_, err = s.RevokeUserToken("token", time.Now().Add(time.Hour))
+	assert.NoError(t, err, "Token should be revoked")
+
+	data := &bytes.Buffer{}
+	assert.NoError(t, s.Backup(data), "Backup should be made")
+	return data.Bytes()
+}
+
+func TestBackupRestore(t *testing.T) {
+	data := makeBackup(t, config.DB{Connection: "memory://"})
+
+	dir, err := ioutil.TempDir("", "aptomi-backup-test")
+	if !assert.NoError(t, err, "Temp dir should be created") {
+		return
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	// backup made from in-memory store is restored into SQLite
+	target := openStore(t, config.DB{Type: generic.TypeSQLite, Connection: filepath.Join(dir, "db.sqlite")})
+	defer target.Close() // nolint: errcheck
+
+	manifest, err := backup.Restore(bytes.NewReader(data), target)
+	if !assert.NoError(t, err, "Backup should be restored") {
+		return
+	}
+	assert.Equal(t, backup.Format, manifest.Format, "Manifest should have correct format")
+	assert.Equal(t, 6, manifest.Objects, "All generations should be restored (3 policy generations, 2 service generations, revoked token)")
+
+	restored := core.NewStore(target)
+	policy, gen, err := restored.GetPolicy(runtime.LastGen)
+	if assert.NoError(t, err, "Policy should be loaded") {
+		assert.Equal(t, runtime.Generation(3), gen, "Last policy generation should be restored")
+		assert.Len(t, policy.GetObjectsByKind(lang.ServiceObject.Kind), 0, "Deleted service shouldn't be in the last policy")
+	}
+	policy, _, err = restored.GetPolicy(2)
+	if assert.NoError(t, err, "Policy should be loaded") {
+		assert.Len(t, policy.GetObjectsByKind(lang.ServiceObject.Kind), 1, "Service should be in the previous policy")
+	}
+	revoked, err := restored.IsUserTokenRevoked("token")
+	assert.NoError(t, err, "Revoked token should be checked")
+	assert.True(t, revoked, "Revoked token should be restored")
+
+	// backup can't be restored into non-empty store
+	_, err = backup.Restore(bytes.NewReader(data), target)
+	assert.Error(t, err, "Backup shouldn't be restored into non-empty store")
+}
+
+func TestBackupCorrupted(t *testing.T) {
+	data := makeBackup(t, config.DB{Connection: "memory://"})
+
+	// replace objects in the archive, keeping the original manifest
+	gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+	if !assert.NoError(t, err, "Archive should be read") {
+		return
+	}
+	tarReader := tar.NewReader(gzipReader)
+	corrupted := &bytes.Buffer{}
+	gzipWriter := gzip.NewWriter(corrupted)
+	tarWriter := tar.NewWriter(gzipWriter)
+	for {
+		header, errNext := tarReader.Next()
+		if errNext == io.EOF {
+			break
+		}
+		content, _ := ioutil.ReadAll(tarReader)
+		if header.Name == "objects.yaml" {
+			content = content[:len(content)/2]
+			header.Size = int64(len(content))
+		}
+		assert.NoError(t, tarWriter.WriteHeader(header), "Header should be written")
+		_, errWrite := tarWriter.Write(content)
+		assert.NoError(t, errWrite, "Content should be written")
+	}
+	assert.NoError(t, tarWriter.Close(), "Archive should be written")
+	assert.NoError(t, gzipWriter.Close(), "Archive should be written")
+
+	_, err = backup.Verify(corrupted)
+	if assert.Error(t, err, "Corrupted backup shouldn't be read") {
+		assert.Contains(t, err.Error(), "checksum", "Checksum mismatch should be reported")
+	}
+
+	_, err = backup.Verify(bytes.NewReader([]byte("not an archive")))
+	assert.Error(t, err, "Invalid backup shouldn't be read")
+}
+
+func TestBackupEncrypted(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aptomi-backup-test")
+	if !assert.NoError(t, err, "Temp dir should be created") {
+		return
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	keyFile := filepath.Join(dir, "keys.yaml")
+	if !assert.NoError(t, encrypted.AddKey(keyFile, "key1"), "Key should be generated") {
+		return
+	}
+	encryption := config.Encryption{KeyFile: keyFile}
+
+	// backup of the store with encryption configured is encrypted as well
+	data := makeBackup(t, config.DB{Connection: "memory://", Encryption: encryption})
+	manifest, err := backup.Verify(bytes.NewReader(data))
+	if assert.NoError(t, err, "Encrypted backup should be verified without key") {
+		assert.True(t, manifest.Encrypted, "Backup should be marked as encrypted")
+	}
+	gzipReader, err := gzip.NewReader(bytes.NewReader(data))
+	if assert.NoError(t, err, "Archive should be read") {
+		plain, _ := ioutil.ReadAll(gzipReader)
+		assert.False(t, bytes.Contains(plain, []byte("namespace: main")), "Archive shouldn't contain plain objects")
+	}
+
+	// encrypted backup can't be restored without the key
+	_, err = backup.Restore(bytes.NewReader(data), openStore(t, config.DB{Connection: "memory://"}))
+	assert.Error(t, err, "Encrypted backup shouldn't be restored without key")
+
+	target := openStore(t, config.DB{Connection: "memory://", Encryption: encryption})
+	manifest, err = backup.Restore(bytes.NewReader(data), target)
+	if assert.NoError(t, err, "Encrypted backup should be restored with key") {
+		assert.Equal(t, 6, manifest.Objects, "All objects should be restored")
+	}
+	policy, _, err := core.NewStore(target).GetPolicy(2)
+	if assert.NoError(t, err, "Policy should be loaded") {
+		assert.Len(t, policy.GetObjectsByKind(lang.ServiceObject.Kind), 1, "Service should be restored")
+	}
+}
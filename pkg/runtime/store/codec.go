@@ -0,0 +1,25 @@
+package store
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/codec/encrypted"
+	"github.com/Aptomi/aptomi/pkg/runtime/codec/yaml"
+)
+
+// NewCodec returns codec, which should be used by generic store implementations for encoding stored objects. Objects
+// are encoded into YAML and, if encryption key file is configured, encrypted. Encrypted objects are detected even if
+// encryption isn't configured, so they are reported as such instead of failing to decode
+func NewCodec(registry *runtime.Registry, cfg config.DB) (runtime.Codec, error) {
+	var ring *encrypted.KeyRing
+	if len(cfg.Encryption.KeyFile) > 0 {
+		var err error
+		ring, err = encrypted.LoadKeyRing(cfg.Encryption.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("error while loading encryption keys: %s", err)
+		}
+	}
+
+	return encrypted.NewCodec(yaml.NewCodec(registry), ring), nil
+}
@@ -1,12 +1,16 @@
 package store
 
 import (
+	"github.com/Aptomi/aptomi/pkg/auth"
+	"github.com/Aptomi/aptomi/pkg/config"
 	"github.com/Aptomi/aptomi/pkg/engine"
 	"github.com/Aptomi/aptomi/pkg/engine/actual"
 	"github.com/Aptomi/aptomi/pkg/engine/progress"
 	"github.com/Aptomi/aptomi/pkg/engine/resolve"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
+	"io"
+	"time"
 )
 
 // Core represents main object store interface that covers database operations for all objects
@@ -14,6 +18,11 @@ type Core interface {
 	Policy
 	Revision
 	ActualState
+	Token
+	ExternalUser
+	Compaction
+	Backup
+	Watch
 }
 
 // Policy represents database operations for Policy object
@@ -42,3 +51,46 @@ type ActualState interface {
 	GetActualStateUpdater() actual.StateUpdater
 	ResetActualState() error
 }
+
+// ExternalUser represents database operations for users authenticated by external identity providers
+type ExternalUser interface {
+	GetExternalUser(name string) (*auth.ExternalUser, error)
+	ListExternalUsers() ([]*auth.ExternalUser, error)
+	SaveExternalUser(user *auth.ExternalUser) error
+}
+
+// Token represents database operations for API tokens and revoked user tokens
+type Token interface {
+	GetToken(name string) (*auth.APIToken, error)
+	ListTokens() ([]*auth.APIToken, error)
+	SaveToken(token *auth.APIToken) error
+	DeleteToken(name string) error
+	UpdateTokenLastUsed(name string, usedAt time.Time) error
+	RevokeUserToken(id string, expiresAt time.Time) (bool, error)
+	IsUserTokenRevoked(id string) (bool, error)
+}
+
+// Compaction represents database operations for removing old generations of policy and revisions
+type Compaction interface {
+	Compact(retention config.Retention, now time.Time) (*CompactionResult, error)
+}
+
+// CompactionResult represents number of generations removed by compaction
+type CompactionResult struct {
+	Revisions         int
+	PolicyGenerations int
+	ObjectGenerations int
+}
+
+// Backup represents database operations for making consistent backup of all objects
+type Backup interface {
+	Backup(w io.Writer) error
+}
+
+// Watch represents subscribing to changes of policy and revisions made in the store
+type Watch interface {
+	// WatchPolicy returns watcher, which delivers event every time new policy generation is saved
+	WatchPolicy() (Watcher, error)
+	// WatchRevisions returns watcher, which delivers event every time revision is created or its progress is updated
+	WatchRevisions() (Watcher, error)
+}
@@ -54,15 +54,22 @@ func (ds *defaultStore) ResetActualState() error {
 		return fmt.Errorf("error while getting all component instances: %s", err)
 	}
 
+	// all instances are deleted in a single transaction, so actual state is never reset partially
+	tx, err := ds.store.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback() // nolint: errcheck
+
 	for _, instanceObj := range instances {
 		if instance, ok := instanceObj.(*resolve.ComponentInstance); ok {
 			key := runtime.KeyForStorable(instance)
-			deleteErr := ds.store.Delete(key)
+			deleteErr := tx.Delete(key)
 			if deleteErr != nil {
 				return deleteErr
 			}
 		}
 	}
 
-	return nil
+	return tx.Commit()
 }
@@ -0,0 +1,12 @@
+package core
+
+import (
+	"github.com/Aptomi/aptomi/pkg/runtime/store/backup"
+	"io"
+)
+
+// Backup writes backup archive of all objects from the underlying generic store
+func (ds *defaultStore) Backup(w io.Writer) error {
+	_, err := backup.Write(w, ds.store)
+	return err
+}
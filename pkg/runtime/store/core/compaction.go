@@ -0,0 +1,144 @@
+package core
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"sort"
+	"time"
+)
+
+// Compact removes old revisions, policy generations and generations of policy objects which aren't retained according
+// to the specified retention config. Policy generations referenced by retained revisions are never removed, as well as
+// the last generation of every object
+func (ds *defaultStore) Compact(retention config.Retention, now time.Time) (*store.CompactionResult, error) {
+	result := &store.CompactionResult{}
+	if !retention.IsEnabled() {
+		return result, nil
+	}
+
+	// policy shouldn't be changed while we are compacting it
+	ds.policyChangeLock.Lock()
+	defer ds.policyChangeLock.Unlock()
+
+	// remove old revisions, remembering which policy generations are referenced by the retained ones
+	revisionObjs, err := ds.store.ListGenerations(engine.RevisionKey)
+	if err != nil {
+		return nil, fmt.Errorf("error while listing revisions: %s", err)
+	}
+	revisions := make([]*engine.Revision, 0, len(revisionObjs))
+	for _, obj := range revisionObjs {
+		revisions = append(revisions, obj.(*engine.Revision))
+	}
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].GetGeneration() < revisions[j].GetGeneration()
+	})
+
+	referencedPolicyGens := make(map[runtime.Generation]bool)
+	for idx, revision := range revisions {
+		// revisions which are still being applied don't have time set yet and should always be kept
+		if revision.Status == engine.RevisionStatusInProgress || isRetained(idx, len(revisions), revision.AppliedAt, retention.Revisions, retention.MaxAge, now) {
+			referencedPolicyGens[revision.Policy] = true
+			continue
+		}
+
+		err = ds.store.DeleteGen(engine.RevisionKey, revision.GetGeneration())
+		if err != nil {
+			return nil, fmt.Errorf("error while deleting revision %s: %s", revision.GetGeneration(), err)
+		}
+		result.Revisions++
+	}
+
+	// remove old policy generations
+	policyObjs, err := ds.store.ListGenerations(engine.PolicyDataKey)
+	if err != nil {
+		return nil, fmt.Errorf("error while listing policy generations: %s", err)
+	}
+	policies := make([]*engine.PolicyData, 0, len(policyObjs))
+	for _, obj := range policyObjs {
+		policies = append(policies, obj.(*engine.PolicyData))
+	}
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].GetGeneration() < policies[j].GetGeneration()
+	})
+
+	// key -> generation -> true for all object generations referenced by retained policy generations
+	retainedObjects := make(map[string]map[runtime.Generation]bool)
+	// keys of objects referenced by removed policy generations, only they could have generations to remove
+	candidateKeys := make(map[string]bool)
+	for idx, policyData := range policies {
+		retained := referencedPolicyGens[policyData.GetGeneration()] || isRetained(idx, len(policies), policyData.Metadata.UpdatedAt, retention.PolicyGenerations, retention.MaxAge, now)
+		for ns, kindNameGen := range policyData.Objects {
+			for kind, nameGen := range kindNameGen {
+				for name, gen := range nameGen {
+					key := runtime.KeyFromParts(ns, kind, name)
+					if !retained {
+						candidateKeys[key] = true
+						continue
+					}
+					if retainedObjects[key] == nil {
+						retainedObjects[key] = make(map[runtime.Generation]bool)
+					}
+					retainedObjects[key][gen] = true
+				}
+			}
+		}
+
+		if retained {
+			continue
+		}
+
+		err = ds.store.DeleteGen(engine.PolicyDataKey, policyData.GetGeneration())
+		if err != nil {
+			return nil, fmt.Errorf("error while deleting policy generation %s: %s", policyData.GetGeneration(), err)
+		}
+		result.PolicyGenerations++
+	}
+
+	// remove generations of policy objects which aren't referenced by any retained policy generation
+	for key := range candidateKeys {
+		objs, errList := ds.store.ListGenerations(key)
+		if errList != nil {
+			return nil, fmt.Errorf("error while listing generations of %s: %s", key, errList)
+		}
+
+		var lastGen runtime.Generation
+		for _, obj := range objs {
+			if gen := obj.(runtime.Versioned).GetGeneration(); gen > lastGen {
+				lastGen = gen
+			}
+		}
+
+		for _, obj := range objs {
+			gen := obj.(runtime.Versioned).GetGeneration()
+			if gen == lastGen || retainedObjects[key][gen] {
+				continue
+			}
+
+			err = ds.store.DeleteGen(key, gen)
+			if err != nil {
+				return nil, fmt.Errorf("error while deleting generation %s of %s: %s", gen, key, err)
+			}
+			result.ObjectGenerations++
+		}
+	}
+
+	return result, nil
+}
+
+// isRetained returns true if generation at the specified position (in the list sorted by generation) should be kept,
+// because it's one of the last keepLast generations or it's younger than maxAge. The last generation is always kept
+func isRetained(idx int, total int, createdAt time.Time, keepLast int, maxAge time.Duration, now time.Time) bool {
+	if keepLast <= 0 && maxAge <= 0 {
+		return true
+	}
+	if idx == total-1 {
+		return true
+	}
+	if keepLast > 0 && idx >= total-keepLast {
+		return true
+	}
+	return maxAge > 0 && now.Sub(createdAt) < maxAge
+}
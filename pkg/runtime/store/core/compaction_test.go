@@ -0,0 +1,116 @@
+package core
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+// makeHistory creates policy generations 1..5 (service generations 1..4 referenced by policy generations 2..5) and
+// two applied revisions for policy generations 2 and 5
+func makeHistory(t *testing.T) store.Core {
+	t.Helper()
+	s := newMemoryStore(t)
+	if !assert.NoError(t, s.InitPolicy(), "Policy should be initialized") {
+		t.FailNow()
+	}
+
+	for i := 1; i <= 4; i++ {
+		service := &lang.Service{
+			TypeKind: lang.ServiceObject.GetTypeKind(),
+			Metadata: lang.Metadata{Namespace: "main", Name: "svc"},
+			Labels:   map[string]string{"version": fmt.Sprintf("%d", i)},
+		}
+		_, _, err := s.UpdatePolicy([]lang.Base{service}, "test")
+		if !assert.NoError(t, err, "Policy should be updated") {
+			t.FailNow()
+		}
+
+		if i == 1 || i == 4 {
+			revision, err := s.NewRevision(runtime.Generation(i + 1))
+			if !assert.NoError(t, err, "Revision should be created") {
+				t.FailNow()
+			}
+			revision.Status = engine.RevisionStatusSuccess
+			revision.AppliedAt = time.Now()
+			if !assert.NoError(t, s.SaveRevision(revision), "Revision should be saved") {
+				t.FailNow()
+			}
+		}
+	}
+
+	return s
+}
+
+func TestCompactKeepLast(t *testing.T) {
+	s := makeHistory(t)
+
+	result, err := s.Compact(config.Retention{PolicyGenerations: 2, Revisions: 1}, time.Now())
+	if !assert.NoError(t, err, "Compaction should succeed") {
+		return
+	}
+	assert.Equal(t, &store.CompactionResult{Revisions: 1, PolicyGenerations: 3, ObjectGenerations: 2}, result, "Compaction result should be correct")
+
+	revision, err := s.GetRevision(runtime.FirstGen)
+	assert.NoError(t, err, "Revision should be loaded")
+	assert.Nil(t, revision, "Old revision should be removed")
+
+	for gen, exists := range map[runtime.Generation]bool{1: false, 3: false, 4: true, 5: true} {
+		policy, _, err := s.GetPolicy(gen)
+		assert.NoError(t, err, "Policy should be loaded")
+		assert.Equal(t, exists, policy != nil, "Policy generation %s should be retained: %t", gen, exists)
+	}
+
+	// policy could be updated after compaction and still references correct objects
+	policy, gen, err := s.GetPolicy(runtime.LastGen)
+	if assert.NoError(t, err, "Last policy should be loaded") {
+		assert.Equal(t, runtime.Generation(5), gen, "Last policy generation should be kept")
+		assert.Equal(t, "4", policy.GetObjectsByKind(lang.ServiceObject.Kind)[0].(*lang.Service).Labels["version"], "Last policy should reference latest service")
+	}
+	policy, _, err = s.GetPolicy(4)
+	if assert.NoError(t, err, "Retained policy should be loaded") {
+		assert.Equal(t, "3", policy.GetObjectsByKind(lang.ServiceObject.Kind)[0].(*lang.Service).Labels["version"], "Retained policy should reference retained service generation")
+	}
+
+	// second compaction doesn't remove anything
+	result, err = s.Compact(config.Retention{PolicyGenerations: 2, Revisions: 1}, time.Now())
+	assert.NoError(t, err, "Compaction should succeed")
+	assert.Equal(t, &store.CompactionResult{}, result, "Nothing should be removed by the second compaction")
+}
+
+func TestCompactKeepsPolicyReferencedByRevision(t *testing.T) {
+	s := makeHistory(t)
+
+	result, err := s.Compact(config.Retention{PolicyGenerations: 1, Revisions: 2}, time.Now())
+	if !assert.NoError(t, err, "Compaction should succeed") {
+		return
+	}
+	assert.Equal(t, &store.CompactionResult{Revisions: 0, PolicyGenerations: 3, ObjectGenerations: 2}, result, "Compaction result should be correct")
+
+	policy, _, err := s.GetPolicy(2)
+	if assert.NoError(t, err, "Policy should be loaded") && assert.NotNil(t, policy, "Policy referenced by revision should be retained") {
+		assert.Equal(t, "1", policy.GetObjectsByKind(lang.ServiceObject.Kind)[0].(*lang.Service).Labels["version"], "Policy referenced by revision should reference its service generation")
+	}
+}
+
+func TestCompactMaxAge(t *testing.T) {
+	s := makeHistory(t)
+
+	result, err := s.Compact(config.Retention{MaxAge: time.Hour}, time.Now())
+	assert.NoError(t, err, "Compaction should succeed")
+	assert.Equal(t, &store.CompactionResult{}, result, "Nothing should be removed if everything is young")
+
+	result, err = s.Compact(config.Retention{MaxAge: time.Hour}, time.Now().Add(2*time.Hour))
+	assert.NoError(t, err, "Compaction should succeed")
+	assert.Equal(t, &store.CompactionResult{Revisions: 1, PolicyGenerations: 4, ObjectGenerations: 3}, result, "Everything except the last generations should be removed")
+
+	result, err = s.Compact(config.Retention{}, time.Now())
+	assert.NoError(t, err, "Compaction should succeed")
+	assert.Equal(t, &store.CompactionResult{}, result, "Nothing should be removed without retention config")
+}
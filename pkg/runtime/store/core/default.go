@@ -8,11 +8,13 @@ import (
 // defaultStore is the generic store implementation that is the glue layer for saving
 // different engine objects into the object store
 type defaultStore struct {
-	policyChangeLock sync.Mutex
-	store            store.Generic
+	policyChangeLock   sync.Mutex
+	revisionChangeLock sync.Mutex
+	tokenChangeLock    sync.Mutex
+	store              store.Generic
 }
 
 // NewStore returns default implementation of generic store
 func NewStore(store store.Generic) store.Core {
-	return &defaultStore{sync.Mutex{}, store}
+	return &defaultStore{store: store}
 }
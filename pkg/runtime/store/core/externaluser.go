@@ -0,0 +1,49 @@
+package core
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/auth"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"strings"
+)
+
+// GetExternalUser returns record of the user authenticated by external identity provider or nil if it doesn't exist
+func (ds *defaultStore) GetExternalUser(name string) (*auth.ExternalUser, error) {
+	userObj, err := ds.store.Get(runtime.KeyFromParts(runtime.SystemNS, auth.ExternalUserObject.Kind, strings.ToLower(name)))
+	if err != nil {
+		return nil, fmt.Errorf("error while getting external user '%s': %s", name, err)
+	}
+	if userObj == nil {
+		return nil, nil
+	}
+
+	user, ok := userObj.(*auth.ExternalUser)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type while getting ExternalUser from DB")
+	}
+
+	return user, nil
+}
+
+// ListExternalUsers returns records of all users authenticated by external identity providers
+func (ds *defaultStore) ListExternalUsers() ([]*auth.ExternalUser, error) {
+	userObjs, err := ds.store.List(runtime.KeyFromParts(runtime.SystemNS, auth.ExternalUserObject.Kind, ""))
+	if err != nil {
+		return nil, fmt.Errorf("error while getting all external users: %s", err)
+	}
+
+	result := []*auth.ExternalUser{}
+	for _, userObj := range userObjs {
+		if user, ok := userObj.(*auth.ExternalUser); ok {
+			result = append(result, user)
+		}
+	}
+
+	return result, nil
+}
+
+// SaveExternalUser saves record of the user authenticated by external identity provider
+func (ds *defaultStore) SaveExternalUser(user *auth.ExternalUser) error {
+	_, err := ds.store.Save(user)
+	return err
+}
@@ -80,6 +80,13 @@ func (ds *defaultStore) UpdatePolicy(updatedObjects []lang.Base, performedBy str
 		panic(fmt.Sprintf("Cannot retrieve last policy from the store, policyData is nil"))
 	}
 
+	// all objects and policy data are saved in a single transaction, so policy generation is never written partially
+	tx, err := ds.store.Begin()
+	if err != nil {
+		return false, nil, err
+	}
+	defer tx.Rollback() // nolint: errcheck
+
 	changed := false
 	for _, updatedObj := range updatedObjects {
 		if updatedObj.IsDeleted() {
@@ -87,7 +94,7 @@ func (ds *defaultStore) UpdatePolicy(updatedObjects []lang.Base, performedBy str
 		}
 
 		var changedObj bool
-		changedObj, err = ds.store.Save(updatedObj)
+		changedObj, err = tx.Save(updatedObj)
 		if err != nil {
 			return false, nil, err
 		}
@@ -103,13 +110,18 @@ func (ds *defaultStore) UpdatePolicy(updatedObjects []lang.Base, performedBy str
 		policyData.Metadata.UpdatedBy = performedBy
 
 		// save policy data
-		_, err = ds.store.Save(policyData)
+		_, err = tx.Save(policyData)
 		if err != nil {
 			return false, nil, err
 		}
 	}
 
-	return changed, policyData, err
+	err = tx.Commit()
+	if err != nil {
+		return false, nil, fmt.Errorf("error while committing policy update: %s", err)
+	}
+
+	return changed, policyData, nil
 }
 
 // InitPolicy initializes policy (on the first run of Aptomi)
@@ -140,6 +152,12 @@ func (ds *defaultStore) DeleteFromPolicy(deleted []lang.Base, performedBy string
 		return false, nil, err
 	}
 
+	tx, err := ds.store.Begin()
+	if err != nil {
+		return false, nil, err
+	}
+	defer tx.Rollback() // nolint: errcheck
+
 	policyChanged := false
 	for _, obj := range deleted {
 		if policyData.Remove(obj) {
@@ -148,7 +166,7 @@ func (ds *defaultStore) DeleteFromPolicy(deleted []lang.Base, performedBy string
 
 		if !obj.IsDeleted() {
 			obj.SetDeleted(true)
-			_, err = ds.store.Save(obj)
+			_, err = tx.Save(obj)
 			if err != nil {
 				return false, nil, fmt.Errorf("error while setting deleted=true for %s: %s", runtime.KeyForStorable(obj), err)
 			}
@@ -160,11 +178,16 @@ func (ds *defaultStore) DeleteFromPolicy(deleted []lang.Base, performedBy string
 		policyData.Metadata.UpdatedBy = performedBy
 
 		// save policy data
-		_, err = ds.store.Save(policyData)
+		_, err = tx.Save(policyData)
 		if err != nil {
 			return false, nil, err
 		}
 	}
 
+	err = tx.Commit()
+	if err != nil {
+		return false, nil, fmt.Errorf("error while committing policy update: %s", err)
+	}
+
 	return policyChanged, policyData, nil
 }
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/generic/memory"
+	"github.com/stretchr/testify/assert"
+	"testing"
+	"time"
+)
+
+func newMemoryStore(t *testing.T) store.Core {
+	t.Helper()
+	b := memory.NewGenericStore(runtime.NewRegistry().Append(store.Objects...))
+	if err := b.Open(config.DB{Connection: "memory://"}); err != nil {
+		t.Fatalf("can't open in-memory store: %s", err)
+	}
+	return NewStore(b)
+}
+
+func TestPolicyUpdateAndDelete(t *testing.T) {
+	s := newMemoryStore(t)
+	if !assert.NoError(t, s.InitPolicy(), "Policy should be initialized") {
+		return
+	}
+
+	service := &lang.Service{
+		TypeKind: lang.ServiceObject.GetTypeKind(),
+		Metadata: lang.Metadata{Namespace: "main", Name: "svc"},
+	}
+	changed, data, err := s.UpdatePolicy([]lang.Base{service}, "test")
+	if !assert.NoError(t, err, "Policy should be updated") {
+		return
+	}
+	assert.True(t, changed, "Policy should be changed")
+
+	changed, _, err = s.UpdatePolicy([]lang.Base{service}, "test")
+	assert.NoError(t, err, "Policy should be updated")
+	assert.False(t, changed, "Policy shouldn't be changed by the same object")
+
+	policy, gen, err := s.GetPolicy(runtime.LastGen)
+	if assert.NoError(t, err, "Policy should be loaded") {
+		assert.Equal(t, data.GetGeneration(), gen, "Last policy generation should be returned")
+		assert.Len(t, policy.GetObjectsByKind(lang.ServiceObject.Kind), 1, "Policy should contain saved service")
+	}
+
+	changed, _, err = s.DeleteFromPolicy([]lang.Base{service}, "test")
+	assert.NoError(t, err, "Object should be deleted from policy")
+	assert.True(t, changed, "Policy should be changed")
+
+	policy, _, err = s.GetPolicy(runtime.LastGen)
+	if assert.NoError(t, err, "Policy should be loaded") {
+		assert.Len(t, policy.GetObjectsByKind(lang.ServiceObject.Kind), 0, "Deleted service shouldn't be in the policy")
+	}
+}
+
+func TestPolicyUpdateIsAtomic(t *testing.T) {
+	s := newMemoryStore(t)
+	if !assert.NoError(t, s.InitPolicy(), "Policy should be initialized") {
+		return
+	}
+
+	first := &lang.Service{
+		TypeKind: lang.ServiceObject.GetTypeKind(),
+		Metadata: lang.Metadata{Namespace: "main", Name: "first"},
+	}
+	second := &lang.Service{
+		TypeKind: lang.ServiceObject.GetTypeKind(),
+		Metadata: lang.Metadata{Namespace: "main", Name: "second", Deleted: true},
+	}
+	_, _, err := s.UpdatePolicy([]lang.Base{first, second}, "test")
+	assert.Error(t, err, "Policy update with deleted object should fail")
+
+	policy, gen, err := s.GetPolicy(runtime.LastGen)
+	if assert.NoError(t, err, "Policy should be loaded") {
+		assert.Equal(t, runtime.FirstGen, gen, "New policy generation shouldn't be created by failed update")
+		assert.Len(t, policy.GetObjectsByKind(lang.ServiceObject.Kind), 0, "Objects from failed update shouldn't be saved")
+	}
+}
+
+func TestWatchPolicy(t *testing.T) {
+	s := newMemoryStore(t)
+	if !assert.NoError(t, s.InitPolicy(), "Policy should be initialized") {
+		return
+	}
+
+	watcher, err := s.WatchPolicy()
+	if !assert.NoError(t, err, "Policy watcher should be created") {
+		return
+	}
+	defer watcher.Stop()
+
+	service := &lang.Service{
+		TypeKind: lang.ServiceObject.GetTypeKind(),
+		Metadata: lang.Metadata{Namespace: "main", Name: "svc"},
+	}
+	_, data, err := s.UpdatePolicy([]lang.Base{service}, "test")
+	if !assert.NoError(t, err, "Policy should be updated") {
+		return
+	}
+
+	select {
+	case event := <-watcher.Events():
+		assert.Equal(t, data.GetGeneration(), event.Generation, "Event for the new policy generation should be delivered")
+	case <-time.After(time.Second):
+		t.Fatal("Policy change event should be delivered")
+	}
+	assert.Len(t, watcher.Events(), 0, "Only policy data changes should be delivered")
+}
@@ -94,9 +94,32 @@ func (ds *defaultStore) NewRevision(policyGen runtime.Generation) (*engine.Revis
 	}, nil
 }
 
-// SaveRevision saves specified Revision into the store with possibly new generation creation
+// SaveRevision saves specified Revision into the store with possibly new generation creation. Revision created by
+// NewRevision is saved only if no other revision was saved since then, so revision generations are never overwritten
 func (ds *defaultStore) SaveRevision(revision *engine.Revision) error {
-	_, err := ds.store.Save(revision)
+	ds.revisionChangeLock.Lock()
+	defer ds.revisionChangeLock.Unlock()
+
+	tx, err := ds.store.Begin()
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %s", err)
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	currRevision, err := tx.GetGen(engine.RevisionKey, runtime.LastGen)
+	if err != nil {
+		return fmt.Errorf("error while geting current revision: %s", err)
+	}
+	if currRevision != nil && currRevision.GetGeneration() > revision.GetGeneration() {
+		return fmt.Errorf("revision %s is outdated, current revision is %s", revision.GetGeneration(), currRevision.GetGeneration())
+	}
+
+	_, err = tx.Save(revision)
+	if err != nil {
+		return fmt.Errorf("error while saving revision: %s", err)
+	}
+
+	err = tx.Commit()
 	if err != nil {
 		return fmt.Errorf("error while saving revision: %s", err)
 	}
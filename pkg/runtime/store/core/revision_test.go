@@ -0,0 +1,28 @@
+package core
+
+import (
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSaveOutdatedRevision(t *testing.T) {
+	s := newMemoryStore(t)
+
+	first, err := s.NewRevision(runtime.FirstGen)
+	if !assert.NoError(t, err, "Revision should be created") {
+		return
+	}
+	second, err := s.NewRevision(runtime.FirstGen)
+	if !assert.NoError(t, err, "Revision should be created") {
+		return
+	}
+	assert.Equal(t, first.GetGeneration(), second.GetGeneration(), "Revisions created before saving should have the same generation")
+
+	assert.NoError(t, s.SaveRevision(second), "Revision should be saved")
+	second.Status = engine.RevisionStatusError
+	assert.NoError(t, s.SaveRevision(second), "Revision should be saved again with new generation")
+
+	assert.Error(t, s.SaveRevision(first), "Outdated revision shouldn't be saved")
+}
@@ -0,0 +1,153 @@
+package core
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/auth"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"sort"
+	"time"
+)
+
+// GetToken returns API token by its name or nil if it doesn't exist
+func (ds *defaultStore) GetToken(name string) (*auth.APIToken, error) {
+	tokenObj, err := ds.store.Get(runtime.KeyFromParts(runtime.SystemNS, auth.APITokenObject.Kind, name))
+	if err != nil {
+		return nil, fmt.Errorf("error while getting token '%s': %s", name, err)
+	}
+	if tokenObj == nil {
+		return nil, nil
+	}
+
+	token, ok := tokenObj.(*auth.APIToken)
+	if !ok {
+		return nil, fmt.Errorf("unexpected type while getting APIToken from DB")
+	}
+
+	return token, nil
+}
+
+// ListTokens returns all API tokens sorted by name
+func (ds *defaultStore) ListTokens() ([]*auth.APIToken, error) {
+	tokenObjs, err := ds.store.List(runtime.KeyFromParts(runtime.SystemNS, auth.APITokenObject.Kind, ""))
+	if err != nil {
+		return nil, fmt.Errorf("error while getting all tokens: %s", err)
+	}
+
+	result := []*auth.APIToken{}
+	for _, tokenObj := range tokenObjs {
+		if token, ok := tokenObj.(*auth.APIToken); ok {
+			result = append(result, token)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Name < result[j].Name
+	})
+
+	return result, nil
+}
+
+// SaveToken saves API token, overwriting existing token with the same name
+func (ds *defaultStore) SaveToken(token *auth.APIToken) error {
+	ds.tokenChangeLock.Lock()
+	defer ds.tokenChangeLock.Unlock()
+
+	_, err := ds.store.Save(token)
+	return err
+}
+
+// DeleteToken deletes API token by its name
+func (ds *defaultStore) DeleteToken(name string) error {
+	ds.tokenChangeLock.Lock()
+	defer ds.tokenChangeLock.Unlock()
+
+	return ds.store.Delete(runtime.KeyFromParts(runtime.SystemNS, auth.APITokenObject.Kind, name))
+}
+
+// UpdateTokenLastUsed sets the time when API token was last used. It fails if token doesn't exist anymore, so the
+// token revoked while it was being used doesn't get saved back
+func (ds *defaultStore) UpdateTokenLastUsed(name string, usedAt time.Time) error {
+	ds.tokenChangeLock.Lock()
+	defer ds.tokenChangeLock.Unlock()
+
+	tx, err := ds.store.Begin()
+	if err != nil {
+		return fmt.Errorf("error while starting transaction: %s", err)
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	tokenObj, err := tx.Get(runtime.KeyFromParts(runtime.SystemNS, auth.APITokenObject.Kind, name))
+	if err != nil {
+		return fmt.Errorf("error while getting token '%s': %s", name, err)
+	}
+	token, ok := tokenObj.(*auth.APIToken)
+	if !ok {
+		return fmt.Errorf("token '%s' doesn't exist", name)
+	}
+	if !usedAt.After(token.LastUsedAt) {
+		return nil
+	}
+
+	token.LastUsedAt = usedAt
+	_, err = tx.Save(token)
+	if err != nil {
+		return fmt.Errorf("error while updating token '%s': %s", name, err)
+	}
+
+	return tx.Commit()
+}
+
+// RevokeUserToken adds user token with a given ID into the revocation list. It returns false if token has already
+// been revoked, so only one of concurrent callers revokes the token. Revocation entries for already expired tokens get
+// removed from the list
+func (ds *defaultStore) RevokeUserToken(id string, expiresAt time.Time) (bool, error) {
+	ds.tokenChangeLock.Lock()
+	defer ds.tokenChangeLock.Unlock()
+
+	revokedObjs, err := ds.store.List(runtime.KeyFromParts(runtime.SystemNS, auth.RevokedTokenObject.Kind, ""))
+	if err != nil {
+		return false, fmt.Errorf("error while getting revoked tokens: %s", err)
+	}
+
+	tx, err := ds.store.Begin()
+	if err != nil {
+		return false, fmt.Errorf("error while starting transaction: %s", err)
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	now := time.Now()
+	for _, revokedObj := range revokedObjs {
+		revoked, ok := revokedObj.(*auth.RevokedToken)
+		if !ok {
+			continue
+		}
+		if revoked.ID == id && !revoked.IsExpired(now) {
+			return false, nil
+		}
+		if revoked.IsExpired(now) {
+			deleteErr := tx.Delete(runtime.KeyForStorable(revoked))
+			if deleteErr != nil {
+				return false, fmt.Errorf("error while deleting expired revoked token: %s", deleteErr)
+			}
+		}
+	}
+
+	_, err = tx.Save(&auth.RevokedToken{
+		TypeKind:  auth.RevokedTokenObject.GetTypeKind(),
+		ID:        id,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return false, fmt.Errorf("error while revoking token '%s': %s", id, err)
+	}
+
+	return true, tx.Commit()
+}
+
+// IsUserTokenRevoked returns true if user token with a given ID is in the revocation list
+func (ds *defaultStore) IsUserTokenRevoked(id string) (bool, error) {
+	revokedObj, err := ds.store.Get(runtime.KeyFromParts(runtime.SystemNS, auth.RevokedTokenObject.Kind, id))
+	if err != nil {
+		return false, fmt.Errorf("error while checking revoked token '%s': %s", id, err)
+	}
+	return revokedObj != nil, nil
+}
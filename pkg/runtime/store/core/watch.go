@@ -0,0 +1,17 @@
+package core
+
+import (
+	"github.com/Aptomi/aptomi/pkg/engine"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+)
+
+// WatchPolicy returns watcher for policy changes. All policy objects are saved together with the policy data in a
+// single transaction, so it's enough to watch policy data only
+func (ds *defaultStore) WatchPolicy() (store.Watcher, error) {
+	return ds.store.Watch(engine.PolicyDataKey)
+}
+
+// WatchRevisions returns watcher for revisions, including updates of their progress
+func (ds *defaultStore) WatchRevisions() (store.Watcher, error) {
+	return ds.store.Watch(engine.RevisionKey)
+}
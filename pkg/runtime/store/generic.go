@@ -22,6 +22,20 @@ type Generic interface {
 	// Update always updates existing object in db and not creating new generation even for versioned objects
 	// todo(slukjanov): introduce "status" for objects and don't update version when only status changed
 	Update(runtime.Storable) (updated bool, err error)
+	// Put stores object as is, under its own generation and without any versioning checks, it's used to restore backups
+	Put(runtime.Storable) error
 
 	Delete(key string) error
+	// DeleteGen deletes specified generation of the versioned object, it's used to remove old generations from db
+	DeleteGen(key string, gen runtime.Generation) error
+
+	// Begin starts a new transaction, all changes made through it are applied to db atomically on commit
+	Begin() (Transaction, error)
+
+	// Watch returns watcher, which delivers events about all changes made to objects with keys matching the prefix
+	Watch(prefix string) (Watcher, error)
+
+	// Codec returns codec objects are stored with, it encrypts them if encryption is configured, so it's also used to
+	// keep backups of objects encrypted
+	Codec() runtime.Codec
 }
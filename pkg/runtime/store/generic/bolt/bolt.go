@@ -7,28 +7,32 @@ import (
 	"fmt"
 	"github.com/Aptomi/aptomi/pkg/config"
 	"github.com/Aptomi/aptomi/pkg/runtime"
-	"github.com/Aptomi/aptomi/pkg/runtime/codec/yaml"
 	"github.com/Aptomi/aptomi/pkg/runtime/store"
 	"github.com/boltdb/bolt"
-	"reflect"
 	"time"
 )
 
 // NewGenericStore creates a new object store based on BoltDB
 func NewGenericStore(registry *runtime.Registry) store.Generic {
-	codec := yaml.NewCodec(registry)
-	return &boltStore{registry: registry, codec: codec}
+	return &boltStore{registry: registry, hub: store.NewWatchHub()}
 }
 
 type boltStore struct {
 	registry *runtime.Registry
 	codec    runtime.Codec
 	db       *bolt.DB
+	hub      *store.WatchHub
 }
 
 var objectsBucket = []byte("objects")
 
 func (bs *boltStore) Open(cfg config.DB) error {
+	codec, err := store.NewCodec(bs.registry, cfg)
+	if err != nil {
+		return err
+	}
+	bs.codec = codec
+
 	connection := cfg.Connection
 	db, err := bolt.Open(connection, 0600, &bolt.Options{Timeout: 5 * time.Second})
 	if err != nil {
@@ -44,6 +48,8 @@ func (bs *boltStore) Open(cfg config.DB) error {
 }
 
 func (bs *boltStore) Close() error {
+	bs.hub.Close()
+
 	err := bs.db.Close()
 	if err != nil {
 		return fmt.Errorf("error while closing BoltDB: %s", err)
@@ -151,24 +157,6 @@ func (bs *boltStore) ListGenerations(key string) ([]runtime.Storable, error) {
 	return bs.List(key + boltSeparator)
 }
 
-func (bs *boltStore) setNextGeneration(obj runtime.Versioned) error {
-	// todo replace this code by checking index that returns last generation
-	info := bs.registry.Get(obj.GetKind())
-	if !info.Versioned {
-		return fmt.Errorf("kind %s isn't versioned", obj.GetKind())
-	}
-	last, err := bs.GetGen(runtime.KeyForStorable(obj), runtime.LastGen)
-	if err != nil {
-		return err
-	}
-	var newGen runtime.Generation = 1
-	if last != nil {
-		newGen = last.GetGeneration().Next()
-	}
-	obj.SetGeneration(newGen)
-	return nil
-}
-
 func (bs *boltStore) Save(obj runtime.Storable) (bool, error) {
 	return bs.save(obj, false)
 }
@@ -177,62 +165,22 @@ func (bs *boltStore) Update(obj runtime.Storable) (bool, error) {
 	return bs.save(obj, true)
 }
 
+func (bs *boltStore) Put(obj runtime.Storable) error {
+	return bs.put(obj, store.GenerationOf(bs.registry, obj))
+}
+
 func (bs *boltStore) save(obj runtime.Storable, updateCurrent bool) (bool, error) {
-	info := bs.registry.Get(obj.GetKind())
-	if info == nil {
-		return false, fmt.Errorf("unknown kind: %s", obj.GetKind())
+	gen, updated, err := store.PrepareSave(bs.registry, bs.GetGen, obj, updateCurrent)
+	if err != nil {
+		return false, err
 	}
-	key := runtime.KeyForStorable(obj)
-	boltPath := key
-	updated := false
-
-	if info.Versioned { // todo extract into "Prepare versioned"
-		versionedObj, ok := obj.(runtime.Versioned)
-		if !ok {
-			return false, fmt.Errorf("versioned object doesn't implement Versioned interface: %s", obj.GetKind())
-		}
-
-		// todo we should compare with latest in some cases
-		existingObj, err := bs.GetGen(key, versionedObj.GetGeneration())
-		if err != nil {
-			return false, err
-		}
 
-		deletable, ok := obj.(runtime.Deletable)
-		if ok && deletable.IsDeleted() {
-			if !info.Deletable {
-				return false, fmt.Errorf("trying mark object deleted=true that isn't explicitly marked as deletable: %s %s", info.Kind, key)
-			}
-
-			if existingObj == nil {
-				return false, fmt.Errorf("trying to make non-existing in db object with deleted=true: %s %s", info.Kind, key)
-			}
-		}
-
-		if existingObj != nil {
-			versionedObj.SetGeneration(existingObj.GetGeneration())
-			equals, equalsErr := bs.equals(obj, existingObj)
-			if equalsErr != nil {
-				return false, equalsErr
-			}
-			if !updateCurrent && !equals {
-				errGen := bs.setNextGeneration(versionedObj)
-				if errGen != nil {
-					return false, fmt.Errorf("error while calling setNextGeneration(%s): %s", obj, errGen)
-				}
-				updated = true
-			}
-		} else {
-			if versionedObj.GetGeneration() == runtime.LastGen {
-				versionedObj.SetGeneration(runtime.FirstGen)
-			}
-			updated = true
-		}
+	return updated, bs.put(obj, gen)
+}
 
-		boltPath += boltSeparator + genStr(versionedObj.GetGeneration())
-	} else { // not versioned
-		boltPath += boltSeparator + genStr(runtime.LastGen)
-	}
+func (bs *boltStore) put(obj runtime.Storable, gen runtime.Generation) error {
+	key := runtime.KeyForStorable(obj)
+	boltPath := key + boltSeparator + genStr(gen)
 
 	err := bs.db.Update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket(objectsBucket)
@@ -247,14 +195,18 @@ func (bs *boltStore) save(obj runtime.Storable, updateCurrent bool) (bool, error
 
 		return bucket.Put([]byte(boltPath), data)
 	})
+	if err != nil {
+		return err
+	}
 
-	return updated, err
+	bs.hub.Notify(store.Event{Type: store.EventPut, Key: key, Generation: gen})
+	return nil
 }
 
 func (bs *boltStore) Delete(key string) error {
 	// todo support deleting version objects, potentially we don't want to remove any object, just mark as deleted
 
-	return bs.db.Update(func(tx *bolt.Tx) error {
+	err := bs.db.Update(func(tx *bolt.Tx) error {
 		bucket := tx.Bucket(objectsBucket)
 		if bucket == nil {
 			return fmt.Errorf("bucket not found: %s", objectsBucket)
@@ -280,20 +232,87 @@ func (bs *boltStore) Delete(key string) error {
 
 		return nil
 	})
+	if err != nil {
+		return err
+	}
+
+	bs.hub.Notify(store.Event{Type: store.EventDelete, Key: key, Generation: runtime.LastGen})
+	return nil
 }
 
-func (bs *boltStore) equals(o1 runtime.Object, o2 runtime.Object) (bool, error) {
-	o1bytes, err := bs.codec.EncodeOne(o1)
+func (bs *boltStore) DeleteGen(key string, gen runtime.Generation) error {
+	if gen == runtime.LastGen {
+		return fmt.Errorf("generation should be specified to delete versioned object: %s", key)
+	}
+
+	err := bs.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(objectsBucket)
+		if bucket == nil {
+			return fmt.Errorf("bucket not found: %s", objectsBucket)
+		}
+
+		err := bucket.Delete([]byte(key + boltSeparator + genStr(gen)))
+		if err != nil {
+			return fmt.Errorf("error while deleting object with key: %s generation: %s", key, gen)
+		}
+
+		return nil
+	})
 	if err != nil {
-		return false, err
+		return err
 	}
 
-	o2bytes, err := bs.codec.EncodeOne(o2)
+	bs.hub.Notify(store.Event{Type: store.EventDelete, Key: key, Generation: gen})
+	return nil
+}
+
+func (bs *boltStore) Begin() (store.Transaction, error) {
+	return store.NewTransaction(bs.registry, bs.codec, bs, bs.apply), nil
+}
+
+func (bs *boltStore) apply(ops []store.Op) error {
+	// all changes are made in a single BoltDB transaction, so they are either all applied or rolled back on error
+	err := bs.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(objectsBucket)
+		if bucket == nil {
+			return fmt.Errorf("bucket not found: %s", objectsBucket)
+		}
+
+		for _, op := range ops {
+			if !op.Delete {
+				err := bucket.Put([]byte(op.Key+boltSeparator+genStr(op.Generation)), op.Data)
+				if err != nil {
+					return fmt.Errorf("error while saving object with key: %s", op.Key)
+				}
+				continue
+			}
+
+			c := bucket.Cursor()
+			prefixBytes := []byte(op.Key + boltSeparator)
+			for k, _ := c.Seek(prefixBytes); k != nil && bytes.HasPrefix(k, prefixBytes); k, _ = c.Next() {
+				err := c.Delete()
+				if err != nil {
+					return fmt.Errorf("error while deleting object with key: %s", k)
+				}
+			}
+		}
+
+		return nil
+	})
 	if err != nil {
-		return false, err
+		return err
 	}
 
-	return reflect.DeepEqual(o1bytes, o2bytes), nil
+	bs.hub.NotifyOps(ops)
+	return nil
+}
+
+func (bs *boltStore) Watch(prefix string) (store.Watcher, error) {
+	return bs.hub.Watch(prefix), nil
+}
+
+func (bs *boltStore) Codec() runtime.Codec {
+	return bs.codec
 }
 
 // todo replace with adding bytes to []byte
@@ -0,0 +1,45 @@
+package generic
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/generic/bolt"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/generic/memory"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/generic/sql"
+	"strings"
+)
+
+const (
+	// TypeBolt is a type of the object store based on BoltDB, it's used by default
+	TypeBolt = "bolt"
+
+	// TypeSQLite is a type of the object store based on SQLite
+	TypeSQLite = sql.DriverSQLite
+
+	// TypeMemory is a type of the in-memory object store, which doesn't persist any data
+	TypeMemory = "memory"
+
+	// memoryConnection is a connection string which selects in-memory object store if type isn't specified
+	memoryConnection = "memory://"
+)
+
+// NewStore creates a new generic object store of the type specified in DB config. It doesn't open the store
+func NewStore(registry *runtime.Registry, cfg config.DB) (store.Generic, error) {
+	storeType := cfg.Type
+	if len(storeType) == 0 && strings.HasPrefix(cfg.Connection, memoryConnection) {
+		storeType = TypeMemory
+	}
+
+	switch storeType {
+	case "", TypeBolt:
+		return bolt.NewGenericStore(registry), nil
+	case TypeSQLite:
+		return sql.NewGenericStore(registry, sql.DriverSQLite), nil
+	case TypeMemory:
+		return memory.NewGenericStore(registry), nil
+	default:
+		return nil, fmt.Errorf("unknown object store type: %s", cfg.Type)
+	}
+}
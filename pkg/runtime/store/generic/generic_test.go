@@ -0,0 +1,402 @@
+package generic
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/auth"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/codec/encrypted"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+var storeTypes = []string{TypeBolt, TypeSQLite, TypeMemory}
+
+func withStore(t *testing.T, testFunc func(t *testing.T, s store.Generic)) {
+	t.Helper()
+	withStoreConfig(t, func(cfg *config.DB) {}, testFunc)
+}
+
+func withStoreConfig(t *testing.T, configure func(cfg *config.DB), testFunc func(t *testing.T, s store.Generic)) {
+	t.Helper()
+	for _, storeType := range storeTypes {
+		t.Run(storeType, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "aptomi-store-test")
+			if !assert.NoError(t, err, "Temp dir should be created") {
+				return
+			}
+			defer os.RemoveAll(dir) // nolint: errcheck
+
+			registry := runtime.NewRegistry().Append(lang.ServiceObject, auth.RevokedTokenObject)
+			cfg := config.DB{Type: storeType, Connection: filepath.Join(dir, "db")}
+			configure(&cfg)
+			s, err := NewStore(registry, cfg)
+			if !assert.NoError(t, err, "Store should be created") {
+				return
+			}
+			if !assert.NoError(t, s.Open(cfg), "Store should be opened") {
+				return
+			}
+			defer s.Close() // nolint: errcheck
+
+			testFunc(t, s)
+		})
+	}
+}
+
+func makeService(name string, labels map[string]string) *lang.Service {
+	return &lang.Service{
+		TypeKind: lang.ServiceObject.GetTypeKind(),
+		Metadata: lang.Metadata{Namespace: "main", Name: name},
+		Labels:   labels,
+	}
+}
+
+func TestNewStoreMemoryConnection(t *testing.T) {
+	s, err := NewStore(runtime.NewRegistry(), config.DB{Connection: "memory://"})
+	if assert.NoError(t, err, "Store should be created") {
+		assert.Equal(t, "*memory.memoryStore", fmt.Sprintf("%T", s), "In-memory store should be selected by connection string")
+	}
+}
+
+func TestStoreReturnsCopies(t *testing.T) {
+	withStore(t, func(t *testing.T, s store.Generic) {
+		service := makeService("svc", map[string]string{"a": "1"})
+		_, err := s.Save(service)
+		assert.NoError(t, err, "Object should be saved")
+		service.Labels["a"] = "changed"
+
+		key := runtime.KeyFromParts("main", lang.ServiceObject.Kind, "svc")
+		loaded, err := s.GetGen(key, runtime.LastGen)
+		if assert.NoError(t, err, "Object should be loaded") && assert.NotNil(t, loaded, "Object should exist") {
+			assert.Equal(t, "1", loaded.(*lang.Service).Labels["a"], "Stored object shouldn't be affected by changes of the saved one")
+			loaded.(*lang.Service).Labels["a"] = "changed"
+		}
+
+		loaded, err = s.GetGen(key, runtime.LastGen)
+		if assert.NoError(t, err, "Object should be loaded") && assert.NotNil(t, loaded, "Object should exist") {
+			assert.Equal(t, "1", loaded.(*lang.Service).Labels["a"], "Stored object shouldn't be affected by changes of the loaded one")
+		}
+	})
+}
+
+func TestNewStoreUnknownType(t *testing.T) {
+	_, err := NewStore(runtime.NewRegistry(), config.DB{Type: "unknown", Connection: "db"})
+	assert.Error(t, err, "Unknown store type should result in error")
+}
+
+func TestStoreVersionedGenerations(t *testing.T) {
+	withStore(t, func(t *testing.T, s store.Generic) {
+		key := runtime.KeyFromParts("main", lang.ServiceObject.Kind, "svc")
+
+		// first save creates first generation
+		updated, err := s.Save(makeService("svc", map[string]string{"a": "1"}))
+		assert.NoError(t, err, "Object should be saved")
+		assert.True(t, updated, "First save should create an object")
+
+		// saving the same object doesn't create new generation
+		updated, err = s.Save(makeService("svc", map[string]string{"a": "1"}))
+		assert.NoError(t, err, "Object should be saved")
+		assert.False(t, updated, "Saving the same object shouldn't create new generation")
+
+		// saving changed object creates new generation
+		updated, err = s.Save(makeService("svc", map[string]string{"a": "2"}))
+		assert.NoError(t, err, "Object should be saved")
+		assert.True(t, updated, "Saving changed object should create new generation")
+
+		last, err := s.GetGen(key, runtime.LastGen)
+		if assert.NoError(t, err, "Last generation should be loaded") && assert.NotNil(t, last, "Last generation should exist") {
+			assert.Equal(t, runtime.Generation(2), last.GetGeneration(), "Last generation should be correct")
+			assert.Equal(t, "2", last.(*lang.Service).Labels["a"], "Last generation should have latest data")
+		}
+
+		first, err := s.GetGen(key, runtime.FirstGen)
+		if assert.NoError(t, err, "First generation should be loaded") && assert.NotNil(t, first, "First generation should exist") {
+			assert.Equal(t, "1", first.(*lang.Service).Labels["a"], "First generation should keep its data")
+		}
+
+		missing, err := s.GetGen(key, runtime.Generation(10))
+		assert.NoError(t, err, "Loading missing generation shouldn't fail")
+		assert.Nil(t, missing, "Missing generation should be nil")
+
+		// update changes current generation in place
+		current := makeService("svc", map[string]string{"a": "3"})
+		current.Generation = 2
+		updated, err = s.Update(current)
+		assert.NoError(t, err, "Object should be updated")
+		assert.False(t, updated, "Update shouldn't create new generation")
+
+		gens, err := s.ListGenerations(key)
+		if assert.NoError(t, err, "Generations should be listed") && assert.Len(t, gens, 2, "There should be two generations") {
+			assert.Equal(t, "1", gens[0].(*lang.Service).Labels["a"], "Generations should be ordered")
+			assert.Equal(t, "3", gens[1].(*lang.Service).Labels["a"], "Current generation should be updated in place")
+		}
+
+		// deleting versioned objects isn't supported, but their old generations could be deleted
+		assert.Error(t, s.Delete(key), "Versioned objects can't be deleted")
+		assert.NoError(t, s.DeleteGen(key, runtime.FirstGen), "Generation should be deleted")
+		gens, err = s.ListGenerations(key)
+		if assert.NoError(t, err, "Generations should be listed") && assert.Len(t, gens, 1, "There should be one generation left") {
+			assert.Equal(t, runtime.Generation(2), gens[0].(runtime.Versioned).GetGeneration(), "Last generation should be left")
+		}
+	})
+}
+
+func TestStoreDeletedMarker(t *testing.T) {
+	withStore(t, func(t *testing.T, s store.Generic) {
+		deleted := makeService("svc", nil)
+		deleted.Deleted = true
+		_, err := s.Save(deleted)
+		assert.Error(t, err, "Non-existing object can't be saved as deleted")
+
+		_, err = s.Save(makeService("svc", nil))
+		assert.NoError(t, err, "Object should be saved")
+
+		deleted = makeService("svc", nil)
+		deleted.Deleted = true
+		updated, err := s.Save(deleted)
+		assert.NoError(t, err, "Existing object should be marked as deleted")
+		assert.True(t, updated, "Marking object as deleted should create new generation")
+		assert.Equal(t, runtime.Generation(2), deleted.GetGeneration(), "Generation should be set on the saved object")
+	})
+}
+
+func TestStoreNonVersioned(t *testing.T) {
+	withStore(t, func(t *testing.T, s store.Generic) {
+		expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Second)
+		for _, id := range []string{"b", "a", "c"} {
+			_, err := s.Save(&auth.RevokedToken{TypeKind: auth.RevokedTokenObject.GetTypeKind(), ID: id, ExpiresAt: expiresAt})
+			assert.NoError(t, err, "Object should be saved")
+		}
+		_, err := s.Save(makeService("svc", nil))
+		assert.NoError(t, err, "Object should be saved")
+
+		key := runtime.KeyFromParts(runtime.SystemNS, auth.RevokedTokenObject.Kind, "a")
+		obj, err := s.Get(key)
+		if assert.NoError(t, err, "Object should be loaded") && assert.NotNil(t, obj, "Object should exist") {
+			assert.Equal(t, expiresAt, obj.(*auth.RevokedToken).ExpiresAt, "Object should be loaded with its data")
+		}
+
+		list, err := s.List(runtime.KeyFromParts(runtime.SystemNS, auth.RevokedTokenObject.Kind, ""))
+		if assert.NoError(t, err, "Objects should be listed") && assert.Len(t, list, 3, "Only objects with prefix should be listed") {
+			assert.Equal(t, "a", list[0].GetName(), "Objects should be ordered by key")
+			assert.Equal(t, "c", list[2].GetName(), "Objects should be ordered by key")
+		}
+
+		assert.NoError(t, s.Delete(key), "Object should be deleted")
+		obj, err = s.Get(key)
+		assert.NoError(t, err, "Loading deleted object shouldn't fail")
+		assert.Nil(t, obj, "Deleted object should be nil")
+
+		list, err = s.List("")
+		assert.NoError(t, err, "Objects should be listed")
+		assert.Len(t, list, 3, "All remaining objects should be listed")
+	})
+}
+
+func TestStoreTransaction(t *testing.T) {
+	withStore(t, func(t *testing.T, s store.Generic) {
+		key := runtime.KeyFromParts("main", lang.ServiceObject.Kind, "svc")
+		revokedKey := runtime.KeyFromParts(runtime.SystemNS, auth.RevokedTokenObject.Kind, "token")
+
+		// rolled back changes shouldn't be visible
+		tx, err := s.Begin()
+		if !assert.NoError(t, err, "Transaction should be started") {
+			return
+		}
+		_, err = tx.Save(makeService("svc", map[string]string{"a": "1"}))
+		assert.NoError(t, err, "Object should be saved in transaction")
+		assert.NoError(t, tx.Rollback(), "Transaction should be rolled back")
+		obj, err := s.GetGen(key, runtime.LastGen)
+		assert.NoError(t, err, "Object should be loaded")
+		assert.Nil(t, obj, "Object from rolled back transaction shouldn't be saved")
+
+		// changes should be visible inside of transaction and only after commit outside of it
+		tx, err = s.Begin()
+		if !assert.NoError(t, err, "Transaction should be started") {
+			return
+		}
+		defer tx.Rollback() // nolint: errcheck
+		for _, value := range []string{"1", "2", "3"} {
+			updated, saveErr := tx.Save(makeService("svc", map[string]string{"a": value}))
+			assert.NoError(t, saveErr, "Object should be saved in transaction")
+			assert.True(t, updated, "New generation should be created in transaction")
+		}
+		_, err = tx.Save(&auth.RevokedToken{TypeKind: auth.RevokedTokenObject.GetTypeKind(), ID: "token"})
+		assert.NoError(t, err, "Object should be saved in transaction")
+
+		obj, err = tx.GetGen(key, runtime.LastGen)
+		if assert.NoError(t, err, "Object should be loaded in transaction") && assert.NotNil(t, obj, "Object should be visible in transaction") {
+			assert.Equal(t, runtime.Generation(3), obj.GetGeneration(), "Last generation written in transaction should be returned")
+		}
+		obj, err = s.GetGen(key, runtime.LastGen)
+		assert.NoError(t, err, "Object should be loaded")
+		assert.Nil(t, obj, "Object shouldn't be visible before commit")
+
+		if !assert.NoError(t, tx.Commit(), "Transaction should be committed") {
+			return
+		}
+		assert.Error(t, tx.Commit(), "Transaction couldn't be committed twice")
+
+		objs, err := s.ListGenerations(key)
+		assert.NoError(t, err, "Generations should be listed")
+		assert.Len(t, objs, 3, "All generations saved in transaction should be stored")
+		revoked, err := s.Get(revokedKey)
+		assert.NoError(t, err, "Object should be loaded")
+		assert.NotNil(t, revoked, "Non-versioned object saved in transaction should be stored")
+
+		// delete should be applied on commit as well
+		tx, err = s.Begin()
+		if !assert.NoError(t, err, "Transaction should be started") {
+			return
+		}
+		assert.Error(t, tx.Delete(key), "Versioned object couldn't be deleted")
+		assert.NoError(t, tx.Delete(revokedKey), "Object should be deleted in transaction")
+		revoked, err = tx.Get(revokedKey)
+		assert.NoError(t, err, "Object should be loaded in transaction")
+		assert.Nil(t, revoked, "Deleted object shouldn't be visible in transaction")
+		assert.NoError(t, tx.Commit(), "Transaction should be committed")
+
+		revoked, err = s.Get(revokedKey)
+		assert.NoError(t, err, "Object should be loaded")
+		assert.Nil(t, revoked, "Object deleted in transaction shouldn't be stored")
+	})
+}
+
+func nextEvent(t *testing.T, watcher store.Watcher) (store.Event, bool) {
+	t.Helper()
+	select {
+	case event, ok := <-watcher.Events():
+		return event, ok
+	case <-time.After(time.Second):
+		t.Fatal("Event should be delivered to watcher")
+		return store.Event{}, false
+	}
+}
+
+func TestStoreWatch(t *testing.T) {
+	withStore(t, func(t *testing.T, s store.Generic) {
+		key := runtime.KeyFromParts("main", lang.ServiceObject.Kind, "svc")
+		revokedKey := runtime.KeyFromParts(runtime.SystemNS, auth.RevokedTokenObject.Kind, "token")
+
+		watcher, err := s.Watch("main/")
+		if !assert.NoError(t, err, "Watcher should be created") {
+			return
+		}
+		allWatcher, err := s.Watch("")
+		if !assert.NoError(t, err, "Watcher should be created") {
+			return
+		}
+		defer allWatcher.Stop()
+
+		_, err = s.Save(&auth.RevokedToken{TypeKind: auth.RevokedTokenObject.GetTypeKind(), ID: "token"})
+		assert.NoError(t, err, "Object should be saved")
+		_, err = s.Save(makeService("svc", map[string]string{"a": "1"}))
+		assert.NoError(t, err, "Object should be saved")
+
+		tx, err := s.Begin()
+		if !assert.NoError(t, err, "Transaction should be started") {
+			return
+		}
+		_, err = tx.Save(makeService("svc", map[string]string{"a": "2"}))
+		assert.NoError(t, err, "Object should be saved in transaction")
+		assert.NoError(t, tx.Delete(revokedKey), "Object should be deleted in transaction")
+		assert.NoError(t, tx.Commit(), "Transaction should be committed")
+
+		assert.NoError(t, s.DeleteGen(key, 1), "Generation should be deleted")
+
+		// watcher should get only events for objects matching its prefix
+		for _, expected := range []store.Event{
+			{Type: store.EventPut, Key: key, Generation: 1},
+			{Type: store.EventPut, Key: key, Generation: 2},
+			{Type: store.EventDelete, Key: key, Generation: 1},
+		} {
+			event, ok := nextEvent(t, watcher)
+			assert.True(t, ok, "Watcher shouldn't be closed")
+			assert.Equal(t, expected, event, "Event should be delivered to watcher")
+		}
+
+		for _, expected := range []store.Event{
+			{Type: store.EventPut, Key: revokedKey, Generation: runtime.LastGen},
+			{Type: store.EventPut, Key: key, Generation: 1},
+			{Type: store.EventPut, Key: key, Generation: 2},
+			{Type: store.EventDelete, Key: revokedKey, Generation: runtime.LastGen},
+			{Type: store.EventDelete, Key: key, Generation: 1},
+		} {
+			event, ok := nextEvent(t, allWatcher)
+			assert.True(t, ok, "Watcher shouldn't be closed")
+			assert.Equal(t, expected, event, "Event should be delivered to watcher")
+		}
+
+		// stopped watcher shouldn't get any more events
+		watcher.Stop()
+		_, err = s.Save(makeService("svc", map[string]string{"a": "3"}))
+		assert.NoError(t, err, "Object should be saved")
+		_, ok := nextEvent(t, watcher)
+		assert.False(t, ok, "Stopped watcher should be closed")
+	})
+}
+
+func TestStoreWatchOverflow(t *testing.T) {
+	withStore(t, func(t *testing.T, s store.Generic) {
+		watcher, err := s.Watch("")
+		if !assert.NoError(t, err, "Watcher should be created") {
+			return
+		}
+
+		// watcher which doesn't read events should be closed instead of blocking writes
+		for i := 0; i < 300; i++ {
+			_, err = s.Save(&auth.RevokedToken{TypeKind: auth.RevokedTokenObject.GetTypeKind(), ID: fmt.Sprintf("token-%d", i)})
+			if !assert.NoError(t, err, "Object should be saved") {
+				return
+			}
+		}
+
+		count := 0
+		for range watcher.Events() {
+			count++
+		}
+		assert.True(t, count < 300, "Slow watcher should be closed after its buffer overflows")
+	})
+}
+
+func TestStoreEncryption(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aptomi-store-keys")
+	if !assert.NoError(t, err, "Temp dir should be created") {
+		return
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+	keyFile := filepath.Join(dir, "keys.yaml")
+	if !assert.NoError(t, encrypted.AddKey(keyFile, "k1"), "Encryption key should be generated") {
+		return
+	}
+
+	var connection string
+	withStoreConfig(t, func(cfg *config.DB) {
+		cfg.Encryption.KeyFile = keyFile
+		connection = cfg.Connection
+	}, func(t *testing.T, s store.Generic) {
+		key := runtime.KeyFromParts("main", lang.ServiceObject.Kind, "svc")
+		for i := 0; i < 2; i++ {
+			updated, saveErr := s.Save(makeService("svc", map[string]string{"password": "secret-value"}))
+			assert.NoError(t, saveErr, "Object should be saved")
+			assert.Equal(t, i == 0, updated, "Saving the same encrypted object again shouldn't create new generation")
+		}
+
+		loaded, err := s.GetGen(key, runtime.LastGen)
+		if assert.NoError(t, err, "Object should be loaded") && assert.NotNil(t, loaded, "Object should exist") {
+			assert.Equal(t, "secret-value", loaded.(*lang.Service).Labels["password"], "Object should be decrypted transparently")
+		}
+
+		// in-memory store doesn't have any file
+		if data, readErr := ioutil.ReadFile(connection); readErr == nil {
+			assert.NotContains(t, string(data), "secret-value", "DB file shouldn't contain plain values")
+		}
+	})
+}
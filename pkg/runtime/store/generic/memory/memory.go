@@ -0,0 +1,265 @@
+package memory
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// NewGenericStore creates a new object store which keeps all data in memory. Objects are stored in encoded form, so
+// callers always get a copy and can't mutate stored objects. All data is lost when store is closed
+func NewGenericStore(registry *runtime.Registry) store.Generic {
+	return &memoryStore{registry: registry, hub: store.NewWatchHub()}
+}
+
+type memoryStore struct {
+	registry *runtime.Registry
+	codec    runtime.Codec
+	hub      *store.WatchHub
+
+	mutex   sync.RWMutex
+	objects map[string]map[runtime.Generation][]byte
+}
+
+func (ms *memoryStore) Open(cfg config.DB) error {
+	codec, err := store.NewCodec(ms.registry, cfg)
+	if err != nil {
+		return err
+	}
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.codec = codec
+	ms.objects = make(map[string]map[runtime.Generation][]byte)
+	return nil
+}
+
+func (ms *memoryStore) Close() error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	ms.hub.Close()
+	ms.objects = nil
+	return nil
+}
+
+func (ms *memoryStore) Get(key string) (runtime.Storable, error) {
+	ms.mutex.RLock()
+	data := ms.objects[key][runtime.LastGen]
+	ms.mutex.RUnlock()
+
+	if data == nil {
+		return nil, nil
+	}
+
+	return ms.decode(data)
+}
+
+func (ms *memoryStore) GetGen(key string, gen runtime.Generation) (runtime.Versioned, error) {
+	ms.mutex.RLock()
+	var data []byte
+	if gen == runtime.LastGen {
+		gens := ms.generations(key)
+		if len(gens) > 0 {
+			data = ms.objects[key][gens[len(gens)-1]]
+		}
+	} else {
+		data = ms.objects[key][gen]
+	}
+	ms.mutex.RUnlock()
+
+	if data == nil {
+		return nil, nil
+	}
+
+	obj, err := ms.decode(data)
+	if err != nil {
+		return nil, err
+	}
+	versioned, ok := obj.(runtime.Versioned)
+	if !ok {
+		return nil, fmt.Errorf("versioned object is expected to be decoded from memory, but got: %s", obj.GetKind())
+	}
+
+	return versioned, nil
+}
+
+func (ms *memoryStore) List(prefix string) ([]runtime.Storable, error) {
+	ms.mutex.RLock()
+	keys := make([]string, 0)
+	for key := range ms.objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	data := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		for _, gen := range ms.generations(key) {
+			data = append(data, ms.objects[key][gen])
+		}
+	}
+	ms.mutex.RUnlock()
+
+	return ms.decodeAll(data)
+}
+
+func (ms *memoryStore) ListGenerations(key string) ([]runtime.Storable, error) {
+	ms.mutex.RLock()
+	data := make([][]byte, 0)
+	for _, gen := range ms.generations(key) {
+		data = append(data, ms.objects[key][gen])
+	}
+	ms.mutex.RUnlock()
+
+	return ms.decodeAll(data)
+}
+
+func (ms *memoryStore) Save(obj runtime.Storable) (bool, error) {
+	return ms.save(obj, false)
+}
+
+func (ms *memoryStore) Update(obj runtime.Storable) (bool, error) {
+	return ms.save(obj, true)
+}
+
+func (ms *memoryStore) Put(obj runtime.Storable) error {
+	return ms.put(obj, store.GenerationOf(ms.registry, obj))
+}
+
+func (ms *memoryStore) save(obj runtime.Storable, updateCurrent bool) (bool, error) {
+	gen, updated, err := store.PrepareSave(ms.registry, ms.GetGen, obj, updateCurrent)
+	if err != nil {
+		return false, err
+	}
+
+	return updated, ms.put(obj, gen)
+}
+
+func (ms *memoryStore) put(obj runtime.Storable, gen runtime.Generation) error {
+	data, err := ms.codec.EncodeOne(obj)
+	if err != nil {
+		return err
+	}
+
+	key := runtime.KeyForStorable(obj)
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	if ms.objects[key] == nil {
+		ms.objects[key] = make(map[runtime.Generation][]byte)
+	}
+	ms.objects[key][gen] = data
+	ms.hub.Notify(store.Event{Type: store.EventPut, Key: key, Generation: gen})
+
+	return nil
+}
+
+func (ms *memoryStore) Delete(key string) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	for _, data := range ms.objects[key] {
+		obj, err := ms.codec.DecodeOne(data)
+		if err != nil {
+			return err
+		}
+		if _, ok := obj.(runtime.Versioned); ok {
+			return fmt.Errorf("deleting versioned objects isn't implmeneted")
+		}
+	}
+	delete(ms.objects, key)
+	ms.hub.Notify(store.Event{Type: store.EventDelete, Key: key, Generation: runtime.LastGen})
+
+	return nil
+}
+
+func (ms *memoryStore) DeleteGen(key string, gen runtime.Generation) error {
+	if gen == runtime.LastGen {
+		return fmt.Errorf("generation should be specified to delete versioned object: %s", key)
+	}
+
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	delete(ms.objects[key], gen)
+	if len(ms.objects[key]) == 0 {
+		delete(ms.objects, key)
+	}
+	ms.hub.Notify(store.Event{Type: store.EventDelete, Key: key, Generation: gen})
+
+	return nil
+}
+
+func (ms *memoryStore) Begin() (store.Transaction, error) {
+	return store.NewTransaction(ms.registry, ms.codec, ms, ms.apply), nil
+}
+
+func (ms *memoryStore) apply(ops []store.Op) error {
+	ms.mutex.Lock()
+	defer ms.mutex.Unlock()
+
+	for _, op := range ops {
+		if op.Delete {
+			delete(ms.objects, op.Key)
+			continue
+		}
+		if ms.objects[op.Key] == nil {
+			ms.objects[op.Key] = make(map[runtime.Generation][]byte)
+		}
+		ms.objects[op.Key][op.Generation] = op.Data
+	}
+	ms.hub.NotifyOps(ops)
+
+	return nil
+}
+
+func (ms *memoryStore) Watch(prefix string) (store.Watcher, error) {
+	return ms.hub.Watch(prefix), nil
+}
+
+func (ms *memoryStore) Codec() runtime.Codec {
+	return ms.codec
+}
+
+// generations returns sorted list of generations stored for the specified key, should be called under lock
+func (ms *memoryStore) generations(key string) []runtime.Generation {
+	result := make([]runtime.Generation, 0, len(ms.objects[key]))
+	for gen := range ms.objects[key] {
+		result = append(result, gen)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i] < result[j]
+	})
+	return result
+}
+
+func (ms *memoryStore) decode(data []byte) (runtime.Storable, error) {
+	obj, err := ms.codec.DecodeOne(data)
+	if err != nil {
+		return nil, err
+	}
+	storable, ok := obj.(runtime.Storable)
+	if !ok {
+		return nil, fmt.Errorf("storable object is expected to be decoded from memory, but got: %s", obj.GetKind())
+	}
+	return storable, nil
+}
+
+func (ms *memoryStore) decodeAll(data [][]byte) ([]runtime.Storable, error) {
+	result := make([]runtime.Storable, 0, len(data))
+	for _, objData := range data {
+		obj, err := ms.decode(objData)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, obj)
+	}
+	return result, nil
+}
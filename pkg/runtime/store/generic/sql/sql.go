@@ -0,0 +1,316 @@
+package sql
+
+import (
+	"database/sql"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"github.com/Aptomi/aptomi/pkg/util"
+)
+
+// DriverSQLite is a name of the SQLite driver
+const DriverSQLite = "sqlite3"
+
+// Schema is kept portable between SQLite and PostgreSQL, so it only uses basic types and statements. Every generation
+// of the object is stored as a separate row, non-versioned objects are always stored with generation 0 (LastGen).
+var schema = []string{
+	`CREATE TABLE IF NOT EXISTS objects (
+		key        VARCHAR(1024) NOT NULL,
+		generation BIGINT        NOT NULL,
+		data       TEXT          NOT NULL,
+		PRIMARY KEY (key, generation)
+	)`,
+}
+
+// NewGenericStore creates a new object store based on SQL database, driver is a name of the database/sql driver
+func NewGenericStore(registry *runtime.Registry, driver string) store.Generic {
+	return &sqlStore{registry: registry, driver: driver, hub: store.NewWatchHub()}
+}
+
+type sqlStore struct {
+	registry *runtime.Registry
+	codec    runtime.Codec
+	driver   string
+	db       *sql.DB
+	hub      *store.WatchHub
+}
+
+func (ss *sqlStore) Open(cfg config.DB) error {
+	codec, err := store.NewCodec(ss.registry, cfg)
+	if err != nil {
+		return err
+	}
+	ss.codec = codec
+
+	// SQLite driver requires cgo, so it's only registered if aptomi is built with cgo enabled
+	if !util.ContainsString(sql.Drivers(), ss.driver) {
+		return fmt.Errorf("SQL driver %s isn't available in this build of aptomi (SQLite requires aptomi to be built with cgo enabled)", ss.driver)
+	}
+
+	connection := cfg.Connection
+	db, err := sql.Open(ss.driver, connection)
+	if err != nil {
+		return fmt.Errorf("error while opening SQL DB (%s): %s error: %s", ss.driver, connection, err)
+	}
+	if ss.driver == DriverSQLite {
+		// SQLite doesn't support concurrent writers, so we're serializing all access through the single connection
+		db.SetMaxOpenConns(1)
+	}
+	ss.db = db
+
+	// Initialize all tables and indexes
+	for _, stmt := range schema {
+		_, err = ss.db.Exec(stmt)
+		if err != nil {
+			return fmt.Errorf("error while initializing SQL DB schema: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func (ss *sqlStore) Close() error {
+	ss.hub.Close()
+
+	err := ss.db.Close()
+	if err != nil {
+		return fmt.Errorf("error while closing SQL DB: %s", err)
+	}
+
+	return err
+}
+
+func (ss *sqlStore) Get(key string) (runtime.Storable, error) {
+	data, err := ss.getData(`SELECT data FROM objects WHERE key = ? AND generation = ?`, key, uint64(runtime.LastGen))
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	obj, err := ss.codec.DecodeOne(data)
+	if err != nil {
+		return nil, err
+	}
+	storable, ok := obj.(runtime.Storable)
+	if !ok {
+		return nil, fmt.Errorf("storable object is expected to be decoded from SQL DB, but got: %s", obj.GetKind())
+	}
+
+	return storable, nil
+}
+
+func (ss *sqlStore) GetGen(key string, gen runtime.Generation) (runtime.Versioned, error) {
+	var data []byte
+	var err error
+	if gen == runtime.LastGen {
+		data, err = ss.getData(`SELECT data FROM objects WHERE key = ? ORDER BY generation DESC LIMIT 1`, key)
+	} else {
+		data, err = ss.getData(`SELECT data FROM objects WHERE key = ? AND generation = ?`, key, uint64(gen))
+	}
+	if err != nil || data == nil {
+		return nil, err
+	}
+
+	obj, err := ss.codec.DecodeOne(data)
+	if err != nil {
+		return nil, err
+	}
+	versioned, ok := obj.(runtime.Versioned)
+	if !ok {
+		return nil, fmt.Errorf("versioned object is expected to be decoded from SQL DB, but got: %s", obj.GetKind())
+	}
+
+	return versioned, nil
+}
+
+func (ss *sqlStore) getData(query string, args ...interface{}) ([]byte, error) {
+	var data string
+	err := ss.db.QueryRow(ss.rebind(query), args...).Scan(&data)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error while querying SQL DB: %s", err)
+	}
+
+	return []byte(data), nil
+}
+
+func (ss *sqlStore) List(prefix string) ([]runtime.Storable, error) {
+	return ss.list(`SELECT data FROM objects WHERE substr(key, 1, ?) = ? ORDER BY key, generation`, len(prefix), prefix)
+}
+
+func (ss *sqlStore) ListGenerations(key string) ([]runtime.Storable, error) {
+	return ss.list(`SELECT data FROM objects WHERE key = ? ORDER BY generation`, key)
+}
+
+func (ss *sqlStore) list(query string, args ...interface{}) ([]runtime.Storable, error) {
+	rows, err := ss.db.Query(ss.rebind(query), args...)
+	if err != nil {
+		return nil, fmt.Errorf("error while querying SQL DB: %s", err)
+	}
+	defer rows.Close() // nolint: errcheck
+
+	result := make([]runtime.Storable, 0)
+	for rows.Next() {
+		var data string
+		err = rows.Scan(&data)
+		if err != nil {
+			return nil, fmt.Errorf("error while reading row from SQL DB: %s", err)
+		}
+
+		baseObj, err := ss.codec.DecodeOne([]byte(data))
+		if err != nil {
+			return nil, err
+		}
+		obj, ok := baseObj.(runtime.Storable)
+		if !ok {
+			return nil, fmt.Errorf("storable object is expected to be decoded from SQL DB, but got: %s", baseObj.GetKind())
+		}
+		result = append(result, obj)
+	}
+
+	return result, rows.Err()
+}
+
+func (ss *sqlStore) Save(obj runtime.Storable) (bool, error) {
+	return ss.save(obj, false)
+}
+
+func (ss *sqlStore) Update(obj runtime.Storable) (bool, error) {
+	return ss.save(obj, true)
+}
+
+func (ss *sqlStore) Put(obj runtime.Storable) error {
+	return ss.put(obj, store.GenerationOf(ss.registry, obj))
+}
+
+func (ss *sqlStore) save(obj runtime.Storable, updateCurrent bool) (bool, error) {
+	gen, updated, err := store.PrepareSave(ss.registry, ss.GetGen, obj, updateCurrent)
+	if err != nil {
+		return false, err
+	}
+
+	return updated, ss.put(obj, gen)
+}
+
+func (ss *sqlStore) put(obj runtime.Storable, gen runtime.Generation) error {
+	key := runtime.KeyForStorable(obj)
+	data, err := ss.codec.EncodeOne(obj)
+	if err != nil {
+		return err
+	}
+
+	_, err = ss.db.Exec(ss.rebind(
+		`INSERT INTO objects (key, generation, data) VALUES (?, ?, ?)
+		ON CONFLICT (key, generation) DO UPDATE SET data = excluded.data`,
+	), key, uint64(gen), string(data))
+	if err != nil {
+		return fmt.Errorf("error while saving object with key %s to SQL DB: %s", key, err)
+	}
+	ss.hub.Notify(store.Event{Type: store.EventPut, Key: key, Generation: gen})
+
+	return nil
+}
+
+func (ss *sqlStore) Delete(key string) error {
+	objs, err := ss.ListGenerations(key)
+	if err != nil {
+		return err
+	}
+	for _, obj := range objs {
+		if _, ok := obj.(runtime.Versioned); ok {
+			return fmt.Errorf("deleting versioned objects isn't implmeneted")
+		}
+	}
+
+	_, err = ss.db.Exec(ss.rebind(`DELETE FROM objects WHERE key = ?`), key)
+	if err != nil {
+		return fmt.Errorf("error while deleting object with key %s from SQL DB: %s", key, err)
+	}
+	ss.hub.Notify(store.Event{Type: store.EventDelete, Key: key, Generation: runtime.LastGen})
+
+	return nil
+}
+
+func (ss *sqlStore) DeleteGen(key string, gen runtime.Generation) error {
+	if gen == runtime.LastGen {
+		return fmt.Errorf("generation should be specified to delete versioned object: %s", key)
+	}
+
+	_, err := ss.db.Exec(ss.rebind(`DELETE FROM objects WHERE key = ? AND generation = ?`), key, uint64(gen))
+	if err != nil {
+		return fmt.Errorf("error while deleting object with key %s generation %s from SQL DB: %s", key, gen, err)
+	}
+	ss.hub.Notify(store.Event{Type: store.EventDelete, Key: key, Generation: gen})
+
+	return nil
+}
+
+func (ss *sqlStore) Begin() (store.Transaction, error) {
+	return store.NewTransaction(ss.registry, ss.codec, ss, ss.apply), nil
+}
+
+func (ss *sqlStore) apply(ops []store.Op) (err error) {
+	tx, err := ss.db.Begin()
+	if err != nil {
+		return fmt.Errorf("error while starting SQL transaction: %s", err)
+	}
+	defer func() {
+		if err != nil {
+			tx.Rollback() // nolint: errcheck
+		}
+	}()
+
+	for _, op := range ops {
+		if op.Delete {
+			_, err = tx.Exec(ss.rebind(`DELETE FROM objects WHERE key = ?`), op.Key)
+		} else {
+			_, err = tx.Exec(ss.rebind(
+				`INSERT INTO objects (key, generation, data) VALUES (?, ?, ?)
+				ON CONFLICT (key, generation) DO UPDATE SET data = excluded.data`,
+			), op.Key, uint64(op.Generation), string(op.Data))
+		}
+		if err != nil {
+			return fmt.Errorf("error while writing object with key %s to SQL DB: %s", op.Key, err)
+		}
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return fmt.Errorf("error while committing SQL transaction: %s", err)
+	}
+	ss.hub.NotifyOps(ops)
+
+	return nil
+}
+
+// Watch delivers only changes made through this store instance, changes made by other processes sharing the same DB
+// aren't observed
+func (ss *sqlStore) Watch(prefix string) (store.Watcher, error) {
+	return ss.hub.Watch(prefix), nil
+}
+
+func (ss *sqlStore) Codec() runtime.Codec {
+	return ss.codec
+}
+
+// rebind converts query placeholders into the format supported by the driver, all queries are written using "?"
+func (ss *sqlStore) rebind(query string) string {
+	if ss.driver != "postgres" {
+		return query
+	}
+
+	result := make([]byte, 0, len(query)+8)
+	idx := 0
+	for i := 0; i < len(query); i++ {
+		if query[i] == '?' {
+			idx++
+			result = append(result, fmt.Sprintf("$%d", idx)...)
+		} else {
+			result = append(result, query[i])
+		}
+	}
+
+	return string(result)
+}
@@ -0,0 +1,9 @@
+//go:build cgo
+// +build cgo
+
+package sql
+
+import (
+	// registers sqlite3 driver for database/sql, it's implemented on top of the SQLite C library and requires cgo
+	_ "github.com/mattn/go-sqlite3"
+)
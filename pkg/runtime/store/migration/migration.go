@@ -0,0 +1,268 @@
+package migration
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/backup"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/generic"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// BaseVersion is a schema version of the stores created before schema versioning was introduced
+const BaseVersion = 1
+
+// Migration converts stored objects to the new schema version. It operates on decoded objects, so fields removed
+// from the kind should be kept (and marked as deprecated) until migration reading them isn't needed anymore
+type Migration struct {
+	// Version is a schema version after migration is applied, versions of migrations should be increasing
+	Version int
+
+	// Kind is a kind of objects migration is applied to, migration is applied to objects of all kinds if it's empty
+	Kind runtime.Kind
+
+	// Description is a short description of the migration shown to the user
+	Description string
+
+	// Migrate converts object to the new schema in place and returns true if object was changed
+	Migrate func(obj runtime.Storable) (changed bool, err error)
+}
+
+// Registry represents ordered list of migrations
+type Registry struct {
+	migrations []*Migration
+}
+
+// NewRegistry returns registry with provided migrations, which should be ordered by their versions
+func NewRegistry(migrations ...*Migration) *Registry {
+	version := BaseVersion
+	for _, migration := range migrations {
+		if migration.Version <= version {
+			panic(fmt.Sprintf("Migration to schema version %d should have version greater than %d", migration.Version, version))
+		}
+		version = migration.Version
+	}
+
+	return &Registry{migrations: migrations}
+}
+
+// LatestVersion returns schema version after all migrations are applied
+func (registry *Registry) LatestVersion() int {
+	if len(registry.migrations) == 0 {
+		return BaseVersion
+	}
+	return registry.migrations[len(registry.migrations)-1].Version
+}
+
+// Pending returns migrations, which should be applied to the store with the specified schema version
+func (registry *Registry) Pending(version int) []*Migration {
+	result := make([]*Migration, 0)
+	for _, migration := range registry.migrations {
+		if migration.Version > version {
+			result = append(result, migration)
+		}
+	}
+	return result
+}
+
+// Result represents result of the store migration
+type Result struct {
+	// From is a schema version of the store before migration
+	From int
+
+	// To is a schema version of the store after migration
+	To int
+
+	// Applied is a list of migrations applied to the store
+	Applied []*Migration
+
+	// Changed is a number of objects (counting every generation separately) changed by every applied migration
+	Changed map[int]int
+
+	// Initialized is true if store didn't have schema marker before migration
+	Initialized bool
+}
+
+// IsNeeded returns true if any migrations should be applied or store schema marker should be written
+func (result *Result) IsNeeded() bool {
+	return len(result.Applied) > 0 || result.Initialized
+}
+
+// Migrate applies all pending migrations to the objects in the store (including all their generations) and updates
+// schema marker. All changes are written in a single transaction, so store is either migrated completely or not
+// changed at all. If dryRun is true, changes are calculated but not written. Empty store without schema marker is
+// considered new and only gets schema marker with the latest version
+func Migrate(s store.Generic, registry *Registry, dryRun bool) (*Result, error) {
+	from, initialized, err := CurrentVersion(s, registry)
+	if err != nil {
+		return nil, err
+	}
+	if from > registry.LatestVersion() {
+		return nil, fmt.Errorf("store schema version %d is newer than the latest supported version %d, newer version of Aptomi should be used", from, registry.LatestVersion())
+	}
+
+	result := &Result{
+		From:        from,
+		To:          registry.LatestVersion(),
+		Applied:     registry.Pending(from),
+		Changed:     make(map[int]int),
+		Initialized: initialized,
+	}
+	if !result.IsNeeded() {
+		return result, nil
+	}
+
+	tx, err := s.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback() // nolint: errcheck
+
+	if len(result.Applied) > 0 {
+		objs, listErr := s.List("")
+		if listErr != nil {
+			return nil, fmt.Errorf("error while listing objects: %s", listErr)
+		}
+
+		for _, obj := range objs {
+			if obj.GetKind() == store.SchemaObject.Kind {
+				continue
+			}
+
+			objChanged := false
+			for _, migration := range result.Applied {
+				if len(migration.Kind) > 0 && migration.Kind != obj.GetKind() {
+					continue
+				}
+
+				changed, migrateErr := migration.Migrate(obj)
+				if migrateErr != nil {
+					return nil, fmt.Errorf("error while migrating %s to schema version %d: %s", runtime.KeyForStorable(obj), migration.Version, migrateErr)
+				}
+				if changed {
+					result.Changed[migration.Version]++
+					objChanged = true
+				}
+			}
+
+			if objChanged {
+				err = tx.Put(obj)
+				if err != nil {
+					return nil, fmt.Errorf("error while saving migrated %s: %s", runtime.KeyForStorable(obj), err)
+				}
+			}
+		}
+	}
+
+	err = tx.Put(&store.Schema{
+		TypeKind:   store.SchemaObject.GetTypeKind(),
+		Version:    result.To,
+		MigratedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error while saving schema version: %s", err)
+	}
+
+	if dryRun {
+		return result, nil
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, fmt.Errorf("error while committing migration: %s", err)
+	}
+
+	return result, nil
+}
+
+// CurrentVersion returns schema version of the store. If store doesn't have schema marker, it returns latest version
+// for the empty store and base version for the store created before schema versioning was introduced
+func CurrentVersion(s store.Generic, registry *Registry) (version int, initialized bool, err error) {
+	obj, err := s.Get(store.SchemaKey)
+	if err != nil {
+		return 0, false, fmt.Errorf("error while getting schema version: %s", err)
+	}
+	if obj != nil {
+		schema, ok := obj.(*store.Schema)
+		if !ok {
+			return 0, false, fmt.Errorf("unexpected type while getting schema version from DB")
+		}
+		return schema.Version, false, nil
+	}
+
+	objs, err := s.List("")
+	if err != nil {
+		return 0, false, fmt.Errorf("error while listing objects: %s", err)
+	}
+	if len(objs) == 0 {
+		return registry.LatestVersion(), true, nil
+	}
+
+	return BaseVersion, true, nil
+}
+
+// MigrateWithBackup applies all pending migrations to the store same as Migrate, but before that it writes backup of
+// all objects from the store into the directory. Backup isn't made if there are no migrations to apply. Path to the
+// backup file is returned if it was made. Migration isn't applied if backup can't be made (e.g. directory is empty)
+func MigrateWithBackup(s store.Generic, registry *Registry, dir string) (*Result, string, error) {
+	from, _, err := CurrentVersion(s, registry)
+	if err != nil {
+		return nil, "", err
+	}
+
+	path := ""
+	if from < registry.LatestVersion() {
+		if len(dir) == 0 {
+			return nil, "", fmt.Errorf("backup directory (db.backupdir) should be configured to migrate DB from schema version %d to %d", from, registry.LatestVersion())
+		}
+		path, err = Backup(s, dir, from, registry.LatestVersion())
+		if err != nil {
+			return nil, "", err
+		}
+	}
+
+	result, err := Migrate(s, registry, false)
+	return result, path, err
+}
+
+// Backup writes backup of all objects from the store into the directory before migrating it from one schema version to
+// another and returns path to the backup file
+func Backup(s store.Generic, dir string, from int, to int) (string, error) {
+	path := filepath.Join(dir, fmt.Sprintf("aptomi-pre-migration-v%d-v%d-%s.tar.gz", from, to, time.Now().Format("20060102-150405")))
+	out, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+	if err != nil {
+		return "", fmt.Errorf("error while creating backup file '%s': %s", path, err)
+	}
+
+	// backup should be on disk before migration starts, so incomplete backup file is removed and migration is aborted
+	_, err = backup.Write(out, s)
+	if err == nil {
+		err = out.Sync()
+	}
+	errClose := out.Close()
+	if err == nil {
+		err = errClose
+	}
+	if err != nil {
+		os.Remove(path) // nolint: errcheck
+		return "", fmt.Errorf("error while making backup '%s': %s", path, err)
+	}
+
+	return path, nil
+}
+
+// BackupDir returns directory for backups made before migrations. If it isn't configured, directory of the DB file is
+// used, while empty string is returned for DB connections which aren't file paths (e.g. in-memory store or SQL DSN)
+func BackupDir(cfg config.DB) string {
+	if len(cfg.BackupDir) > 0 {
+		return cfg.BackupDir
+	}
+	if cfg.Type == generic.TypeMemory || strings.Contains(cfg.Connection, "://") || strings.HasPrefix(cfg.Connection, "file:") || strings.Contains(cfg.Connection, "?") {
+		return ""
+	}
+	return filepath.Dir(cfg.Connection)
+}
@@ -0,0 +1,191 @@
+package migration
+
+import (
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/generic/memory"
+	"github.com/stretchr/testify/assert"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newMemoryStore(t *testing.T) store.Generic {
+	t.Helper()
+	s := memory.NewGenericStore(runtime.NewRegistry().Append(store.Objects...))
+	if err := s.Open(config.DB{Connection: "memory://"}); err != nil {
+		t.Fatalf("can't open in-memory store: %s", err)
+	}
+	return s
+}
+
+func makeService(labels map[string]string) *lang.Service {
+	return &lang.Service{
+		TypeKind:   lang.ServiceObject.GetTypeKind(),
+		Metadata:   lang.Metadata{Namespace: "main", Name: "svc"},
+		Labels:     labels,
+		Components: []*lang.ServiceComponent{},
+	}
+}
+
+func labelMigration(version int) *Migration {
+	return &Migration{
+		Version:     version,
+		Kind:        lang.ServiceObject.Kind,
+		Description: "add migrated label",
+		Migrate: func(obj runtime.Storable) (bool, error) {
+			service := obj.(*lang.Service)
+			if service.Labels == nil {
+				service.Labels = make(map[string]string)
+			}
+			service.Labels["migrated"] = "true"
+			return true, nil
+		},
+	}
+}
+
+func TestMigrateNewStore(t *testing.T) {
+	s := newMemoryStore(t)
+	registry := NewRegistry(labelMigration(2))
+
+	result, err := Migrate(s, registry, false)
+	if !assert.NoError(t, err, "Empty store should be migrated") {
+		return
+	}
+	assert.True(t, result.Initialized, "Schema marker should be initialized")
+	assert.Empty(t, result.Applied, "Migrations shouldn't be applied to the empty store")
+
+	version, initialized, err := CurrentVersion(s, registry)
+	assert.NoError(t, err, "Schema version should be read")
+	assert.False(t, initialized, "Schema marker should exist")
+	assert.Equal(t, 2, version, "Empty store should get the latest schema version")
+}
+
+func TestMigrateExistingStore(t *testing.T) {
+	s := newMemoryStore(t)
+	for _, labels := range []map[string]string{{"gen": "1"}, {"gen": "2"}} {
+		if _, err := s.Save(makeService(labels)); !assert.NoError(t, err, "Service should be saved") {
+			return
+		}
+	}
+	registry := NewRegistry(labelMigration(2))
+
+	// dry run shouldn't change anything
+	result, err := Migrate(s, registry, true)
+	if !assert.NoError(t, err, "Dry run should succeed") {
+		return
+	}
+	assert.Equal(t, BaseVersion, result.From, "Store without schema marker should have base version")
+	assert.Len(t, result.Applied, 1, "Pending migration should be reported")
+	assert.Equal(t, 2, result.Changed[2], "Both generations should be reported as changed")
+	version, initialized, _ := CurrentVersion(s, registry)
+	assert.Equal(t, BaseVersion, version, "Dry run shouldn't write schema marker")
+	assert.True(t, initialized, "Dry run shouldn't write schema marker")
+
+	result, err = Migrate(s, registry, false)
+	if !assert.NoError(t, err, "Store should be migrated") {
+		return
+	}
+	assert.Equal(t, 2, result.To, "Store should be migrated to the latest version")
+
+	generations, err := s.ListGenerations(runtime.KeyForStorable(makeService(nil)))
+	if assert.NoError(t, err, "Generations should be listed") {
+		assert.Len(t, generations, 2, "Migration shouldn't create new generations")
+		for _, obj := range generations {
+			assert.Equal(t, "true", obj.(*lang.Service).Labels["migrated"], "All generations should be migrated")
+		}
+	}
+
+	// migrations shouldn't be applied again
+	result, err = Migrate(s, registry, false)
+	assert.NoError(t, err, "Migrated store should be checked")
+	assert.False(t, result.IsNeeded(), "Migrated store shouldn't need migrations")
+}
+
+func TestMigrateNewerStore(t *testing.T) {
+	s := newMemoryStore(t)
+	_, err := Migrate(s, NewRegistry(labelMigration(2), labelMigration(3)), false)
+	if !assert.NoError(t, err, "Empty store should be migrated") {
+		return
+	}
+
+	_, err = Migrate(s, NewRegistry(labelMigration(2)), false)
+	assert.Error(t, err, "Store with newer schema version shouldn't be migrated")
+}
+
+func TestRegistryOrder(t *testing.T) {
+	assert.Panics(t, func() { NewRegistry(labelMigration(3), labelMigration(2)) }, "Unordered migrations should panic")
+	assert.Panics(t, func() { NewRegistry(labelMigration(BaseVersion)) }, "Migration to base version should panic")
+}
+
+func TestMigrateWithBackup(t *testing.T) {
+	dir, err := ioutil.TempDir("", "aptomi-migration-test")
+	if !assert.NoError(t, err, "Temp dir should be created") {
+		return
+	}
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	s := newMemoryStore(t)
+	if _, err = s.Save(makeService(nil)); !assert.NoError(t, err, "Service should be saved") {
+		return
+	}
+
+	_, path, err := MigrateWithBackup(s, NewRegistry(labelMigration(2)), dir)
+	if !assert.NoError(t, err, "Store should be migrated") {
+		return
+	}
+	assert.Equal(t, dir, filepath.Dir(path), "Backup should be written to the specified dir")
+	_, err = os.Stat(path)
+	assert.NoError(t, err, "Backup file should exist")
+
+	_, path, err = MigrateWithBackup(s, NewRegistry(labelMigration(2)), dir)
+	assert.NoError(t, err, "Migrated store should be checked")
+	assert.Empty(t, path, "Backup shouldn't be made if there are no migrations")
+}
+
+func TestMigrateWithoutBackupDir(t *testing.T) {
+	s := newMemoryStore(t)
+	if _, err := s.Save(makeService(nil)); !assert.NoError(t, err, "Service should be saved") {
+		return
+	}
+
+	_, _, err := MigrateWithBackup(s, NewRegistry(labelMigration(2)), "")
+	assert.Error(t, err, "Store shouldn't be migrated without backup")
+	version, _, err := CurrentVersion(s, NewRegistry(labelMigration(2)))
+	assert.NoError(t, err, "Schema version should be loaded")
+	assert.Equal(t, BaseVersion, version, "Store shouldn't be migrated without backup")
+}
+
+func TestMigrateBackupFailure(t *testing.T) {
+	s := newMemoryStore(t)
+	if _, err := s.Save(makeService(nil)); !assert.NoError(t, err, "Service should be saved") {
+		return
+	}
+
+	_, _, err := MigrateWithBackup(s, NewRegistry(labelMigration(2)), filepath.Join(os.TempDir(), "aptomi-missing-dir", "backups"))
+	assert.Error(t, err, "Store shouldn't be migrated if backup fails")
+	version, _, err := CurrentVersion(s, NewRegistry(labelMigration(2)))
+	assert.NoError(t, err, "Schema version should be loaded")
+	assert.Equal(t, BaseVersion, version, "Store shouldn't be migrated if backup fails")
+}
+
+func TestBackupDir(t *testing.T) {
+	testCases := []struct {
+		cfg      config.DB
+		expected string
+	}{
+		{config.DB{Connection: "/var/lib/aptomi/db.bolt"}, "/var/lib/aptomi"},
+		{config.DB{Connection: "/var/lib/aptomi/db.bolt", BackupDir: "/backups"}, "/backups"},
+		{config.DB{Type: "sqlite3", Connection: "/var/lib/aptomi/db.sqlite"}, "/var/lib/aptomi"},
+		{config.DB{Type: "sqlite3", Connection: "file:/var/lib/aptomi/db.sqlite?cache=shared"}, ""},
+		{config.DB{Type: "sqlite3", Connection: "file:/var/lib/aptomi/db.sqlite?cache=shared", BackupDir: "/backups"}, "/backups"},
+		{config.DB{Connection: "memory://"}, ""},
+		{config.DB{Type: "memory", Connection: "test"}, ""},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, BackupDir(tc.cfg), "Backup dir for connection '%s' should be correct", tc.cfg.Connection)
+	}
+}
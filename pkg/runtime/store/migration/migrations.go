@@ -0,0 +1,6 @@
+package migration
+
+// Migrations is the registry of all migrations of stored objects. When fields of stored kinds (e.g. lang.Service,
+// resolve.ComponentInstance or engine.Revision) are changed in a way that affects existing data, migration with the
+// next schema version should be added to the end of the list
+var Migrations = NewRegistry()
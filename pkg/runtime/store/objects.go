@@ -1,6 +1,7 @@
 package store
 
 import (
+	"github.com/Aptomi/aptomi/pkg/auth"
 	"github.com/Aptomi/aptomi/pkg/engine"
 	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
@@ -8,5 +9,5 @@ import (
 
 var (
 	// Objects represents list of all storable objects
-	Objects = runtime.AppendAll(engine.Objects, lang.PolicyObjects)
+	Objects = runtime.AppendAll(engine.Objects, lang.PolicyObjects, auth.Objects, []*runtime.Info{SchemaObject})
 )
@@ -0,0 +1,38 @@
+package store
+
+import (
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"time"
+)
+
+// SchemaObject is an informational data structure with Kind and Constructor for Schema
+var SchemaObject = &runtime.Info{
+	Kind:        "schema",
+	Storable:    true,
+	Constructor: func() runtime.Object { return &Schema{} },
+}
+
+// SchemaKey is the key of the schema marker (there is only one schema marker in the store)
+var SchemaKey = runtime.KeyFromParts(runtime.SystemNS, SchemaObject.Kind, runtime.EmptyName)
+
+// Schema is a marker of the schema version of all objects in the store. It's updated by migrations, which convert
+// stored objects to the new schema, when fields of stored object kinds are changed
+type Schema struct {
+	runtime.TypeKind `yaml:",inline"`
+
+	// Version is a schema version of the stored objects
+	Version int
+
+	// MigratedAt is a time when the last migration was applied
+	MigratedAt time.Time
+}
+
+// GetName returns Schema name
+func (schema *Schema) GetName() string {
+	return runtime.EmptyName
+}
+
+// GetNamespace returns Schema namespace
+func (schema *Schema) GetNamespace() string {
+	return runtime.SystemNS
+}
@@ -0,0 +1,234 @@
+package store
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+)
+
+// Transaction represents a set of changes, which are applied to the store atomically on Commit. Changes aren't visible
+// to others until transaction is committed, but they are visible to reads made through the transaction itself
+type Transaction interface {
+	Get(key string) (runtime.Storable, error)
+	GetGen(key string, gen runtime.Generation) (runtime.Versioned, error)
+
+	Save(runtime.Storable) (updated bool, err error)
+	Update(runtime.Storable) (updated bool, err error)
+	// Put stores object as is under its own generation, it's used to rewrite objects without creating new generations
+	Put(runtime.Storable) error
+	Delete(key string) error
+
+	// Commit applies all changes made in transaction to the store atomically
+	Commit() error
+	// Rollback discards all changes made in transaction, it does nothing if transaction is already committed
+	Rollback() error
+}
+
+// Op represents a single change made in transaction, which should be applied to the store on commit
+type Op struct {
+	Key        string
+	Generation runtime.Generation
+	Data       []byte
+
+	// Delete means that all generations of the object with Key should be deleted
+	Delete bool
+}
+
+// ApplyFunc applies all changes from the transaction to the store atomically, so either all of them or none are applied
+type ApplyFunc func(ops []Op) error
+
+// NewTransaction returns transaction, which buffers all changes in memory and then applies them atomically to the store
+// using the provided function. It's used by generic store implementations to implement Begin
+func NewTransaction(registry *runtime.Registry, codec runtime.Codec, store Generic, apply ApplyFunc) Transaction {
+	return &transaction{
+		registry: registry,
+		codec:    codec,
+		store:    store,
+		apply:    apply,
+		written:  make(map[string]map[runtime.Generation][]byte),
+		deleted:  make(map[string]bool),
+	}
+}
+
+type transaction struct {
+	registry *runtime.Registry
+	codec    runtime.Codec
+	store    Generic
+	apply    ApplyFunc
+
+	ops      []Op
+	written  map[string]map[runtime.Generation][]byte
+	deleted  map[string]bool
+	finished bool
+}
+
+func (tx *transaction) Get(key string) (runtime.Storable, error) {
+	if err := tx.checkNotFinished(); err != nil {
+		return nil, err
+	}
+
+	if data, ok := tx.written[key][runtime.LastGen]; ok {
+		return tx.decode(data)
+	}
+	if tx.deleted[key] {
+		return nil, nil
+	}
+
+	return tx.store.Get(key)
+}
+
+func (tx *transaction) GetGen(key string, gen runtime.Generation) (runtime.Versioned, error) {
+	if err := tx.checkNotFinished(); err != nil {
+		return nil, err
+	}
+
+	var data []byte
+	if gen == runtime.LastGen {
+		var lastGen runtime.Generation
+		for writtenGen, writtenData := range tx.written[key] {
+			if writtenGen >= lastGen {
+				lastGen = writtenGen
+				data = writtenData
+			}
+		}
+
+		// generation written in transaction wins, unless there is a newer one in the store
+		stored, err := tx.store.GetGen(key, runtime.LastGen)
+		if err != nil {
+			return nil, err
+		}
+		if data == nil || stored != nil && stored.GetGeneration() > lastGen {
+			return stored, nil
+		}
+	} else {
+		var ok bool
+		if data, ok = tx.written[key][gen]; !ok {
+			return tx.store.GetGen(key, gen)
+		}
+	}
+
+	if data == nil {
+		return nil, nil
+	}
+	obj, err := tx.decode(data)
+	if err != nil {
+		return nil, err
+	}
+	versioned, ok := obj.(runtime.Versioned)
+	if !ok {
+		return nil, fmt.Errorf("versioned object is expected to be decoded from transaction, but got: %s", obj.GetKind())
+	}
+
+	return versioned, nil
+}
+
+func (tx *transaction) Save(obj runtime.Storable) (bool, error) {
+	return tx.save(obj, false)
+}
+
+func (tx *transaction) Update(obj runtime.Storable) (bool, error) {
+	return tx.save(obj, true)
+}
+
+func (tx *transaction) save(obj runtime.Storable, updateCurrent bool) (bool, error) {
+	if err := tx.checkNotFinished(); err != nil {
+		return false, err
+	}
+
+	gen, updated, err := PrepareSave(tx.registry, tx.GetGen, obj, updateCurrent)
+	if err != nil {
+		return false, err
+	}
+
+	return updated, tx.put(obj, gen)
+}
+
+func (tx *transaction) Put(obj runtime.Storable) error {
+	if err := tx.checkNotFinished(); err != nil {
+		return err
+	}
+
+	return tx.put(obj, GenerationOf(tx.registry, obj))
+}
+
+func (tx *transaction) put(obj runtime.Storable, gen runtime.Generation) error {
+	data, err := tx.codec.EncodeOne(obj)
+	if err != nil {
+		return err
+	}
+
+	key := runtime.KeyForStorable(obj)
+	if tx.written[key] == nil {
+		tx.written[key] = make(map[runtime.Generation][]byte)
+	}
+	tx.written[key][gen] = data
+	tx.ops = append(tx.ops, Op{Key: key, Generation: gen, Data: data})
+
+	return nil
+}
+
+func (tx *transaction) Delete(key string) error {
+	if err := tx.checkNotFinished(); err != nil {
+		return err
+	}
+
+	objs, err := tx.store.ListGenerations(key)
+	if err != nil {
+		return err
+	}
+	for _, data := range tx.written[key] {
+		obj, decodeErr := tx.decode(data)
+		if decodeErr != nil {
+			return decodeErr
+		}
+		objs = append(objs, obj)
+	}
+	for _, obj := range objs {
+		if info := tx.registry.Get(obj.GetKind()); info.Versioned {
+			return fmt.Errorf("deleting versioned objects isn't implmeneted")
+		}
+	}
+
+	delete(tx.written, key)
+	tx.deleted[key] = true
+	tx.ops = append(tx.ops, Op{Key: key, Delete: true})
+
+	return nil
+}
+
+func (tx *transaction) Commit() error {
+	if err := tx.checkNotFinished(); err != nil {
+		return err
+	}
+	tx.finished = true
+
+	if len(tx.ops) == 0 {
+		return nil
+	}
+
+	return tx.apply(tx.ops)
+}
+
+func (tx *transaction) Rollback() error {
+	tx.finished = true
+	tx.ops = nil
+	return nil
+}
+
+func (tx *transaction) checkNotFinished() error {
+	if tx.finished {
+		return fmt.Errorf("transaction is already finished")
+	}
+	return nil
+}
+
+func (tx *transaction) decode(data []byte) (runtime.Storable, error) {
+	obj, err := tx.codec.DecodeOne(data)
+	if err != nil {
+		return nil, err
+	}
+	storable, ok := obj.(runtime.Storable)
+	if !ok {
+		return nil, fmt.Errorf("storable object is expected to be decoded from transaction, but got: %s", obj.GetKind())
+	}
+	return storable, nil
+}
@@ -0,0 +1,101 @@
+package store
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/codec/yaml"
+)
+
+// GenerationGetter is a function that returns specified generation of the object with provided key
+type GenerationGetter func(key string, gen runtime.Generation) (runtime.Versioned, error)
+
+// PrepareSave implements generation semantics shared by all generic store implementations. It returns generation under
+// which the object should be written (LastGen for non-versioned objects) and whether a new object or a new generation
+// is being created. For versioned objects, generation is set on the object itself. If updateCurrent is true, current
+// generation is overwritten instead of creating a new one. Objects are compared in plain YAML, as codec used for
+// storing them could be non-deterministic (e.g. when they are encrypted)
+func PrepareSave(registry *runtime.Registry, getGen GenerationGetter, obj runtime.Storable, updateCurrent bool) (runtime.Generation, bool, error) {
+	info := registry.Get(obj.GetKind())
+	if info == nil {
+		return runtime.LastGen, false, fmt.Errorf("unknown kind: %s", obj.GetKind())
+	}
+	if !info.Versioned {
+		return runtime.LastGen, false, nil
+	}
+
+	key := runtime.KeyForStorable(obj)
+	versionedObj, ok := obj.(runtime.Versioned)
+	if !ok {
+		return runtime.LastGen, false, fmt.Errorf("versioned object doesn't implement Versioned interface: %s", obj.GetKind())
+	}
+
+	// todo we should compare with latest in some cases
+	existingObj, err := getGen(key, versionedObj.GetGeneration())
+	if err != nil {
+		return runtime.LastGen, false, err
+	}
+
+	deletable, ok := obj.(runtime.Deletable)
+	if ok && deletable.IsDeleted() {
+		if !info.Deletable {
+			return runtime.LastGen, false, fmt.Errorf("trying mark object deleted=true that isn't explicitly marked as deletable: %s %s", info.Kind, key)
+		}
+
+		if existingObj == nil {
+			return runtime.LastGen, false, fmt.Errorf("trying to make non-existing in db object with deleted=true: %s %s", info.Kind, key)
+		}
+	}
+
+	updated := false
+	if existingObj != nil {
+		versionedObj.SetGeneration(existingObj.GetGeneration())
+		equals, equalsErr := equals(yaml.NewCodec(registry), obj, existingObj)
+		if equalsErr != nil {
+			return runtime.LastGen, false, equalsErr
+		}
+		if !updateCurrent && !equals {
+			// todo replace this code by checking index that returns last generation
+			last, lastErr := getGen(key, runtime.LastGen)
+			if lastErr != nil {
+				return runtime.LastGen, false, fmt.Errorf("error while getting last generation of %s: %s", key, lastErr)
+			}
+			var newGen runtime.Generation = 1
+			if last != nil {
+				newGen = last.GetGeneration().Next()
+			}
+			versionedObj.SetGeneration(newGen)
+			updated = true
+		}
+	} else {
+		if versionedObj.GetGeneration() == runtime.LastGen {
+			versionedObj.SetGeneration(runtime.FirstGen)
+		}
+		updated = true
+	}
+
+	return versionedObj.GetGeneration(), updated, nil
+}
+
+func equals(codec runtime.Codec, o1 runtime.Object, o2 runtime.Object) (bool, error) {
+	o1bytes, err := codec.EncodeOne(o1)
+	if err != nil {
+		return false, err
+	}
+
+	o2bytes, err := codec.EncodeOne(o2)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(o1bytes, o2bytes), nil
+}
+
+// GenerationOf returns generation under which the object is stored, it's LastGen for non-versioned objects
+func GenerationOf(registry *runtime.Registry, obj runtime.Storable) runtime.Generation {
+	info := registry.Get(obj.GetKind())
+	if versioned, ok := obj.(runtime.Versioned); ok && info != nil && info.Versioned {
+		return versioned.GetGeneration()
+	}
+	return runtime.LastGen
+}
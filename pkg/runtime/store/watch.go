@@ -0,0 +1,135 @@
+package store
+
+import (
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"strings"
+	"sync"
+)
+
+// EventType represents type of the change made in the store
+type EventType string
+
+const (
+	// EventPut means that object (or its generation) was created or updated
+	EventPut EventType = "put"
+
+	// EventDelete means that object (or its generation) was deleted
+	EventDelete EventType = "delete"
+)
+
+// watchBufferSize is a number of events buffered for every watcher before it's considered too slow and gets stopped
+const watchBufferSize = 256
+
+// Event represents a single change made in the store. Generation is LastGen for non-versioned objects, as well as for
+// delete events which affect all generations of the object
+type Event struct {
+	Type       EventType
+	Key        string
+	Generation runtime.Generation
+}
+
+// Watcher delivers events about changes made in the store to objects with keys matching specified prefix
+type Watcher interface {
+	// Events returns channel with events. Channel is closed when watcher is stopped, including the case when watcher
+	// doesn't keep up with changes and its buffer overflows, so events were lost and the state should be re-read
+	Events() <-chan Event
+
+	// Stop stops watcher and closes its channel
+	Stop()
+}
+
+// WatchHub implements in-process watch shared by all generic store implementations. Stores notify it about changes
+// after they are successfully written, so watchers see only the changes made through the same store instance
+type WatchHub struct {
+	mutex    sync.Mutex
+	watchers map[*watcher]bool
+}
+
+// NewWatchHub returns new WatchHub without any watchers
+func NewWatchHub() *WatchHub {
+	return &WatchHub{watchers: make(map[*watcher]bool)}
+}
+
+// Watch returns new watcher for objects with keys matching provided prefix (empty prefix matches all objects)
+func (hub *WatchHub) Watch(prefix string) Watcher {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	w := &watcher{hub: hub, prefix: prefix, events: make(chan Event, watchBufferSize)}
+	hub.watchers[w] = true
+
+	return w
+}
+
+// Notify delivers events to all watchers with matching prefix. It never blocks, watchers which can't accept more
+// events are stopped
+func (hub *WatchHub) Notify(events ...Event) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	for w := range hub.watchers {
+		for _, event := range events {
+			if !strings.HasPrefix(event.Key, w.prefix) {
+				continue
+			}
+
+			select {
+			case w.events <- event:
+			default:
+				hub.stop(w)
+			}
+
+			if !hub.watchers[w] {
+				break
+			}
+		}
+	}
+}
+
+// NotifyOps delivers events for the changes made by committed transaction
+func (hub *WatchHub) NotifyOps(ops []Op) {
+	events := make([]Event, 0, len(ops))
+	for _, op := range ops {
+		if op.Delete {
+			events = append(events, Event{Type: EventDelete, Key: op.Key, Generation: runtime.LastGen})
+		} else {
+			events = append(events, Event{Type: EventPut, Key: op.Key, Generation: op.Generation})
+		}
+	}
+	hub.Notify(events...)
+}
+
+// Close stops all watchers, it's called when store is closed
+func (hub *WatchHub) Close() {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	for w := range hub.watchers {
+		hub.stop(w)
+	}
+}
+
+// stop removes watcher and closes its channel, hub mutex should be held by caller
+func (hub *WatchHub) stop(w *watcher) {
+	if hub.watchers[w] {
+		delete(hub.watchers, w)
+		close(w.events)
+	}
+}
+
+type watcher struct {
+	hub    *WatchHub
+	prefix string
+	events chan Event
+}
+
+func (w *watcher) Events() <-chan Event {
+	return w.events
+}
+
+func (w *watcher) Stop() {
+	w.hub.mutex.Lock()
+	defer w.hub.mutex.Unlock()
+
+	w.hub.stop(w)
+}
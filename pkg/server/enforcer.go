@@ -7,22 +7,65 @@ import (
 	"github.com/Aptomi/aptomi/pkg/engine/diff"
 	"github.com/Aptomi/aptomi/pkg/engine/resolve"
 	"github.com/Aptomi/aptomi/pkg/event"
+	"github.com/Aptomi/aptomi/pkg/external/users"
+	"github.com/Aptomi/aptomi/pkg/lang"
 	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
 	log "github.com/Sirupsen/logrus"
 	"time"
 )
 
+// SystemUserName is the name used as the author of expired dependencies deletion performed by Aptomi itself. It has
+// the reserved prefix, so it can't be confused with a user from the directory
+const SystemUserName = users.ReservedNamePrefix + "expiration"
+
 func logError(err interface{}) {
 	log.Errorf("Error while enforcing policy: %s", err)
 }
 
 func (server *Server) enforceLoop() error {
+	var watcher store.Watcher
 	for {
 		err := server.enforce()
 		if err != nil {
 			logError(err)
 		}
-		time.Sleep(server.cfg.Enforcer.Interval)
+
+		// policy watcher gets closed if it doesn't keep up with changes, so it's re-created in such case
+		if watcher == nil {
+			watcher, err = server.store.WatchPolicy()
+			if err != nil {
+				return fmt.Errorf("error while watching policy changes: %s", err)
+			}
+		}
+
+		// run enforcement as soon as policy is changed or enforcement is triggered through the API, but not less
+		// frequently than the configured interval
+		select {
+		case _, ok := <-watcher.Events():
+			if !ok {
+				watcher = nil
+				continue
+			}
+			drainEvents(watcher)
+		case <-server.enforcementTrigger:
+		case <-time.After(server.cfg.Enforcer.Interval):
+		}
+	}
+}
+
+// drainEvents skips all events already received by the watcher, so multiple policy changes made while enforcement
+// was running trigger only a single enforcement
+func drainEvents(watcher store.Watcher) {
+	for {
+		select {
+		case _, ok := <-watcher.Events():
+			if !ok {
+				return
+			}
+		default:
+			return
+		}
 	}
 }
 
@@ -62,6 +105,18 @@ func (server *Server) enforce() error {
 		return fmt.Errorf("desiredPolicy is nil, does not exist in the store")
 	}
 
+	// delete expired dependencies from the policy and reload it, if any of them got deleted
+	expired, err := server.deleteExpiredDependencies(desiredPolicy)
+	if err != nil {
+		return fmt.Errorf("error while deleting expired dependencies: %s", err)
+	}
+	if expired {
+		desiredPolicy, desiredPolicyGen, err = server.store.GetPolicy(runtime.LastGen)
+		if err != nil {
+			return fmt.Errorf("error while getting desiredPolicy: %s", err)
+		}
+	}
+
 	actualState, err := server.store.GetActualState()
 	if err != nil {
 		return fmt.Errorf("error while getting actual state: %s", err)
@@ -122,6 +177,26 @@ func (server *Server) enforce() error {
 	return nil
 }
 
+// deleteExpiredDependencies deletes all dependencies, which have their expiration time in the past, from the policy
+func (server *Server) deleteExpiredDependencies(policy *lang.Policy) (bool, error) {
+	now := time.Now()
+	expired := []lang.Base{}
+	for _, obj := range policy.GetObjectsByKind(lang.DependencyObject.Kind) {
+		dependency := obj.(*lang.Dependency)
+		if dependency.IsExpired(now) {
+			log.Infof("(enforce-%d) Dependency %s expired at %s, deleting it", server.enforcementIdx, runtime.KeyForStorable(dependency), dependency.ExpiresAt)
+			expired = append(expired, dependency)
+		}
+	}
+
+	if len(expired) <= 0 {
+		return false, nil
+	}
+
+	changed, _, err := server.store.DeleteFromPolicy(expired, SystemUserName)
+	return changed, err
+}
+
 func (server *Server) saveErrRevision(currRevision *engine.Revision, desiredPolicyGen runtime.Generation) {
 	if currRevision == nil || currRevision.Policy != desiredPolicyGen || currRevision.Status != engine.RevisionStatusError {
 		rev, revErr := server.store.NewRevision(desiredPolicyGen)
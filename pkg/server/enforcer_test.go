@@ -0,0 +1,57 @@
+package server
+
+import (
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/stretchr/testify/assert"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestDeleteExpiredDependencies(t *testing.T) {
+	server := &Server{store: newMemoryStore(t)}
+	newDependency := func(name string, expiresAt time.Time) *lang.Dependency {
+		return &lang.Dependency{
+			TypeKind:  lang.DependencyObject.GetTypeKind(),
+			Metadata:  lang.Metadata{Namespace: "main", Name: name},
+			User:      "alice",
+			Contract:  "twitter-stats",
+			ExpiresAt: expiresAt,
+		}
+	}
+	_, _, err := server.store.UpdatePolicy([]lang.Base{
+		newDependency("expired", time.Now().Add(-time.Minute)),
+		newDependency("active", time.Now().Add(time.Hour)),
+		newDependency("permanent", time.Time{}),
+	}, "test")
+	if !assert.NoError(t, err, "Policy should be updated") {
+		return
+	}
+
+	policy, gen, err := server.store.GetPolicy(runtime.LastGen)
+	if !assert.NoError(t, err, "Policy should be loaded") {
+		return
+	}
+	changed, err := server.deleteExpiredDependencies(policy)
+	if !assert.NoError(t, err, "Expired dependencies should be deleted") || !assert.True(t, changed, "Policy should be changed") {
+		return
+	}
+
+	policy, newGen, err := server.store.GetPolicy(runtime.LastGen)
+	if !assert.NoError(t, err, "Policy should be loaded") {
+		return
+	}
+	assert.Equal(t, gen.Next(), newGen, "New policy generation should be created")
+	names := []string{}
+	for _, obj := range policy.GetObjectsByKind(lang.DependencyObject.Kind) {
+		names = append(names, obj.GetName())
+	}
+	sort.Strings(names)
+	assert.Equal(t, []string{"active", "permanent"}, names, "Only expired dependency should be deleted")
+
+	// nothing is changed when there are no expired dependencies
+	changed, err = server.deleteExpiredDependencies(policy)
+	assert.NoError(t, err, "No dependencies should be deleted")
+	assert.False(t, changed, "Policy shouldn't be changed")
+}
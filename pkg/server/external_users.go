@@ -0,0 +1,54 @@
+package server
+
+import (
+	"fmt"
+	"github.com/Aptomi/aptomi/pkg/external/users"
+	"github.com/Aptomi/aptomi/pkg/lang"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"strconv"
+)
+
+// externalUserLoader loads users authenticated by external identity providers, which are recorded in the store by
+// the API, so policy resolution can find them same as users from the user directory
+type externalUserLoader struct {
+	store store.Core
+}
+
+// externalUserLoader implements users.UserLoader
+var _ users.UserLoader = &externalUserLoader{}
+
+// LoadUsersAll loads all recorded external users
+func (loader *externalUserLoader) LoadUsersAll() *lang.GlobalUsers {
+	records, err := loader.store.ListExternalUsers()
+	if err != nil {
+		panic(fmt.Sprintf("error while loading external users: %s", err))
+	}
+
+	result := &lang.GlobalUsers{Users: make(map[string]*lang.User)}
+	for _, record := range records {
+		result.Users[record.GetName()] = record.ToUser()
+	}
+	return result
+}
+
+// LoadUserByName loads a single recorded external user by name
+func (loader *externalUserLoader) LoadUserByName(name string) *lang.User {
+	record, err := loader.store.GetExternalUser(name)
+	if err != nil {
+		panic(fmt.Sprintf("error while loading external user: %s", err))
+	}
+	if record == nil {
+		return nil
+	}
+	return record.ToUser()
+}
+
+// Authenticate always fails, as external users are authenticated by their identity providers
+func (loader *externalUserLoader) Authenticate(name, password string) (*lang.User, error) {
+	return nil, fmt.Errorf("user '%s' should be authenticated by external identity provider", name)
+}
+
+// Summary returns summary as string
+func (loader *externalUserLoader) Summary() string {
+	return strconv.Itoa(len(loader.LoadUsersAll().Users)) + " (external)"
+}
@@ -0,0 +1,33 @@
+package server
+
+import (
+	"github.com/Aptomi/aptomi/pkg/auth"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestExternalUserLoader(t *testing.T) {
+	s := newMemoryStore(t)
+	loader := &externalUserLoader{store: s}
+	assert.Nil(t, loader.LoadUserByName("Alice"), "Unknown external user shouldn't be loaded")
+
+	err := s.SaveExternalUser(&auth.ExternalUser{
+		TypeKind: auth.ExternalUserObject.GetTypeKind(),
+		Name:     "Alice",
+		Issuer:   "https://idp.example.com",
+		Labels:   map[string]string{"team": "red"},
+	})
+	if !assert.NoError(t, err, "External user should be saved") {
+		return
+	}
+
+	user := loader.LoadUserByName("alice")
+	if assert.NotNil(t, user, "External user should be loaded by name regardless of case") {
+		assert.Equal(t, "Alice", user.Name, "External user name should be preserved")
+		assert.Equal(t, "red", user.Labels["team"], "External user labels should be loaded")
+	}
+	assert.Len(t, loader.LoadUsersAll().Users, 1, "All external users should be loaded")
+
+	_, err = loader.Authenticate("alice", "password")
+	assert.Error(t, err, "External user shouldn't be authenticated with password")
+}
@@ -17,7 +17,8 @@ import (
 	"github.com/Aptomi/aptomi/pkg/runtime"
 	"github.com/Aptomi/aptomi/pkg/runtime/store"
 	"github.com/Aptomi/aptomi/pkg/runtime/store/core"
-	"github.com/Aptomi/aptomi/pkg/runtime/store/generic/bolt"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/generic"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/migration"
 	"github.com/Aptomi/aptomi/pkg/server/ui"
 	log "github.com/Sirupsen/logrus"
 	"github.com/gorilla/handlers"
@@ -33,19 +34,22 @@ type Server struct {
 	backgroundErrors chan string
 
 	externalData          *external.Data
+	userLoader            *users.UserLoaderCached
 	store                 store.Core
 	pluginRegistryFactory plugin.RegistryFactory
 
 	httpServer *http.Server
 
-	enforcementIdx uint
+	enforcementIdx     uint
+	enforcementTrigger chan struct{}
 }
 
 // NewServer creates a new Aptomi Server
 func NewServer(cfg *config.Server) *Server {
 	s := &Server{
-		cfg:              cfg,
-		backgroundErrors: make(chan string),
+		cfg:                cfg,
+		backgroundErrors:   make(chan string),
+		enforcementTrigger: make(chan struct{}, 1),
 	}
 
 	return s
@@ -61,9 +65,10 @@ func (server *Server) Start() {
 	// See if policy initialization needs to happen on the first run
 	server.initPolicyOnFirstRun()
 
-	// Start API, UI and Enforcer
+	// Start API, UI, Enforcer and DB compaction
 	server.startHTTPServer()
 	server.startEnforcer()
+	server.startCompaction()
 
 	// Wait for jobs to complete (it essentially hangs forever)
 	server.wait()
@@ -93,22 +98,76 @@ func (server *Server) initExternalData() {
 	for _, file := range server.cfg.Users.File {
 		userLoaders = append(userLoaders, users.NewUserLoaderFromFile(file, server.cfg.DomainAdminOverrides))
 	}
+	if server.cfg.Users.CacheTTL <= 0 {
+		server.cfg.Users.CacheTTL = time.Minute
+	}
+	server.userLoader = users.NewUserLoaderCached(users.NewUserLoaderMultipleSources(userLoaders), server.cfg.Users.CacheTTL)
+
+	// users authenticated by external identity providers are recorded in the store by the API. They aren't cached, so
+	// policy resolution finds them right after they call the API for the first time
+	var userLoader users.UserLoader = server.userLoader
+	if len(server.cfg.Auth.External) > 0 {
+		userLoader = users.NewUserLoaderMultipleSources([]users.UserLoader{server.userLoader, &externalUserLoader{store: server.store}})
+	}
+
 	server.externalData = external.NewData(
-		users.NewUserLoaderMultipleSources(userLoaders),
-		secrets.NewSecretLoaderFromDir(server.cfg.SecretsDir),
+		userLoader,
+		secrets.NewSecretLoaderMultipleSources(server.initSecretLoaders()),
 	)
 }
 
+func (server *Server) initSecretLoaders() []secrets.SecretLoader {
+	var secretLoaders []secrets.SecretLoader
+	if len(server.cfg.SecretsDir) > 0 {
+		secretLoaders = append(secretLoaders, secrets.NewSecretLoaderFromDir(server.cfg.SecretsDir))
+	}
+	for _, dir := range server.cfg.Secrets.Dir {
+		secretLoaders = append(secretLoaders, secrets.NewSecretLoaderFromDir(dir))
+	}
+	for _, encryptedFile := range server.cfg.Secrets.EncryptedFile {
+		secretLoaders = append(secretLoaders, secrets.NewSecretLoaderFromEncryptedFile(encryptedFile))
+	}
+	for _, vault := range server.cfg.Secrets.Vault {
+		loader, err := secrets.NewSecretLoaderFromVault(vault)
+		if err != nil {
+			panic(fmt.Sprintf("Can't initialize Vault secret loader: %s", err))
+		}
+		secretLoaders = append(secretLoaders, loader)
+	}
+	if server.cfg.Secrets.Env != nil {
+		secretLoaders = append(secretLoaders, secrets.NewSecretLoaderFromEnv(*server.cfg.Secrets.Env))
+	}
+	return secretLoaders
+}
+
 func (server *Server) initStore() {
 	registry := runtime.NewRegistry().Append(store.Objects...)
-	b := bolt.NewGenericStore(registry)
-	err := b.Open(server.cfg.DB)
+	b, err := generic.NewStore(registry, server.cfg.DB)
+	if err != nil {
+		panic(fmt.Sprintf("Can't create object store: %s", err))
+	}
+	err = b.Open(server.cfg.DB)
 	if err != nil {
 		panic(fmt.Sprintf("Can't open object store: %s", err))
 	}
+	server.migrateStore(b)
 	server.store = core.NewStore(b)
 }
 
+func (server *Server) migrateStore(b store.Generic) {
+	// Migrate stored objects to the current schema version, making backup before it
+	result, backupFile, err := migration.MigrateWithBackup(b, migration.Migrations, migration.BackupDir(server.cfg.DB))
+	if err != nil {
+		panic(fmt.Sprintf("Can't migrate object store: %s", err))
+	}
+	if len(backupFile) > 0 {
+		log.Infof("Backup of object store saved to %s before migration", backupFile)
+	}
+	for _, applied := range result.Applied {
+		log.Infof("Object store migrated to schema version %d (%s), %d objects changed", applied.Version, applied.Description, result.Changed[applied.Version])
+	}
+}
+
 func (server *Server) initPluginRegistryFactory() {
 	server.pluginRegistryFactory = func() plugin.Registry {
 		clusterTypes := make(map[string]plugin.ClusterPluginConstructor)
@@ -158,8 +217,14 @@ func (server *Server) startHTTPServer() {
 		server.cfg.Auth.Secret = "Shahsh4e cohp8aeT Ifaic3ah ohs4eiSh vee7Qua7 eiCh2iLo eiroh3Ie oeg2ruPu"
 		log.Warnf("The auth.secret not specified in config, using insecure default one")
 	}
+	if server.cfg.Auth.AccessTokenTTL <= 0 {
+		server.cfg.Auth.AccessTokenTTL = 24 * time.Hour
+	}
+	if server.cfg.Auth.RefreshTokenTTL <= 0 {
+		server.cfg.Auth.RefreshTokenTTL = 30 * 24 * time.Hour
+	}
 
-	api.Serve(router, server.store, server.externalData, server.pluginRegistryFactory, server.cfg.Auth.Secret)
+	api.Serve(router, server.store, server.externalData, server.pluginRegistryFactory, server.cfg.Auth, server.enforcementTrigger)
 	server.serveUI(router)
 
 	var handler http.Handler = router
@@ -167,6 +232,7 @@ func (server *Server) startHTTPServer() {
 	// todo write to logrus
 	handler = handlers.CombinedLoggingHandler(os.Stdout, handler) // todo(slukjanov): make it at least somehow configurable - for example, select file to write to with rotation
 	handler = middleware.NewPanicHandler(handler)
+	handler = middleware.NewStreamingHandler(handler, api.WatchPathSuffix)
 	// todo(slukjanov): add configurable handlers.ProxyHeaders to f behind the nginx or any other proxy
 	// todo(slukjanov): add compression handler and compress by default in client
 
@@ -207,6 +273,28 @@ func (server *Server) serveUI(router *httprouter.Router) {
 	})
 }
 
+func (server *Server) startCompaction() {
+	// Remove old generations of policy and revisions from DB according to the retention config
+	retention := server.cfg.DB.Retention
+	if !retention.IsEnabled() {
+		return
+	}
+	if retention.Interval <= 0 {
+		retention.Interval = time.Hour
+	}
+	server.runInBackground("DB Compaction", true, func() {
+		for {
+			result, err := server.store.Compact(retention, time.Now())
+			if err != nil {
+				log.Warnf("Error while compacting DB: %s", err)
+			} else {
+				log.Infof("DB compacted, removed %d revisions, %d policy generations and %d object generations", result.Revisions, result.PolicyGenerations, result.ObjectGenerations)
+			}
+			time.Sleep(retention.Interval)
+		}
+	})
+}
+
 func (server *Server) startEnforcer() {
 	// Start policy enforcement job
 	if !server.cfg.Enforcer.Disabled {
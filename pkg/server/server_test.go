@@ -0,0 +1,24 @@
+package server
+
+import (
+	"github.com/Aptomi/aptomi/pkg/config"
+	"github.com/Aptomi/aptomi/pkg/runtime"
+	"github.com/Aptomi/aptomi/pkg/runtime/store"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/core"
+	"github.com/Aptomi/aptomi/pkg/runtime/store/generic/memory"
+	"testing"
+)
+
+// newMemoryStore returns a core store on top of the empty in-memory store with initialized policy
+func newMemoryStore(t *testing.T) store.Core {
+	t.Helper()
+	b := memory.NewGenericStore(runtime.NewRegistry().Append(store.Objects...))
+	if err := b.Open(config.DB{Connection: "memory://"}); err != nil {
+		t.Fatalf("can't open in-memory store: %s", err)
+	}
+	s := core.NewStore(b)
+	if err := s.InitPolicy(); err != nil {
+		t.Fatalf("can't init policy: %s", err)
+	}
+	return s
+}
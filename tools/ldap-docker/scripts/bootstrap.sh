@@ -48,5 +48,8 @@ restart_ldap
 # Create aptomi users
 ldapmodify -c -h localhost -p 10389 -D uid=admin,ou=system -w secret -f /aptomi-users.ldif
 
+# Create aptomi groups
+ldapmodify -c -h localhost -p 10389 -D uid=admin,ou=system -w secret -f /aptomi-groups.ldif
+
 # Stop LDAP server
 stop_ldap